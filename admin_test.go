@@ -1,11 +1,15 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/gorilla/mux"
 )
 
 func TestAdminSlotsHandler_MissingCredentials(t *testing.T) {
@@ -67,4 +71,67 @@ func TestAdminSlotsHandler_WithCredentials(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status %d for correct auth, got %d", http.StatusOK, w.Code)
 	}
-}
\ No newline at end of file
+}
+
+func TestAdminPreviewHandler(t *testing.T) {
+	os.Setenv("ADMIN_USERNAME", "testadmin")
+	os.Setenv("ADMIN_PASSWORD", "testpass123")
+	defer func() {
+		os.Unsetenv("ADMIN_USERNAME")
+		os.Unsetenv("ADMIN_PASSWORD")
+	}()
+
+	// Unauthenticated requests are rejected
+	req := httptest.NewRequest("GET", "/admin/preview?slug=claude-code-go-sdk-announcement", nil)
+	w := httptest.NewRecorder()
+	adminPreviewHandler(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	// Unknown posts can't be previewed
+	req = httptest.NewRequest("GET", "/admin/preview?slug=does-not-exist", nil)
+	req.SetBasicAuth("testadmin", "testpass123")
+	w = httptest.NewRecorder()
+	adminPreviewHandler(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	// Valid request returns a signed link that opens the post
+	req = httptest.NewRequest("GET", "/admin/preview?slug=claude-code-go-sdk-announcement&ttl=1h", nil)
+	req.SetBasicAuth("testadmin", "testpass123")
+	w = httptest.NewRecorder()
+	adminPreviewHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var resp map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid JSON response: %v", err)
+	}
+	previewURL, err := url.Parse(resp["url"])
+	if err != nil || !strings.HasPrefix(previewURL.Path, "/blog/preview/") {
+		t.Fatalf("Unexpected preview URL: %q", resp["url"])
+	}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/blog/preview/{slug}", blogPreviewHandler).Methods("GET")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", previewURL.RequestURI(), nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected preview status %d, got %d", http.StatusOK, w.Code)
+	}
+	if w.Header().Get("X-Robots-Tag") == "" {
+		t.Error("Preview responses should not be indexed")
+	}
+
+	// Tampered signatures are rejected
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", previewURL.Path+"?expires=9999999999&sig=deadbeef", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected tampered preview status %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"blockhead.consulting/internal/lint"
+)
+
+func main() {
+	root := flag.String("root", ".", "Site root containing content/ and static/")
+
+	flag.Parse()
+
+	problems, err := lint.Check(os.DirFS(*root))
+	if err != nil {
+		log.Fatalf("Failed to lint %s: %v", *root, err)
+	}
+
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		fmt.Printf("\n✗ %d problems found\n", len(problems))
+		os.Exit(1)
+	}
+	fmt.Println("✓ No problems found")
+}
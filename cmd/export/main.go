@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// The router and its handlers live in the site's main package, which can't be
+// imported. The export builds the site binary, or uses one given with -bin,
+// and runs it in its export mode, where the router is started in-process.
+func main() {
+	var (
+		root   = flag.String("root", ".", "Site root containing main.go")
+		outDir = flag.String("out", "dist", "Directory to write the static site to")
+		bin    = flag.String("bin", "", "Site binary to run, built from -root when empty")
+	)
+
+	flag.Parse()
+
+	out, err := filepath.Abs(*outDir)
+	if err != nil {
+		log.Fatalf("Invalid output directory %s: %v", *outDir, err)
+	}
+
+	site := *bin
+	if site == "" {
+		tmp, err := os.MkdirTemp("", "export")
+		if err != nil {
+			log.Fatalf("Failed to create build directory: %v", err)
+		}
+		site = filepath.Join(tmp, "site")
+		err = build(*root, site)
+		if err == nil {
+			err = run(*root, site, out)
+		}
+		os.RemoveAll(tmp)
+		exit(err)
+		return
+	}
+
+	site, err = filepath.Abs(site)
+	if err != nil {
+		log.Fatalf("Invalid binary %s: %v", *bin, err)
+	}
+	exit(run(*root, site, out))
+}
+
+// build compiles the site at root into a binary
+func build(root, site string) error {
+	cmd := exec.Command("go", "build", "-o", site, ".")
+	cmd.Dir = root
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// run exports the site with its binary, from the root, where the site finds
+// its .env and data directory
+func run(root, site, out string) error {
+	cmd := exec.Command(site, "export", "-out", out)
+	cmd.Dir = root
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// exit ends the export with the exit code of a failed command, whose output
+// already explains what went wrong
+func exit(err error) {
+	if err == nil {
+		return
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		os.Exit(exitErr.ExitCode())
+	}
+	log.Fatalf("Export failed: %v", err)
+}
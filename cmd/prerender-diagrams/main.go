@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"blockhead.consulting/internal/render"
+)
+
+func main() {
+	var (
+		contentDir = flag.String("content", "content", "Directory of markdown content to scan")
+		cacheDir   = flag.String("cache", "data/diagram-cache", "Diagram cache directory")
+		maxMB      = flag.Int("max-mb", 64, "Diagram cache size limit in megabytes")
+	)
+
+	flag.Parse()
+
+	// Failures are reported per file below
+	logger := log.New(io.Discard, "", 0)
+	diagrams := render.NewDiagrams(render.DiagramOptions{
+		Cache:  render.NewDiagramCache(*cacheDir, int64(*maxMB)<<20),
+		Logger: logger,
+	}, render.DefaultDiagramRenderers()...)
+
+	var rendered, cached, failed int
+	err := filepath.WalkDir(*contentDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".md") {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, block := range diagrams.DiagramBlocks(content) {
+			wasCached, err := diagrams.Prerender(context.Background(), block.Language, block.Source)
+			switch {
+			case err != nil:
+				fmt.Printf("✗ %s: %s diagram: %v\n", path, block.Language, err)
+				failed++
+			case wasCached:
+				cached++
+			default:
+				fmt.Printf("✓ %s: rendered %s diagram\n", path, block.Language)
+				rendered++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Failed to scan %s: %v", *contentDir, err)
+	}
+
+	fmt.Printf("\n%d rendered, %d already cached, %d failed (cache: %s)\n", rendered, cached, failed, *cacheDir)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,391 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"blockhead.consulting/internal/blog"
+	"blockhead.consulting/internal/errors"
+	"github.com/gorilla/mux"
+)
+
+// Prefix is the path every route of this version of the API is under
+const Prefix = "/api/v1"
+
+// Page sizes of post listings
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Options configures the API
+type Options struct {
+	Title   string                     // Name of the API in the OpenAPI document
+	BaseURL func(*http.Request) string // Absolute origin of the site, for links in responses
+	Logger  *log.Logger
+}
+
+// API serves the published posts of the blog as JSON
+type API struct {
+	posts   blog.Service
+	title   string
+	baseURL func(*http.Request) string
+	logger  *log.Logger
+}
+
+// New creates the API on top of a blog service
+func New(posts blog.Service, opts Options) *API {
+	a := &API{
+		posts:   posts,
+		title:   opts.Title,
+		baseURL: opts.BaseURL,
+		logger:  opts.Logger,
+	}
+	if a.title == "" {
+		a.title = "Blog API"
+	}
+	if a.baseURL == nil {
+		a.baseURL = func(r *http.Request) string { return "http://" + r.Host }
+	}
+	if a.logger == nil {
+		a.logger = log.Default()
+	}
+	return a
+}
+
+// handler returns the body of a successful response, or an error the
+// client is told about
+type handler func(a *API, r *http.Request) (interface{}, error)
+
+// Register adds the routes of the API to a router
+func (a *API) Register(r *mux.Router) {
+	for _, e := range endpoints {
+		r.Handle(Prefix+e.Path, a.serve(e.handle)).Methods("GET", "HEAD")
+	}
+}
+
+// serve writes what a handler returns as JSON with an ETag of the body, so
+// unchanged responses are revalidated without sending them again
+func (a *API) serve(h handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		result, err := h(a, r)
+		if err != nil {
+			a.writeError(w, err)
+			return
+		}
+		body, err := json.Marshal(result)
+		if err != nil {
+			a.writeError(w, errors.Wrap(err, errors.ErrCodeSerialization, "failed to encode response"))
+			return
+		}
+
+		sum := sha256.Sum256(body)
+		etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(append(body, '\n'))
+	})
+}
+
+// writeError writes an error as an ErrorResponse. Only errors meant for
+// users are explained, anything else is logged and reported as internal.
+func (a *API) writeError(w http.ResponseWriter, err error) {
+	appErr, ok := err.(*errors.AppError)
+	if !ok || !appErr.IsUserError() {
+		a.logger.Printf("API: %v", err)
+		appErr = errors.Internal("internal error")
+	}
+
+	status := http.StatusInternalServerError
+	switch appErr.Code {
+	case errors.ErrCodeNotFound, errors.ErrCodeResourceNotFound, errors.ErrCodePageNotFound:
+		status = http.StatusNotFound
+	case errors.ErrCodeValidation, errors.ErrCodeInvalidInput, errors.ErrCodeMissingRequired, errors.ErrCodeInvalidFormat:
+		status = http.StatusBadRequest
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: Problem{Code: string(appErr.Code), Message: appErr.Message}})
+}
+
+// listPosts serves /posts: published posts, newest first or by relevance to
+// q, filtered by tag and date and paginated with a cursor
+func listPosts(a *API, r *http.Request) (interface{}, error) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	limit := DefaultLimit
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > MaxLimit {
+			return nil, errors.Validation("limit", "limit must be a number from 1 to "+strconv.Itoa(MaxLimit))
+		}
+		limit = n
+	}
+	from, err := parseDate(query.Get("from"), "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseDate(query.Get("to"), "to")
+	if err != nil {
+		return nil, err
+	}
+	fields, err := parseFields(query.Get("fields"))
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []blog.Post
+	if q := strings.TrimSpace(query.Get("q")); q != "" {
+		for _, result := range a.posts.SearchResults(ctx, q) {
+			posts = append(posts, result.Post)
+		}
+	} else {
+		posts = a.posts.GetAll(ctx)
+	}
+
+	var tagged map[string]bool
+	if tag := query.Get("tag"); tag != "" {
+		tagged = make(map[string]bool)
+		for _, post := range a.posts.GetByTag(ctx, tag) {
+			tagged[post.Slug] = true
+		}
+	}
+	matching := posts[:0]
+	for _, post := range posts {
+		if tagged != nil && !tagged[post.Slug] {
+			continue
+		}
+		if !from.IsZero() && post.Date.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !post.Date.Before(to.AddDate(0, 0, 1)) {
+			continue
+		}
+		matching = append(matching, post)
+	}
+
+	// The cursor is the slug of the last post of the previous page, so
+	// posts published in the meantime don't shift the pages after it
+	start := 0
+	if cursor := query.Get("cursor"); cursor != "" {
+		slug, err := base64.RawURLEncoding.DecodeString(cursor)
+		start = -1
+		for i, post := range matching {
+			if err == nil && post.Slug == string(slug) {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return nil, errors.Validation("cursor", "cursor is invalid or its post is no longer listed")
+		}
+	}
+	end := start + limit
+	if end > len(matching) {
+		end = len(matching)
+	}
+
+	list := PostList{Posts: make([]Post, 0, end-start), Total: len(matching)}
+	for _, post := range matching[start:end] {
+		list.Posts = append(list.Posts, a.post(r, post, fields["html"] || fields["toc"]))
+	}
+	if end < len(matching) {
+		list.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(matching[end-1].Slug))
+	}
+	if fields == nil {
+		return list, nil
+	}
+	return selectFields(list, fields)
+}
+
+// getPost serves /posts/{slug}: one published post with its content
+func getPost(a *API, r *http.Request) (interface{}, error) {
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		return nil, err
+	}
+	post, err := a.posts.GetBySlug(r.Context(), mux.Vars(r)["slug"])
+	if err != nil || post == nil {
+		return nil, errors.NotFound("post")
+	}
+
+	result := a.post(r, *post, true)
+	if fields == nil {
+		return result, nil
+	}
+	return pick(result, fields)
+}
+
+// listTags serves /tags: every tag of published posts with its post count
+func listTags(a *API, r *http.Request) (interface{}, error) {
+	ctx := r.Context()
+	baseURL := strings.TrimRight(a.baseURL(r), "/")
+
+	list := TagList{Tags: []Tag{}}
+	for _, tag := range a.posts.GetTags(ctx) {
+		list.Tags = append(list.Tags, Tag{
+			Tag:   tag,
+			Name:  a.posts.TagName(tag),
+			Count: len(a.posts.GetByTag(ctx, tag)),
+			URL:   baseURL + "/blog/tag/" + a.posts.CanonicalTag(tag),
+		})
+	}
+	return list, nil
+}
+
+// openAPI serves /openapi.json, the OpenAPI document of the API
+func openAPI(a *API, r *http.Request) (interface{}, error) {
+	return a.OpenAPI(a.baseURL(r)), nil
+}
+
+// post converts a blog post to its API representation, with its rendered
+// content and table of contents when content is set
+func (a *API) post(r *http.Request, post blog.Post, content bool) Post {
+	baseURL := strings.TrimRight(a.baseURL(r), "/")
+	result := Post{
+		Slug:         post.Slug,
+		URL:          baseURL + "/blog/" + post.Slug,
+		Title:        post.Title,
+		Date:         post.Date,
+		Summary:      post.Summary,
+		Description:  post.Description,
+		Tags:         post.Tags,
+		Authors:      make([]Author, 0, len(post.Authors)),
+		ReadingTime:  post.ReadingTime,
+		Image:        post.Image,
+		Lang:         post.Lang,
+		Translations: post.Translations,
+	}
+	if result.Description == "" {
+		result.Description = post.Summary
+	}
+	if result.Tags == nil {
+		result.Tags = []string{}
+	}
+	if post.WasUpdated() {
+		updated := post.Updated
+		result.Updated = &updated
+	}
+	if result.Image != "" && strings.HasPrefix(result.Image, "/") {
+		result.Image = baseURL + result.Image
+	}
+	for _, author := range post.Authors {
+		person := Author{Name: author.Name}
+		if author.Registered {
+			person.ID = author.ID
+			person.URL = baseURL + "/blog/author/" + author.ID
+		}
+		result.Authors = append(result.Authors, person)
+	}
+	if post.Series != "" {
+		result.Series = &Series{
+			Name: post.Series,
+			Part: post.SeriesOrder,
+			URL:  baseURL + "/blog/series/" + blog.SeriesSlug(post.Series),
+		}
+	}
+	if content {
+		result.HTML = string(post.Content)
+		result.TOC = post.TOC
+	}
+	return result
+}
+
+// parseDate parses a YYYY-MM-DD query parameter, zero when it's empty
+func parseDate(value, name string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errors.Validation(name, name+" must be a date like 2024-01-31")
+	}
+	return date, nil
+}
+
+// postFields are the JSON names of the fields of a Post
+var postFields = jsonFields(reflect.TypeOf(Post{}))
+
+// parseFields parses a comma-separated field selection, nil when there's none
+func parseFields(value string) (map[string]bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+	fields := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if !contains(postFields, name) {
+			return nil, errors.Validation("fields", "unknown field "+strconv.Quote(name)+", fields are "+strings.Join(postFields, ", "))
+		}
+		fields[name] = true
+	}
+	return fields, nil
+}
+
+// selectFields returns a post listing with only the selected fields of each post
+func selectFields(list PostList, fields map[string]bool) (interface{}, error) {
+	selected := struct {
+		Posts      []map[string]json.RawMessage `json:"posts"`
+		Total      int                          `json:"total"`
+		NextCursor string                       `json:"next_cursor,omitempty"`
+	}{
+		Posts:      make([]map[string]json.RawMessage, 0, len(list.Posts)),
+		Total:      list.Total,
+		NextCursor: list.NextCursor,
+	}
+	for _, post := range list.Posts {
+		picked, err := pick(post, fields)
+		if err != nil {
+			return nil, err
+		}
+		selected.Posts = append(selected.Posts, picked)
+	}
+	return selected, nil
+}
+
+// pick returns the selected fields of a post. Fields left out of the post's
+// JSON because they're empty stay out.
+func pick(post Post, fields map[string]bool) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(post)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeSerialization, "failed to encode post")
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeSerialization, "failed to encode post")
+	}
+	picked := make(map[string]json.RawMessage, len(fields))
+	for name, value := range all {
+		if fields[name] {
+			picked[name] = value
+		}
+	}
+	return picked, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"blockhead.consulting/internal/blog"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRouter(t *testing.T) *mux.Router {
+	fsys := fstest.MapFS{
+		"content/blog.yml":       {Data: []byte("blog:\n  tag_filters:\n    - display: \"Go\"\n      tag: \"golang\"\n      aliases: [\"go\"]\n")},
+		"content/authors.yml":    {Data: []byte("authors:\n  - id: ada\n    name: Ada\n")},
+		"content/blog/first.md":  {Data: []byte("---\ntitle: First\ndate: 2024-01-10\nsummary: The first\ntags: [go]\n---\n\n## Setup\n\nHello.\n")},
+		"content/blog/second.md": {Data: []byte("---\ntitle: Second\ndate: 2024-02-10\ntags: [golang, rust]\nseries: Intro\nseriesOrder: 2\nauthor: Sam Guest\n---\n\nRust and Go.\n")},
+		"content/blog/third.md":  {Data: []byte("---\ntitle: Third\ndate: 2024-03-10\ntags: [rust]\n---\n\nMore rust.\n")},
+		"content/blog/draft.md":  {Data: []byte("---\ntitle: Draft\ndate: 2024-04-10\ndraft: true\ntags: [go]\n---\n\nNot yet.\n")},
+	}
+	posts := blog.NewServiceWithOptions(fsys, "content/blog", log.New(io.Discard, "", 0), nil)
+	require.NoError(t, posts.LoadPosts(context.Background()))
+
+	r := mux.NewRouter()
+	New(posts, Options{
+		Title:   "Test API",
+		BaseURL: func(*http.Request) string { return "https://example.com" },
+		Logger:  log.New(io.Discard, "", 0),
+	}).Register(r)
+	return r
+}
+
+func get(t *testing.T, r http.Handler, target string, out interface{}) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", target, nil))
+	if out != nil {
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), out), rr.Body.String())
+	}
+	return rr
+}
+
+func slugs(list PostList) []string {
+	var result []string
+	for _, post := range list.Posts {
+		result = append(result, post.Slug)
+	}
+	return result
+}
+
+func TestListPosts(t *testing.T) {
+	r := testRouter(t)
+
+	var list PostList
+	rr := get(t, r, "/api/v1/posts", &list)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, []string{"third", "second", "first"}, slugs(list))
+	assert.Equal(t, 3, list.Total)
+	assert.Empty(t, list.NextCursor)
+
+	second := list.Posts[1]
+	assert.Equal(t, "https://example.com/blog/second", second.URL)
+	assert.Equal(t, []Author{{Name: "Sam Guest"}}, second.Authors)
+	assert.Equal(t, &Series{Name: "Intro", Part: 2, URL: "https://example.com/blog/series/intro"}, second.Series)
+	assert.Empty(t, second.HTML, "listings leave out the content")
+	assert.Equal(t, []Author{{ID: "ada", Name: "Ada", URL: "https://example.com/blog/author/ada"}}, list.Posts[2].Authors)
+
+	testCases := []struct {
+		query string
+		want  []string
+	}{
+		{"tag=go", []string{"second", "first"}},
+		{"tag=rust&from=2024-02-10", []string{"third", "second"}},
+		{"to=2024-02-10", []string{"second", "first"}},
+		{"from=2024-02-11&to=2024-03-10", []string{"third"}},
+		{"q=rust&tag=golang", []string{"second"}},
+		{"tag=nothing", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			var list PostList
+			rr := get(t, r, "/api/v1/posts?"+tc.query, &list)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tc.want, slugs(list))
+		})
+	}
+}
+
+func TestListPostsCursor(t *testing.T) {
+	r := testRouter(t)
+
+	var seen []string
+	target := "/api/v1/posts?limit=2"
+	for target != "" {
+		var list PostList
+		rr := get(t, r, target, &list)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, 3, list.Total)
+		seen = append(seen, slugs(list)...)
+
+		target = ""
+		if list.NextCursor != "" {
+			target = "/api/v1/posts?limit=2&cursor=" + list.NextCursor
+		}
+	}
+	assert.Equal(t, []string{"third", "second", "first"}, seen)
+}
+
+func TestListPostsFields(t *testing.T) {
+	r := testRouter(t)
+
+	var list struct {
+		Posts []map[string]interface{} `json:"posts"`
+	}
+	rr := get(t, r, "/api/v1/posts?fields=slug,series,html&limit=2", &list)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Len(t, list.Posts, 2)
+	assert.Equal(t, map[string]interface{}{"slug": "third", "html": "<p>More rust.</p>\n"}, list.Posts[0])
+	assert.Equal(t, []string{"html", "series", "slug"}, keys(list.Posts[1]))
+}
+
+func keys(m map[string]interface{}) []string {
+	var result []string
+	for key := range m {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestInvalidParameters(t *testing.T) {
+	r := testRouter(t)
+
+	for _, query := range []string{"limit=0", "limit=101", "from=yesterday", "cursor=bm9wZQ", "fields=slug,body"} {
+		t.Run(query, func(t *testing.T) {
+			var body ErrorResponse
+			rr := get(t, r, "/api/v1/posts?"+query, &body)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, "VALIDATION_ERROR", body.Error.Code)
+			assert.NotEmpty(t, body.Error.Message)
+		})
+	}
+}
+
+func TestGetPost(t *testing.T) {
+	r := testRouter(t)
+
+	var post Post
+	rr := get(t, r, "/api/v1/posts/first", &post)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "First", post.Title)
+	assert.Equal(t, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), post.Date)
+	assert.Equal(t, "The first", post.Description)
+	assert.Contains(t, post.HTML, `id="setup"`)
+	require.Len(t, post.TOC, 1)
+	assert.Equal(t, "setup", post.TOC[0].ID)
+
+	// Unchanged responses are revalidated by their ETag
+	req := httptest.NewRequest("GET", "/api/v1/posts/first", nil)
+	req.Header.Set("If-None-Match", rr.Header().Get("ETag"))
+	cached := httptest.NewRecorder()
+	r.ServeHTTP(cached, req)
+	assert.Equal(t, http.StatusNotModified, cached.Code)
+	assert.Empty(t, cached.Body.String())
+
+	for _, slug := range []string{"draft", "missing"} {
+		var body ErrorResponse
+		rr = get(t, r, "/api/v1/posts/"+slug, &body)
+		assert.Equal(t, http.StatusNotFound, rr.Code, slug)
+		assert.Equal(t, "NOT_FOUND", body.Error.Code, slug)
+	}
+}
+
+func TestListTags(t *testing.T) {
+	r := testRouter(t)
+
+	var list TagList
+	rr := get(t, r, "/api/v1/tags", &list)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, []Tag{
+		{Tag: "golang", Name: "Go", Count: 2, URL: "https://example.com/blog/tag/golang"},
+		{Tag: "rust", Name: "rust", Count: 2, URL: "https://example.com/blog/tag/rust"},
+	}, list.Tags)
+}
+
+func TestOpenAPI(t *testing.T) {
+	r := testRouter(t)
+
+	var doc struct {
+		OpenAPI string
+		Info    struct{ Title string }
+		Servers []struct{ URL string }
+		Paths   map[string]struct {
+			Get struct {
+				OperationID string
+				Responses   map[string]json.RawMessage
+			}
+		}
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]json.RawMessage
+				Required   []string
+			}
+		}
+	}
+	rr := get(t, r, "/api/v1/openapi.json", &doc)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Equal(t, "Test API", doc.Info.Title)
+	assert.Equal(t, "https://example.com/api/v1", doc.Servers[0].URL)
+
+	// Every registered route is documented
+	require.NoError(t, r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		require.NoError(t, err)
+		assert.Contains(t, doc.Paths, path[len(Prefix):])
+		return nil
+	}))
+	assert.Len(t, doc.Paths, 4)
+	assert.Contains(t, doc.Paths["/posts/{slug}"].Get.Responses, "404")
+
+	post := doc.Components.Schemas["Post"]
+	for _, field := range postFields {
+		assert.Contains(t, post.Properties, field)
+	}
+	assert.Contains(t, post.Required, "slug")
+	assert.NotContains(t, post.Required, "html")
+	assert.JSONEq(t, `{"type":"array","items":{"$ref":"#/components/schemas/TOCEntry"}}`, string(post.Properties["toc"]))
+	assert.Contains(t, doc.Components.Schemas["TOCEntry"].Properties, "children")
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"time"
+
+	"blockhead.consulting/internal/blog"
+)
+
+// Post is a published blog post. Listings leave out HTML and TOC unless
+// they're asked for with the fields parameter.
+type Post struct {
+	Slug         string          `json:"slug"`
+	URL          string          `json:"url"` // Absolute URL of the post's page
+	Title        string          `json:"title"`
+	Date         time.Time       `json:"date"`
+	Updated      *time.Time      `json:"updated,omitempty"` // Set when the post was revised after it was published
+	Summary      string          `json:"summary"`
+	Description  string          `json:"description"`
+	Tags         []string        `json:"tags"`
+	Authors      []Author        `json:"authors"`
+	Series       *Series         `json:"series,omitempty"`
+	ReadingTime  int             `json:"reading_time"` // Minutes
+	Image        string          `json:"image,omitempty"`
+	Lang         string          `json:"lang"`
+	Translations []string        `json:"translations,omitempty"`
+	HTML         string          `json:"html,omitempty"` // Rendered content
+	TOC          []blog.TOCEntry `json:"toc,omitempty"`
+}
+
+// Author is a person credited with a post
+type Author struct {
+	ID   string `json:"id,omitempty"` // Empty for authors not in authors.yml
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"` // Absolute URL of the author's page, empty when there's none
+}
+
+// Series places a post in a multi-part series
+type Series struct {
+	Name string `json:"name"`
+	Part int    `json:"part"`
+	URL  string `json:"url"`
+}
+
+// PostList is one page of posts matching a query
+type PostList struct {
+	Posts      []Post `json:"posts"`                 // Only the selected fields when there's a selection
+	Total      int    `json:"total"`                 // Posts matching the query across all pages
+	NextCursor string `json:"next_cursor,omitempty"` // Pass as cursor for the next page, empty on the last
+}
+
+// Tag is a tag with the number of published posts carrying it
+type Tag struct {
+	Tag   string `json:"tag"` // Canonical tag, as accepted by the tag filter
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	URL   string `json:"url"` // Absolute URL of the tag's page
+}
+
+// TagList lists every tag of published posts
+type TagList struct {
+	Tags []Tag `json:"tags"`
+}
+
+// ErrorResponse is the body of every response that isn't a success
+type ErrorResponse struct {
+	Error Problem `json:"error"`
+}
+
+// Problem says what went wrong with a request
+type Problem struct {
+	Code    string `json:"code"` // Error code from internal/errors, e.g. NOT_FOUND
+	Message string `json:"message"`
+}
@@ -0,0 +1,265 @@
+package api
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// endpoint is a route of the API and what the OpenAPI document says about it
+type endpoint struct {
+	Path     string
+	ID       string // OpenAPI operationId
+	Summary  string
+	Params   []parameter
+	Response interface{} // Zero value of the type of the response body
+	handle   handler
+}
+
+// fieldsParam selects fields of posts on every endpoint returning posts
+var fieldsParam = parameter{
+	Name:        "fields",
+	In:          "query",
+	Description: "Comma-separated fields of each post to return, e.g. slug,title,date. Fields are " + strings.Join(postFields, ", ") + ".",
+	Schema:      &schema{Type: "string"},
+}
+
+// endpoints lists every route of the API. Register serves them and
+// OpenAPI documents them, so the two can't drift apart.
+var endpoints = []endpoint{
+	{
+		Path:    "/posts",
+		ID:      "listPosts",
+		Summary: "List published posts, newest first, or by relevance when searching",
+		Params: []parameter{
+			{Name: "tag", In: "query", Description: "Only posts with this tag or one of its aliases", Schema: &schema{Type: "string"}},
+			{Name: "from", In: "query", Description: "Only posts published on or after this date", Schema: &schema{Type: "string", Format: "date"}},
+			{Name: "to", In: "query", Description: "Only posts published on or before this date", Schema: &schema{Type: "string", Format: "date"}},
+			{Name: "q", In: "query", Description: "Search query, with the syntax of the blog's search page", Schema: &schema{Type: "string"}},
+			{Name: "cursor", In: "query", Description: "next_cursor of the previous page", Schema: &schema{Type: "string"}},
+			{Name: "limit", In: "query", Description: "Posts per page", Schema: &schema{Type: "integer", Minimum: intPtr(1), Maximum: intPtr(MaxLimit), Default: DefaultLimit}},
+			fieldsParam,
+		},
+		Response: PostList{},
+		handle:   listPosts,
+	},
+	{
+		Path:    "/posts/{slug}",
+		ID:      "getPost",
+		Summary: "Get a published post with its rendered HTML and table of contents",
+		Params: []parameter{
+			{Name: "slug", In: "path", Required: true, Schema: &schema{Type: "string"}},
+			fieldsParam,
+		},
+		Response: Post{},
+		handle:   getPost,
+	},
+	{
+		Path:     "/tags",
+		ID:       "listTags",
+		Summary:  "List the tags of published posts with their post counts",
+		Response: TagList{},
+		handle:   listTags,
+	},
+}
+
+func init() {
+	// Added here because the document is generated from endpoints
+	endpoints = append(endpoints, endpoint{
+		Path:     "/openapi.json",
+		ID:       "getOpenAPI",
+		Summary:  "Get this document",
+		Response: map[string]interface{}{},
+		handle:   openAPI,
+	})
+}
+
+// OpenAPI document structure, the subset of OpenAPI 3.0 the API needs
+
+type document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       info                `json:"info"`
+	Servers    []server            `json:"servers"`
+	Paths      map[string]pathItem `json:"paths"`
+	Components components          `json:"components"`
+}
+
+type info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type server struct {
+	URL string `json:"url"`
+}
+
+type pathItem struct {
+	Get operation `json:"get"`
+}
+
+type operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Parameters  []parameter         `json:"parameters,omitempty"`
+	Responses   map[string]response `json:"responses"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *schema `json:"schema"`
+}
+
+type response struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type components struct {
+	Schemas map[string]*schema `json:"schemas"`
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+}
+
+// OpenAPI returns the OpenAPI document of the API served at baseURL. Schemas
+// are generated from the response types, so they follow changes to them.
+func (a *API) OpenAPI(baseURL string) interface{} {
+	schemas := make(map[string]*schema)
+	errorSchema := schemaOf(reflect.TypeOf(ErrorResponse{}), schemas)
+
+	doc := document{
+		OpenAPI:    "3.0.3",
+		Info:       info{Title: a.title, Version: "1"},
+		Servers:    []server{{URL: strings.TrimRight(baseURL, "/") + Prefix}},
+		Paths:      make(map[string]pathItem, len(endpoints)),
+		Components: components{Schemas: schemas},
+	}
+	for _, e := range endpoints {
+		op := operation{
+			OperationID: e.ID,
+			Summary:     e.Summary,
+			Parameters:  e.Params,
+			Responses: map[string]response{
+				"200": {Description: "OK", Content: jsonContent(schemaOf(reflect.TypeOf(e.Response), schemas))},
+				"304": {Description: "Not modified since the ETag in If-None-Match"},
+			},
+		}
+		if len(e.Params) > 0 {
+			op.Responses["400"] = response{Description: "Invalid parameter", Content: jsonContent(errorSchema)}
+		}
+		if strings.Contains(e.Path, "{") {
+			op.Responses["404"] = response{Description: "Not found", Content: jsonContent(errorSchema)}
+		}
+		doc.Paths[e.Path] = pathItem{Get: op}
+	}
+	return doc
+}
+
+func jsonContent(s *schema) map[string]mediaType {
+	return map[string]mediaType{"application/json": {Schema: s}}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf returns the schema of a Go type as encoding/json encodes it.
+// Structs are added to schemas by name and referenced.
+func schemaOf(t reflect.Type, schemas map[string]*schema) *schema {
+	switch {
+	case t == timeType:
+		return &schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Ptr:
+		return schemaOf(t.Elem(), schemas)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &schema{Type: "array", Items: schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return &schema{Type: "object", AdditionalProperties: schemaOf(t.Elem(), schemas)}
+	case reflect.Struct:
+		ref := &schema{Ref: "#/components/schemas/" + t.Name()}
+		if _, ok := schemas[t.Name()]; ok {
+			return ref
+		}
+		s := &schema{Type: "object", Properties: make(map[string]*schema)}
+		schemas[t.Name()] = s // before the fields, so recursive types end
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, omitempty, ok := jsonName(field)
+			if !ok {
+				continue
+			}
+			s.Properties[name] = schemaOf(field.Type, schemas)
+			if !omitempty {
+				s.Required = append(s.Required, name)
+			}
+		}
+		return ref
+	default:
+		return &schema{}
+	}
+}
+
+// jsonFields returns the JSON names of the fields of a struct type
+func jsonFields(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, ok := jsonName(t.Field(i)); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// jsonName returns the name encoding/json gives a struct field, and whether
+// it's left out when empty; ok is false for fields it skips
+func jsonName(field reflect.StructField) (name string, omitempty, ok bool) {
+	if field.PkgPath != "" {
+		return "", false, false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, true
+}
+
+func intPtr(n int) *int {
+	return &n
+}
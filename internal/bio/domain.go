@@ -11,7 +11,9 @@ type Bio struct {
 	Title    string
 	Subtitle string
 	Content  template.HTML
-	LastMod  time.Time
+	Markdown string    // Source of Content, without the frontmatter
+	Created  time.Time // First commit of the file, zero outside git
+	LastMod  time.Time // Last commit, or the file's modification time outside git
 }
 
 // Service defines the bio service interface
@@ -6,30 +6,47 @@ import (
 	"html/template"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
-	"strings"
 	"time"
 
-	"github.com/yuin/goldmark"
-	"github.com/yuin/goldmark/extension"
-	"github.com/yuin/goldmark/parser"
-	"github.com/yuin/goldmark/renderer/html"
-	"github.com/yuin/goldmark/text"
-	"go.abhg.dev/goldmark/frontmatter"
+	"blockhead.consulting/internal/history"
+	"blockhead.consulting/internal/render"
+	"gopkg.in/yaml.v3"
 )
 
 // service implements the bio service using file storage
 type service struct {
 	contentDir string
 	logger     *log.Logger
+	renderer   *render.Renderer
+	history    history.Provider
 }
 
-// NewService creates a new bio service
-func NewService(logger *log.Logger) Service {
-	return &service{
+// Option configures the bio service
+type Option func(*service)
+
+// WithHistory dates bios from their history instead of the time they load
+func WithHistory(provider history.Provider) Option {
+	return func(s *service) {
+		s.history = provider
+	}
+}
+
+// NewService creates a new bio service. A nil renderer uses the site defaults.
+func NewService(logger *log.Logger, renderer *render.Renderer, opts ...Option) Service {
+	if renderer == nil {
+		renderer = render.Site()
+	}
+	s := &service{
 		contentDir: "content",
 		logger:     logger,
+		renderer:   renderer,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // GetBrief returns the brief bio content for homepage
@@ -54,25 +71,11 @@ func (s *service) loadBio(requestCtx context.Context, filename string) (*Bio, er
 		return nil, fmt.Errorf("failed to read bio file %s: %w", filePath, err)
 	}
 
-	// Parse frontmatter and markdown
-	md := goldmark.New(
-		goldmark.WithExtensions(
-			extension.GFM,
-			&frontmatter.Extender{},
-		),
-		goldmark.WithParserOptions(
-			parser.WithAutoHeadingID(),
-		),
-		goldmark.WithRendererOptions(
-			html.WithHardWraps(),
-			html.WithXHTML(),
-		),
-	)
-
-	// Create parser context and parse the document
-	parseCtx := parser.NewContext()
-	reader := text.NewReader(content)
-	doc := md.Parser().Parse(reader, parser.WithContext(parseCtx))
+	// Split frontmatter from markdown, a bio without frontmatter is all markdown
+	frontmatterBytes, markdownContent, err := render.SplitFrontmatter(content)
+	if err != nil && err != render.ErrNoFrontmatter {
+		s.logger.Printf("BIO: Warning - %v in %s", err, filename)
+	}
 
 	// Extract frontmatter
 	var frontMatter struct {
@@ -80,23 +83,32 @@ func (s *service) loadBio(requestCtx context.Context, filename string) (*Bio, er
 		Subtitle string `yaml:"subtitle"`
 	}
 
-	if d := frontmatter.Get(parseCtx); d != nil {
-		if err := d.Decode(&frontMatter); err != nil {
+	if len(frontmatterBytes) > 0 {
+		if err := yaml.Unmarshal(frontmatterBytes, &frontMatter); err != nil {
 			s.logger.Printf("BIO: Warning - failed to decode frontmatter in %s: %v", filename, err)
 		}
 	}
 
 	// Convert markdown to HTML
-	var buf strings.Builder
-	if err := md.Renderer().Render(&buf, content, doc); err != nil {
-		return nil, fmt.Errorf("failed to render markdown: %w", err)
+	rendered := s.renderer.WithSource(os.DirFS(s.contentDir), path.Dir(filename)).Render(markdownContent)
+	if err := rendered.Err(); err != nil {
+		s.logger.Printf("BIO: Error rendering %s: %v", filename, err)
+		return nil, fmt.Errorf("invalid bio content in %s: %w", filename, err)
 	}
 
 	bio := &Bio{
 		Title:    frontMatter.Title,
 		Subtitle: frontMatter.Subtitle,
-		Content:  template.HTML(buf.String()),
-		LastMod:  time.Now(), // TODO: Get actual file modification time from git
+		Content:  template.HTML(rendered.HTML),
+		Markdown: string(markdownContent),
+		LastMod:  time.Now(),
+	}
+	if s.history != nil {
+		revisions := s.history.Lookup(requestCtx, filePath)
+		bio.Created = revisions.Created
+		if !revisions.Updated.IsZero() {
+			bio.LastMod = revisions.Updated
+		}
 	}
 
 	s.logger.Printf("BIO: Successfully loaded bio: %s", bio.Title)
@@ -0,0 +1,164 @@
+package blog
+
+import (
+	"context"
+	"io/fs"
+
+	"blockhead.consulting/internal/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// authorsPath is where the authors registry lives relative to the content root
+const authorsPath = "content/authors.yml"
+
+// Author is a person posts are credited to
+type Author struct {
+	ID     string       `yaml:"id" json:"id"`
+	Name   string       `yaml:"name" json:"name"`
+	Bio    string       `yaml:"bio" json:"bio,omitempty"`
+	Avatar string       `yaml:"avatar" json:"avatar,omitempty"` // Image URL, usually under /static/
+	Links  []AuthorLink `yaml:"links" json:"links,omitempty"`
+
+	// Registered authors are listed in authors.yml and have an author page.
+	// Names in frontmatter that aren't are credited as written.
+	Registered bool `yaml:"-" json:"-"`
+}
+
+// AuthorLink is a profile or website of an author
+type AuthorLink struct {
+	Label string `yaml:"label" json:"label"`
+	URL   string `yaml:"url" json:"url"`
+}
+
+// AuthorsConfig is the authors registry in authors.yml. The first author is
+// credited with posts that don't name any.
+type AuthorsConfig struct {
+	Authors []Author `yaml:"authors"`
+}
+
+// AuthorSlug returns the URL form of an author ID or name
+func AuthorSlug(id string) string {
+	return slugify(id)
+}
+
+// loadAuthors reads the authors registry, which is optional
+func (s *service) loadAuthors() *AuthorsConfig {
+	data, err := fs.ReadFile(s.blogFS, authorsPath)
+	if err != nil {
+		return &AuthorsConfig{}
+	}
+
+	var config AuthorsConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		s.logger.Printf("BLOG: Warning - could not parse %s: %v, crediting posts as written", authorsPath, err)
+		return &AuthorsConfig{}
+	}
+	for i := range config.Authors {
+		config.Authors[i].ID = AuthorSlug(config.Authors[i].ID)
+		config.Authors[i].Registered = true
+	}
+
+	s.logger.Printf("BLOG: Loaded %d authors", len(config.Authors))
+	return &config
+}
+
+// Find returns the registered author with an ID or name, matched by slug
+func (c *AuthorsConfig) Find(id string) (*Author, bool) {
+	if c == nil {
+		return nil, false
+	}
+	slug := AuthorSlug(id)
+	for i := range c.Authors {
+		if c.Authors[i].ID == slug || AuthorSlug(c.Authors[i].Name) == slug {
+			return &c.Authors[i], true
+		}
+	}
+	return nil, false
+}
+
+// resolve credits a post's named authors to their registered entries, or
+// to the default author when it names none. Unregistered names are kept as
+// written.
+func (c *AuthorsConfig) resolve(named []Author) []Author {
+	if len(named) == 0 {
+		if c == nil || len(c.Authors) == 0 {
+			return nil
+		}
+		return []Author{c.Authors[0]}
+	}
+
+	authors := make([]Author, 0, len(named))
+	for _, author := range named {
+		if registered, ok := c.Find(author.ID); ok {
+			authors = append(authors, *registered)
+			continue
+		}
+		authors = append(authors, author)
+	}
+	return authors
+}
+
+// namedAuthors credits a post to IDs or names as written, until they're
+// resolved against the registry
+func namedAuthors(names []string) []Author {
+	var authors []Author
+	for _, name := range names {
+		if slug := AuthorSlug(name); slug != "" {
+			authors = append(authors, Author{ID: slug, Name: name})
+		}
+	}
+	return authors
+}
+
+// AuthorNames returns the authors a post names, from authors or the single
+// author shorthand
+func (f *Frontmatter) AuthorNames() []string {
+	if len(f.Authors) > 0 {
+		return f.Authors
+	}
+	if f.Author != "" {
+		return []string{f.Author}
+	}
+	return nil
+}
+
+// HasAuthor reports whether a post is credited to an author
+func (p *Post) HasAuthor(id string) bool {
+	slug := AuthorSlug(id)
+	for _, author := range p.Authors {
+		if author.ID == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAuthor returns a registered author by ID
+func (s *service) GetAuthor(ctx context.Context, id string) (*Author, error) {
+	author, ok := s.current().authors.Find(id)
+	if !ok {
+		return nil, errors.NotFound("author")
+	}
+	result := *author
+	return &result, nil
+}
+
+// GetAuthors returns the registered authors in the order of authors.yml
+func (s *service) GetAuthors(ctx context.Context) []Author {
+	snap := s.current()
+	if snap.authors == nil {
+		return nil
+	}
+	return append([]Author(nil), snap.authors.Authors...)
+}
+
+// GetByAuthor returns the published posts credited to an author
+func (s *service) GetByAuthor(ctx context.Context, id string) []Post {
+	var results []Post
+	for _, post := range s.GetAll(ctx) {
+		if post.HasAuthor(id) {
+			results = append(results, post)
+		}
+	}
+	return results
+}
@@ -3,6 +3,9 @@ package blog
 import (
 	"html/template"
 	"time"
+
+	"blockhead.consulting/internal/history"
+	"blockhead.consulting/internal/render"
 )
 
 // Post represents a blog post
@@ -12,18 +15,96 @@ type Post struct {
 	Date        time.Time     `json:"date"`
 	Summary     string        `json:"summary"`
 	Content     template.HTML `json:"-"`
+	Markdown    string        `json:"-"` // Source of Content, without the frontmatter
 	ReadingTime int           `json:"reading_time"`
 	Tags        []string      `json:"tags"`
 	FileName    string        `json:"file_name"`
+	Draft       bool          `json:"draft,omitempty"`
+	PublishAt   time.Time     `json:"publish_at,omitempty"`
+	TOC         []TOCEntry    `json:"toc,omitempty"`
+	TOCPlaced   bool          `json:"-"` // The TOC was rendered inside Content by a [[toc]] marker
+	Series      string        `json:"series,omitempty"`
+	SeriesOrder int           `json:"series_order,omitempty"`
+	Description string        `json:"description,omitempty"` // For search engines and link previews, the summary when empty
+	Image       string        `json:"image,omitempty"`       // Social card image URL
+	Canonical   string        `json:"canonical,omitempty"`   // URL of the original when the post is republished
+	Authors     []Author      `json:"authors,omitempty"`     // Credited authors, the default author when the post names none
+
+	// Revision history, filled in by WithHistory
+	Created   time.Time          `json:"created,omitempty"`   // First commit of the file, zero outside git
+	Updated   time.Time          `json:"updated,omitempty"`   // Last commit, or the file's modification time outside git
+	Revisions []history.Revision `json:"revisions,omitempty"` // Commits that changed the post, newest first
+
+	// Localization, filled in by WithLocales
+	Lang         string   `json:"lang,omitempty"`         // Locale the post is written in
+	Translations []string `json:"translations,omitempty"` // Other locales the post is available in
+
+	RelatedPinned  []string `json:"-"` // Slugs always listed first as related posts
+	RelatedExclude []string `json:"-"` // Slugs never listed as related posts
+}
+
+// WasUpdated reports whether the post was revised in git after the day it
+// was published
+func (p *Post) WasUpdated() bool {
+	return len(p.Revisions) > 1 && p.Updated.After(p.Date.AddDate(0, 0, 1))
+}
+
+// LastModified returns when the post last changed, its date unless it was
+// revised since
+func (p *Post) LastModified() time.Time {
+	if p.WasUpdated() {
+		return p.Updated
+	}
+	return p.Date
+}
+
+// IsPublished reports whether the post is visible to readers at the given time
+func (p *Post) IsPublished(now time.Time) bool {
+	if p.Draft {
+		return false
+	}
+	return p.PublishAt.IsZero() || !now.Before(p.PublishAt)
+}
+
+// TOCEntry is a heading in a post's table of contents
+type TOCEntry = render.TOCEntry
+
+// TOCHTML returns the table of contents to show above the post, or nothing
+// when the post placed it with a [[toc]] marker, opted out, or is too short
+// to need one
+func (p *Post) TOCHTML() template.HTML {
+	if p.TOCPlaced || render.CountTOCEntries(p.TOC) < render.MinTOCHeadings {
+		return ""
+	}
+	return render.TOCHTML(p.TOC)
+}
+
+// SearchResult is a post matching a search query
+type SearchResult struct {
+	Post    Post
+	Score   float64
+	Snippet template.HTML // Excerpt with matching words in <mark>, empty if none matched
 }
 
 // Frontmatter represents the YAML frontmatter of a blog post
 type Frontmatter struct {
-	Title       string    `yaml:"title"`
-	Date        time.Time `yaml:"date"`
-	Summary     string    `yaml:"summary"`
-	Tags        []string  `yaml:"tags"`
-	ReadingTime int       `yaml:"readingTime"`
+	Title          string    `yaml:"title"`
+	Date           time.Time `yaml:"date"`
+	Summary        string    `yaml:"summary"`
+	Tags           []string  `yaml:"tags"`
+	ReadingTime    int       `yaml:"readingTime"`
+	Draft          bool      `yaml:"draft"`          // Hide the post everywhere except preview links
+	PublishAt      time.Time `yaml:"publishAt"`      // Hide the post until this time
+	TOC            *bool     `yaml:"toc"`            // Set to false to leave out the table of contents
+	Series         string    `yaml:"series"`         // Name of the multi-part series the post belongs to
+	SeriesOrder    int       `yaml:"seriesOrder"`    // Part number within the series
+	Related        []string  `yaml:"related"`        // Pin these slugs as related posts
+	RelatedExclude []string  `yaml:"relatedExclude"` // Never suggest these slugs as related posts
+	Description    string    `yaml:"description"`    // Meta description, defaults to the summary
+	Image          string    `yaml:"image"`          // Social card image, a local image like in markdown or a URL
+	Canonical      string    `yaml:"canonical"`      // Canonical URL when the post first appeared elsewhere
+	Author         string    `yaml:"author"`         // Shorthand for a single author
+	Authors        []string  `yaml:"authors"`        // IDs from authors.yml or names, defaults to the first registered author
 }
 
 // BlogConfig represents the blog configuration
@@ -33,6 +114,7 @@ type BlogConfig struct {
 		Subtitle   string       `yaml:"subtitle"`
 		TagFilters []TagFilter  `yaml:"tag_filters"`
 		Search     SearchConfig `yaml:"search"`
+		Code       CodeConfig   `yaml:"code"`
 	} `yaml:"blog"`
 }
 
@@ -44,8 +126,15 @@ type TagFilter struct {
 	Aliases []string `yaml:"aliases,omitempty"`
 }
 
+// CodeConfig names the chroma styles code blocks are colored with for
+// readers using a light or a dark color scheme. Empty names use the defaults.
+type CodeConfig struct {
+	LightStyle string `yaml:"light_style"`
+	DarkStyle  string `yaml:"dark_style"`
+}
+
 // SearchConfig represents search configuration
 type SearchConfig struct {
 	Placeholder   string `yaml:"placeholder"`
 	CaseSensitive bool   `yaml:"case_sensitive"`
-}
\ No newline at end of file
+}
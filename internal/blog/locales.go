@@ -0,0 +1,135 @@
+package blog
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"blockhead.consulting/internal/i18n"
+)
+
+// WithLocales publishes the blog in more than one language. Posts in the
+// default locale are written as slug.md and their translations as
+// slug.<lang>.md. Readers in another locale see the translation of a post
+// where there is one and the original otherwise, chosen by the locale in
+// the context passed to each method.
+func WithLocales(defaultLocale string, others ...string) Option {
+	return func(s *service) {
+		s.defaultLocale = i18n.Normalize(defaultLocale)
+		s.locales = nil
+		for _, code := range others {
+			if code = i18n.Normalize(code); code != "" && code != s.defaultLocale {
+				s.locales = append(s.locales, code)
+			}
+		}
+	}
+}
+
+// splitLocale returns the slug and locale of a post file name without its
+// .md extension. Only configured locales count, so slugs may contain dots.
+func (s *service) splitLocale(name string) (string, string) {
+	if dot := strings.LastIndex(name, "."); dot > 0 {
+		lang := i18n.Normalize(name[dot+1:])
+		for _, code := range s.locales {
+			if lang == code {
+				return name[:dot], code
+			}
+		}
+	}
+	return name, s.defaultLocale
+}
+
+// localized returns the snapshot of the locale in the context, the default
+// locale's when it has none or isn't published
+func (s *service) localized(ctx context.Context) *snapshot {
+	snap := s.current()
+	if view, ok := snap.views[i18n.Normalize(i18n.FromContext(ctx))]; ok {
+		return view
+	}
+	return snap
+}
+
+// buildViews indexes the posts of every other locale: each view holds the
+// default posts with their translations swapped in, plus posts written only
+// in that locale. Every post learns which other locales it's available in.
+func (s *service) buildViews(next *snapshot, translated []Post) map[string]*snapshot {
+	if len(s.locales) == 0 {
+		return nil
+	}
+
+	available := make(map[string][]string) // slug -> locales
+	for _, post := range next.posts {
+		available[post.Slug] = append(available[post.Slug], post.Lang)
+	}
+	for _, post := range translated {
+		available[post.Slug] = append(available[post.Slug], post.Lang)
+	}
+	withTranslations := func(post Post) Post {
+		post.Translations = nil
+		for _, code := range available[post.Slug] {
+			if code != post.Lang {
+				post.Translations = append(post.Translations, code)
+			}
+		}
+		return post
+	}
+	for i := range next.posts {
+		next.posts[i] = withTranslations(next.posts[i])
+	}
+
+	views := make(map[string]*snapshot, len(s.locales))
+	for _, code := range s.locales {
+		byslug := make(map[string]Post)
+		for _, post := range translated {
+			if post.Lang == code {
+				byslug[post.Slug] = withTranslations(post)
+			}
+		}
+
+		view := newSnapshot(next.config)
+		view.generation = next.generation
+		view.loadedAt = next.loadedAt
+		view.loadErrors = next.loadErrors
+		view.authors = next.authors
+		view.lang = code
+		for _, post := range next.posts {
+			if translation, ok := byslug[post.Slug]; ok {
+				post = translation
+				delete(byslug, post.Slug)
+			}
+			view.posts = append(view.posts, post)
+		}
+		for _, post := range translated {
+			if translation, ok := byslug[post.Slug]; ok && post.Lang == code {
+				view.posts = append(view.posts, translation)
+			}
+		}
+		sort.SliceStable(view.posts, func(i, j int) bool {
+			return view.posts[i].Date.After(view.posts[j].Date)
+		})
+		s.index(view)
+		views[code] = view
+	}
+	return views
+}
+
+// inherit fills in what a translation leaves out from the original post:
+// its date, tags and place in a series. A translation is never published
+// before its original, so it's a draft while the original is and scheduled
+// no earlier than the original.
+func (p *Post) inherit(original *Post) {
+	p.Draft = p.Draft || original.Draft
+	if original.PublishAt.After(p.PublishAt) {
+		p.PublishAt = original.PublishAt
+	}
+	if p.Date.IsZero() {
+		p.Date = original.Date
+	}
+	if len(p.Tags) == 0 {
+		p.Tags = original.Tags
+	}
+	if p.Series == "" {
+		p.Series = original.Series
+		p.SeriesOrder = original.SeriesOrder
+	}
+}
@@ -0,0 +1,51 @@
+package blog
+
+// Pagination describes one page of a post listing
+type Pagination struct {
+	Page       int // 1-based
+	PerPage    int
+	TotalPosts int
+	TotalPages int
+}
+
+// HasPrev reports whether there is a newer page
+func (p Pagination) HasPrev() bool {
+	return p.Page > 1
+}
+
+// HasNext reports whether there is an older page
+func (p Pagination) HasNext() bool {
+	return p.Page < p.TotalPages
+}
+
+// Paginate returns the posts on the given page. The first page always
+// exists, even when there are no posts; ok is false for any page past the
+// last one.
+func Paginate(posts []Post, page, perPage int) ([]Post, Pagination, bool) {
+	if perPage < 1 {
+		perPage = len(posts)
+	}
+
+	totalPages := 1
+	if len(posts) > 0 && perPage > 0 {
+		totalPages = (len(posts) + perPage - 1) / perPage
+	}
+
+	p := Pagination{
+		Page:       page,
+		PerPage:    perPage,
+		TotalPosts: len(posts),
+		TotalPages: totalPages,
+	}
+	if page < 1 || page > totalPages {
+		return nil, p, false
+	}
+
+	start := (page - 1) * perPage
+	end := start + perPage
+	if end > len(posts) {
+		end = len(posts)
+	}
+
+	return posts[start:end], p, true
+}
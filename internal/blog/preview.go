@@ -0,0 +1,64 @@
+package blog
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"time"
+
+	"blockhead.consulting/internal/errors"
+)
+
+// PreviewSigner mints and verifies expiring HMAC-signed links that let
+// reviewers read unpublished posts
+type PreviewSigner struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// NewPreviewSigner creates a signer using the given secret key
+func NewPreviewSigner(secret []byte) *PreviewSigner {
+	return &PreviewSigner{
+		secret: secret,
+		now:    time.Now,
+	}
+}
+
+// PreviewPath returns the site-relative preview URL for a post
+func (p *PreviewSigner) PreviewPath(slug string, expires time.Time) string {
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	query.Set("sig", p.Sign(slug, expires))
+	return "/blog/preview/" + url.PathEscape(slug) + "?" + query.Encode()
+}
+
+// Sign returns the hex signature binding a slug to an expiry time
+func (p *PreviewSigner) Sign(slug string, expires time.Time) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(slug))
+	mac.Write([]byte{0})
+	mac.Write([]byte(strconv.FormatInt(expires.Unix(), 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a preview link's expiry and signature
+func (p *PreviewSigner) Verify(slug, expires, signature string) error {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return errors.New(errors.ErrCodeInvalidInput, "invalid preview expiry")
+	}
+
+	expiresAt := time.Unix(unix, 0)
+	if !p.now().Before(expiresAt) {
+		return errors.Unauthorized("preview link expired")
+	}
+
+	expected := p.Sign(slug, expiresAt)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.Unauthorized("invalid preview signature")
+	}
+
+	return nil
+}
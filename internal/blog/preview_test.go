@@ -0,0 +1,42 @@
+package blog
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewSigner(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	signer := NewPreviewSigner([]byte("test-secret"))
+	signer.now = func() time.Time { return now }
+
+	expires := now.Add(time.Hour)
+	path := signer.PreviewPath("my-draft", expires)
+	require.True(t, strings.HasPrefix(path, "/blog/preview/my-draft?"))
+
+	parsed, err := url.Parse(path)
+	require.NoError(t, err)
+	query := parsed.Query()
+
+	// Valid link
+	assert.NoError(t, signer.Verify("my-draft", query.Get("expires"), query.Get("sig")))
+
+	// Signature is bound to the slug and expiry
+	assert.Error(t, signer.Verify("other-post", query.Get("expires"), query.Get("sig")))
+	assert.Error(t, signer.Verify("my-draft", "9999999999", query.Get("sig")))
+	assert.Error(t, signer.Verify("my-draft", "not-a-number", query.Get("sig")))
+
+	// A different secret rejects the link
+	other := NewPreviewSigner([]byte("other-secret"))
+	other.now = signer.now
+	assert.Error(t, other.Verify("my-draft", query.Get("expires"), query.Get("sig")))
+
+	// Expired links are rejected
+	signer.now = func() time.Time { return expires }
+	assert.Error(t, signer.Verify("my-draft", query.Get("expires"), query.Get("sig")))
+}
@@ -0,0 +1,141 @@
+package blog
+
+import (
+	"math"
+	"sort"
+
+	"blockhead.consulting/internal/search"
+)
+
+// Weights of the two signals combined into a related-post score
+const (
+	relatedTagWeight  = 0.6
+	relatedTextWeight = 0.4
+)
+
+// buildRelated ranks, for every post, the other posts by how related they
+// are. Posts pinned in frontmatter come first in the order given, excluded
+// posts never appear, and the rest are ordered by a mix of tag overlap and
+// TF-IDF cosine similarity of their text. Unrelated posts (score zero) are
+// left out.
+func (s *service) buildRelated(snap *snapshot) map[string][]string {
+	posts := snap.posts
+	vectors := tfidfVectors(posts)
+	tagSets := make([]map[string]bool, len(posts))
+	for i, post := range posts {
+		tagSets[i] = make(map[string]bool, len(post.Tags))
+		for _, tag := range snap.canonicalPostTags(post) {
+			tagSets[i][tag] = true
+		}
+	}
+
+	related := make(map[string][]string, len(posts))
+	for i, post := range posts {
+		excluded := map[string]bool{post.Slug: true}
+		for _, slug := range post.RelatedExclude {
+			excluded[slug] = true
+		}
+
+		var ranked []string
+		for _, slug := range post.RelatedPinned {
+			if _, exists := snap.postMap[slug]; !exists {
+				s.logger.Printf("BLOG: Warning - post '%s' pins unknown related post '%s'", post.Slug, slug)
+				continue
+			}
+			if !excluded[slug] {
+				ranked = append(ranked, slug)
+				excluded[slug] = true
+			}
+		}
+
+		type candidate struct {
+			slug  string
+			score float64
+		}
+		var candidates []candidate
+		for j, other := range posts {
+			if excluded[other.Slug] {
+				continue
+			}
+			score := relatedTagWeight*jaccard(tagSets[i], tagSets[j]) +
+				relatedTextWeight*cosine(vectors[i], vectors[j])
+			if score > 0 {
+				candidates = append(candidates, candidate{slug: other.Slug, score: score})
+			}
+		}
+
+		// Ties go to the newer post, posts are sorted newest first
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return candidates[a].score > candidates[b].score
+		})
+		for _, c := range candidates {
+			ranked = append(ranked, c.slug)
+		}
+
+		related[post.Slug] = ranked
+	}
+
+	return related
+}
+
+// tfidfVectors returns a unit-length TF-IDF vector for the text of each post
+func tfidfVectors(posts []Post) []map[string]float64 {
+	counts := make([]map[string]float64, len(posts))
+	docFreq := make(map[string]int)
+
+	for i, post := range posts {
+		counts[i] = make(map[string]float64)
+		text := post.Title + " " + post.Summary + " " + search.PlainText(string(post.Content))
+		for _, term := range search.Terms(text) {
+			if counts[i][term] == 0 {
+				docFreq[term]++
+			}
+			counts[i][term]++
+		}
+	}
+
+	n := float64(len(posts))
+	for _, vector := range counts {
+		var norm float64
+		for term, tf := range vector {
+			// Terms in every post say nothing about similarity
+			weight := (1 + math.Log(tf)) * math.Log(n/float64(docFreq[term]))
+			vector[term] = weight
+			norm += weight * weight
+		}
+		norm = math.Sqrt(norm)
+		for term := range vector {
+			if norm > 0 {
+				vector[term] /= norm
+			}
+		}
+	}
+
+	return counts
+}
+
+// cosine returns the cosine similarity of two unit vectors
+func cosine(a, b map[string]float64) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	var dot float64
+	for term, weight := range a {
+		dot += weight * b[term]
+	}
+	return dot
+}
+
+// jaccard returns the overlap of two tag sets
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for tag := range a {
+		if b[tag] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
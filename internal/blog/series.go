@@ -0,0 +1,102 @@
+package blog
+
+import (
+	"context"
+	"sort"
+
+	"blockhead.consulting/internal/errors"
+)
+
+// Series is a multi-part set of posts meant to be read in order
+type Series struct {
+	Name  string
+	Slug  string
+	Posts []Post // Published parts in reading order
+}
+
+// SeriesPosition locates a post within its series
+type SeriesPosition struct {
+	Series *Series
+	Part   int // 1-based
+	Total  int
+	Prev   *Post
+	Next   *Post
+}
+
+// SeriesSlug returns the URL form of a series name
+func SeriesSlug(name string) string {
+	return slugify(name)
+}
+
+// Position returns where the post with the given slug sits in the series
+func (s *Series) Position(slug string) (SeriesPosition, bool) {
+	for i := range s.Posts {
+		if s.Posts[i].Slug != slug {
+			continue
+		}
+
+		pos := SeriesPosition{Series: s, Part: i + 1, Total: len(s.Posts)}
+		if i > 0 {
+			pos.Prev = &s.Posts[i-1]
+		}
+		if i < len(s.Posts)-1 {
+			pos.Next = &s.Posts[i+1]
+		}
+		return pos, true
+	}
+
+	return SeriesPosition{}, false
+}
+
+// GetSeries returns the published parts of a series, by name or slug
+func (s *service) GetSeries(ctx context.Context, name string) (*Series, error) {
+	slug := SeriesSlug(name)
+	now := s.clock()
+
+	series := &Series{Slug: slug}
+	for _, post := range s.localized(ctx).posts {
+		if post.Series == "" || SeriesSlug(post.Series) != slug || !post.IsPublished(now) {
+			continue
+		}
+		series.Posts = append(series.Posts, post)
+	}
+
+	if len(series.Posts) == 0 {
+		return nil, errors.NotFound("blog series")
+	}
+
+	// Parts without an order go last, by date
+	sort.SliceStable(series.Posts, func(i, j int) bool {
+		a, b := series.Posts[i], series.Posts[j]
+		if a.SeriesOrder != b.SeriesOrder {
+			if a.SeriesOrder == 0 || b.SeriesOrder == 0 {
+				return b.SeriesOrder == 0
+			}
+			return a.SeriesOrder < b.SeriesOrder
+		}
+		return a.Date.Before(b.Date)
+	})
+	series.Name = series.Posts[0].Series
+
+	return series, nil
+}
+
+// GetAllSeries returns every series with at least one published part
+func (s *service) GetAllSeries(ctx context.Context) []*Series {
+	seen := make(map[string]bool)
+	var all []*Series
+
+	for _, post := range s.GetAll(ctx) {
+		slug := SeriesSlug(post.Series)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+
+		if series, err := s.GetSeries(ctx, slug); err == nil {
+			all = append(all, series)
+		}
+	}
+
+	return all
+}
@@ -1,66 +1,113 @@
 package blog
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"html/template"
-	"io"
 	"io/fs"
 	"log"
 	"math"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"blockhead.consulting/internal/errors"
 	"blockhead.consulting/internal/events"
+	"blockhead.consulting/internal/history"
+	"blockhead.consulting/internal/i18n"
 	"blockhead.consulting/internal/registry"
+	"blockhead.consulting/internal/render"
+	"blockhead.consulting/internal/search"
 	
-	"github.com/alecthomas/chroma/v2/formatters/html"
-	"github.com/alecthomas/chroma/v2/lexers"
-	"github.com/alecthomas/chroma/v2/styles"
-	"github.com/gomarkdown/markdown"
-	"github.com/gomarkdown/markdown/ast"
-	mdhtml "github.com/gomarkdown/markdown/html"
-	"github.com/gomarkdown/markdown/parser"
 	"gopkg.in/yaml.v3"
 )
 
-// Service provides blog functionality
+// Service provides blog functionality. Methods taking a context read the
+// posts of the locale in it (see i18n.WithLocale and WithLocales).
 type Service interface {
 	// GetAll returns all published blog posts
 	GetAll(ctx context.Context) []Post
 	
-	// GetBySlug returns a blog post by its slug
+	// GetBySlug returns a published blog post by its slug
 	GetBySlug(ctx context.Context, slug string) (*Post, error)
 	
+	// GetPreview returns a blog post by its slug even if it is unpublished
+	GetPreview(ctx context.Context, slug string) (*Post, error)
+	
 	// Search searches blog posts by query
 	Search(ctx context.Context, query string) []Post
 	
-	// GetByTag returns posts with a specific tag
+	// SearchResults searches blog posts by query and returns ranked results with excerpts
+	SearchResults(ctx context.Context, query string) []SearchResult
+	
+	// GetByTag returns posts with a specific tag or any of its aliases
 	GetByTag(ctx context.Context, tag string) []Post
 	
-	// GetTags returns all unique tags
+	// GetTags returns all unique canonical tags
 	GetTags(ctx context.Context) []string
 	
+	// CanonicalTag resolves a tag or alias to the slug of its tag page
+	CanonicalTag(tag string) string
+	
+	// TagName returns the display name of a tag
+	TagName(tag string) string
+	
+	// GetSeries returns the published parts of a series in reading order
+	GetSeries(ctx context.Context, name string) (*Series, error)
+	
+	// GetAllSeries returns every series with published parts
+	GetAllSeries(ctx context.Context) []*Series
+	
+	// GetByAuthor returns the published posts credited to an author
+	GetByAuthor(ctx context.Context, id string) []Post
+	
+	// GetAuthor returns an author registered in authors.yml
+	GetAuthor(ctx context.Context, id string) (*Author, error)
+	
+	// GetAuthors returns the registered authors, the default author first
+	GetAuthors(ctx context.Context) []Author
+	
+	// RelatedTo returns up to n published posts related to the post with the given slug
+	RelatedTo(ctx context.Context, slug string, n int) []Post
+	
+	// GetByDate returns posts published in a year, or in one month of it when month is not zero
+	GetByDate(ctx context.Context, year int, month time.Month) []Post
+	
 	// LoadPosts loads posts from the filesystem
 	LoadPosts(ctx context.Context) error
 	
 	// GetBlogConfig returns the blog configuration
 	GetBlogConfig() *BlogConfig
+	
+	// Status reports the generation and errors of the last load
+	Status() LoadStatus
 }
 
 // service implements the blog service
 type service struct {
-	posts      []Post
-	postMap    map[string]*Post
-	tagIndex   map[string][]int // tag -> post indices
-	blogFS     fs.FS
-	blogDir    string // directory containing blog posts
-	logger     *log.Logger
-	eventBus   events.EventBus
-	blogConfig *BlogConfig
+	snap     atomic.Pointer[snapshot] // posts and indexes being served
+	blogFS   fs.FS
+	blogDir  string // directory containing blog posts
+	logger   *log.Logger
+	eventBus events.EventBus
+	renderer *render.Renderer // converts post markdown to HTML
+	history  history.Provider // created and updated dates, nil to leave them out
+	
+	// Locales of posts, see locales.go
+	defaultLocale string   // locale of posts without a locale in their file name
+	locales       []string // other locales posts are translated into
+	now      func() time.Time // clock used to decide which posts are published
+	loadMu   sync.Mutex       // serializes loads so generations don't interleave
+	
+	// Hot reload from disk, see watch.go
+	contentDir    string        // on-disk content root, empty when serving the embed
+	watchInterval time.Duration // how often contentDir is polled, zero disables watching
+	stopWatch     context.CancelFunc
+	watchDone     chan struct{}
 }
 
 // NewService creates a new blog service
@@ -69,22 +116,30 @@ func NewService(blogFS fs.FS, logger *log.Logger, eventBus events.EventBus) *ser
 }
 
 // NewServiceWithOptions creates a new blog service with custom options
-func NewServiceWithOptions(blogFS fs.FS, blogDir string, logger *log.Logger, eventBus events.EventBus) *service {
+func NewServiceWithOptions(blogFS fs.FS, blogDir string, logger *log.Logger, eventBus events.EventBus, opts ...Option) *service {
 	if logger == nil {
 		logger = log.Default()
 	}
 	
 	s := &service{
-		postMap:  make(map[string]*Post),
-		tagIndex: make(map[string][]int),
 		blogFS:   blogFS,
 		blogDir:  blogDir,
 		logger:   logger,
 		eventBus: eventBus,
+		renderer: render.Site(),
+		now:      time.Now,
+		
+		defaultLocale: i18n.DefaultLocale,
+		
+		watchInterval: DefaultWatchInterval,
 	}
 	
-	// Load blog configuration
-	s.loadBlogConfig()
+	for _, opt := range opts {
+		opt(s)
+	}
+	
+	// Load blog configuration, posts follow in Start
+	s.snap.Store(newSnapshot(s.loadBlogConfig()))
 	
 	return s
 }
@@ -102,35 +157,55 @@ func (s *service) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to load blog posts: %w", err)
 	}
 	
-	s.logger.Printf("BLOG: Blog service started with %d posts", len(s.posts))
+	s.logger.Printf("BLOG: Blog service started with %d posts", len(s.current().posts))
+	
+	s.startWatching()
 	
 	return nil
 }
 
 func (s *service) Stop(ctx context.Context) error {
 	s.logger.Printf("BLOG: Stopping blog service...")
+	s.stopWatching()
 	return nil
 }
 
+// Health fails only when no posts are served. Posts that failed to parse
+// leave the service degraded, not down; Status reports them.
 func (s *service) Health(ctx context.Context) error {
-	if len(s.posts) == 0 {
+	status := s.Status()
+	if status.Generation == 0 {
 		return fmt.Errorf("no blog posts loaded")
 	}
+	if status.Posts == 0 {
+		return fmt.Errorf("generation %d: no blog posts loaded", status.Generation)
+	}
 	return nil
 }
 
-// GetAll returns all blog posts
+// Status reports the generation and errors of the last load
+func (s *service) Status() LoadStatus {
+	return s.current().status()
+}
+
+// GetAll returns all published blog posts
 func (s *service) GetAll(ctx context.Context) []Post {
 	// Return a copy to prevent modification
-	result := make([]Post, len(s.posts))
-	copy(result, s.posts)
+	snap := s.localized(ctx)
+	now := s.clock()
+	result := make([]Post, 0, len(snap.posts))
+	for _, post := range snap.posts {
+		if post.IsPublished(now) {
+			result = append(result, post)
+		}
+	}
 	return result
 }
 
-// GetBySlug returns a blog post by slug
+// GetBySlug returns a published blog post by slug
 func (s *service) GetBySlug(ctx context.Context, slug string) (*Post, error) {
-	post, exists := s.postMap[slug]
-	if !exists {
+	post, exists := s.localized(ctx).postMap[slug]
+	if !exists || !post.IsPublished(s.clock()) {
 		return nil, errors.NotFound("blog post")
 	}
 	
@@ -139,22 +214,69 @@ func (s *service) GetBySlug(ctx context.Context, slug string) (*Post, error) {
 	return &result, nil
 }
 
+// GetPreview returns a blog post by slug regardless of its publication state
+func (s *service) GetPreview(ctx context.Context, slug string) (*Post, error) {
+	post, exists := s.localized(ctx).postMap[slug]
+	if !exists {
+		return nil, errors.NotFound("blog post")
+	}
+	
+	result := *post
+	return &result, nil
+}
+
+// clock returns the current time used for publication checks
+func (s *service) clock() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+	return s.now()
+}
+
 // Search searches blog posts
 func (s *service) Search(ctx context.Context, query string) []Post {
-	if query == "" {
+	if strings.TrimSpace(query) == "" {
 		return s.GetAll(ctx)
 	}
 	
-	query = strings.ToLower(query)
-	var results []Post
+	results := s.SearchResults(ctx, query)
+	posts := make([]Post, len(results))
+	for i, result := range results {
+		posts[i] = result.Post
+	}
+	
+	return posts
+}
+
+// SearchResults returns published posts matching the query, best match first
+func (s *service) SearchResults(ctx context.Context, query string) []SearchResult {
+	snap := s.localized(ctx)
+	q := search.ParseQuery(query)
+	for i, tag := range q.Tags {
+		q.Tags[i] = snap.canonicalTag(tag)
+	}
+	if q.IsEmpty() || snap.searchIndex == nil {
+		posts := s.GetAll(ctx)
+		results := make([]SearchResult, len(posts))
+		for i, post := range posts {
+			results[i] = SearchResult{Post: post}
+		}
+		return results
+	}
 	
-	for _, post := range s.posts {
-		// Search in title, summary, and tags
-		if strings.Contains(strings.ToLower(post.Title), query) ||
-			strings.Contains(strings.ToLower(post.Summary), query) ||
-			s.containsTag(post.Tags, query) {
-			results = append(results, post)
+	now := s.clock()
+	var results []SearchResult
+	for _, hit := range snap.searchIndex.Search(q) {
+		post, exists := snap.postMap[hit.ID]
+		if !exists || !post.IsPublished(now) {
+			continue
 		}
+		
+		results = append(results, SearchResult{
+			Post:    *post,
+			Score:   hit.Score,
+			Snippet: snap.searchIndex.Snippet(hit.ID, q),
+		})
 	}
 	
 	return results
@@ -162,24 +284,65 @@ func (s *service) Search(ctx context.Context, query string) []Post {
 
 // GetByTag returns posts with a specific tag
 func (s *service) GetByTag(ctx context.Context, tag string) []Post {
-	indices, exists := s.tagIndex[strings.ToLower(tag)]
+	snap := s.localized(ctx)
+	indices, exists := snap.tagIndex[snap.canonicalTag(tag)]
 	if !exists {
 		return []Post{}
 	}
 	
-	results := make([]Post, len(indices))
-	for i, idx := range indices {
-		results[i] = s.posts[idx]
+	now := s.clock()
+	results := make([]Post, 0, len(indices))
+	for _, idx := range indices {
+		if snap.posts[idx].IsPublished(now) {
+			results = append(results, snap.posts[idx])
+		}
+	}
+	
+	return results
+}
+
+// RelatedTo returns up to n published posts related to a post
+func (s *service) RelatedTo(ctx context.Context, slug string, n int) []Post {
+	snap := s.localized(ctx)
+	now := s.clock()
+	var results []Post
+	for _, relatedSlug := range snap.related[slug] {
+		if len(results) >= n {
+			break
+		}
+		if post, exists := snap.postMap[relatedSlug]; exists && post.IsPublished(now) {
+			results = append(results, *post)
+		}
+	}
+	
+	return results
+}
+
+// GetByDate returns published posts from a year, or a month of it
+func (s *service) GetByDate(ctx context.Context, year int, month time.Month) []Post {
+	var results []Post
+	for _, post := range s.GetAll(ctx) {
+		if post.Date.Year() != year || (month != 0 && post.Date.Month() != month) {
+			continue
+		}
+		results = append(results, post)
 	}
 	
 	return results
 }
 
-// GetTags returns all unique tags
+// GetTags returns all unique tags of published posts
 func (s *service) GetTags(ctx context.Context) []string {
+	snap := s.localized(ctx)
+	now := s.clock()
 	var tags []string
-	for tag := range s.tagIndex {
-		tags = append(tags, tag)
+	for tag, indices := range snap.tagIndex {
+		for _, idx := range indices {
+			if snap.posts[idx].IsPublished(now) {
+				tags = append(tags, tag)
+				break
+			}
+		}
 	}
 	sort.Strings(tags)
 	return tags
@@ -187,58 +350,99 @@ func (s *service) GetTags(ctx context.Context) []string {
 
 // LoadPosts loads blog posts from the filesystem
 func (s *service) LoadPosts(ctx context.Context) error {
-	s.posts = []Post{}
-	s.postMap = make(map[string]*Post)
-	s.tagIndex = make(map[string][]int)
+	return s.load(ctx, false)
+}
+
+// load builds a new snapshot from the filesystem and swaps it in. The blog
+// configuration is carried over from the current snapshot unless
+// reloadConfig is set. When the blog directory can't be read the current
+// posts stay in place and the failure is recorded in their load errors.
+func (s *service) load(ctx context.Context, reloadConfig bool) error {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+	
+	prev := s.current()
+	next := newSnapshot(prev.config)
+	if reloadConfig {
+		next = newSnapshot(s.loadBlogConfig())
+	}
+	next.generation = prev.generation + 1
+	next.loadedAt = s.clock()
+	next.authors = s.loadAuthors()
 	
 	// Read blog directory
 	files, err := fs.ReadDir(s.blogFS, s.blogDir)
 	if err != nil {
+		failed := *prev
+		failed.loadErrors = []string{fmt.Sprintf("read %s: %v", s.blogDir, err)}
+		s.snap.Store(&failed)
 		return errors.Wrap(err, errors.ErrCodeIO, "failed to read blog directory")
 	}
 	
+	var translated []Post
 	for _, file := range files {
 		if !strings.HasSuffix(file.Name(), ".md") {
 			continue
 		}
 		
-		filePath := file.Name()
-		if s.blogDir != "." {
-			filePath = s.blogDir + "/" + file.Name()
-		}
-		post, err := s.loadMarkdownPost(filePath)
+		post, err := s.loadMarkdownPost(s.postPath(file.Name()))
 		if err != nil {
 			s.logger.Printf("BLOG: Warning - failed to load %s: %v", file.Name(), err)
+			next.loadErrors = append(next.loadErrors, fmt.Sprintf("%s: %v", file.Name(), err))
+			continue
+		}
+		post.Authors = next.authors.resolve(post.Authors)
+		
+		// Translations are indexed per locale in buildViews
+		if post.Lang != s.defaultLocale {
+			translated = append(translated, *post)
 			continue
 		}
 		
 		// Add to collections
-		s.posts = append(s.posts, *post)
-		s.postMap[post.Slug] = post
+		next.posts = append(next.posts, *post)
 		s.logger.Printf("BLOG: Loaded post with slug: '%s'", post.Slug)
+		if !post.IsPublished(s.clock()) {
+			s.logger.Printf("BLOG: Post '%s' is unpublished (draft or scheduled)", post.Slug)
+		}
 	}
 	
 	// Sort posts by date (newest first)
-	sort.Slice(s.posts, func(i, j int) bool {
-		return s.posts[i].Date.After(s.posts[j].Date)
+	sort.Slice(next.posts, func(i, j int) bool {
+		return next.posts[i].Date.After(next.posts[j].Date)
 	})
 	
-	// Build tag index AFTER sorting
-	for idx, post := range s.posts {
-		for _, tag := range post.Tags {
-			tagLower := strings.ToLower(tag)
-			s.tagIndex[tagLower] = append(s.tagIndex[tagLower], idx)
+	// Translations without a date or tags take the original's
+	originals := make(map[string]*Post, len(next.posts))
+	for i := range next.posts {
+		originals[next.posts[i].Slug] = &next.posts[i]
+	}
+	for i := range translated {
+		if original, ok := originals[translated[i].Slug]; ok {
+			translated[i].inherit(original)
 		}
+		s.logger.Printf("BLOG: Loaded %s translation of '%s'", translated[i].Lang, translated[i].Slug)
+	}
+	
+	next.views = s.buildViews(next, translated)
+	s.index(next)
+	
+	s.snap.Store(next)
+	
+	// Every post was just rendered, so images only earlier versions used go
+	if pruned := s.renderer.PruneImages(); pruned > 0 {
+		s.logger.Printf("BLOG: Dropped %d images no post uses any more", pruned)
 	}
 	
-	s.logger.Printf("BLOG: Loaded %d blog posts", len(s.posts))
+	s.logger.Printf("BLOG: Loaded %d blog posts (generation %d)", len(next.posts), next.generation)
 	
 	// Publish event
 	if s.eventBus != nil {
 		s.eventBus.Publish(ctx, events.NewEventWithContext(ctx,
 			events.EventBlogPublished,
 			map[string]interface{}{
-				"count": len(s.posts),
+				"count":      len(next.posts),
+				"generation": next.generation,
 			},
 		))
 	}
@@ -246,6 +450,83 @@ func (s *service) LoadPosts(ctx context.Context) error {
 	return nil
 }
 
+// index builds the lookups of a snapshot's sorted posts: by slug, by tag,
+// full-text and related posts
+func (s *service) index(snap *snapshot) {
+	for i := range snap.posts {
+		snap.postMap[snap.posts[i].Slug] = &snap.posts[i]
+	}
+	
+	// Build tag index AFTER sorting, grouping aliases under their canonical tag
+	for idx, post := range snap.posts {
+		seen := make(map[string]bool, len(post.Tags))
+		for _, tag := range post.Tags {
+			canonical := snap.canonicalTag(tag)
+			if canonical == "" || seen[canonical] {
+				continue
+			}
+			seen[canonical] = true
+			snap.tagIndex[canonical] = append(snap.tagIndex[canonical], idx)
+			if _, named := snap.tagNames[canonical]; !named {
+				snap.tagNames[canonical] = tag
+			}
+		}
+	}
+	
+	// Build the full-text index, publication is checked at query time
+	documents := make([]search.Document, len(snap.posts))
+	for idx, post := range snap.posts {
+		documents[idx] = search.Document{
+			ID:      post.Slug,
+			Title:   post.Title,
+			Summary: post.Summary,
+			Tags:    post.Tags,
+			TagKeys: snap.canonicalPostTags(post),
+			Body:    search.PlainText(string(post.Content)),
+			Date:    post.Date,
+		}
+	}
+	snap.searchIndex = search.NewIndex(documents)
+	snap.related = s.buildRelated(snap)
+}
+
+// postPath returns the path of a post file within the blog filesystem
+func (s *service) postPath(name string) string {
+	if s.blogDir == "." {
+		return name
+	}
+	return s.blogDir + "/" + name
+}
+
+// ReservedSlugs are the /blog/ path segments served by other routes, so
+// posts with these slugs can't be reached. The router's tests keep the list
+// in step with the routes it registers.
+var ReservedSlugs = []string{"search", "page", "tag", "series", "author", "preview", "feed.xml", "atom.xml", "feed.json"}
+
+// IsReservedSlug reports whether a slug is one of ReservedSlugs
+func IsReservedSlug(slug string) bool {
+	for _, reserved := range ReservedSlugs {
+		if slug == reserved {
+			return true
+		}
+	}
+	return false
+}
+
+// isYear reports whether a slug is a year, whose /blog/ path is the archive
+// of the year rather than a post
+func isYear(slug string) bool {
+	if len(slug) != 4 {
+		return false
+	}
+	for _, r := range slug {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 // loadMarkdownPost loads a single markdown post
 func (s *service) loadMarkdownPost(filename string) (*Post, error) {
 	// Read file
@@ -261,11 +542,25 @@ func (s *service) loadMarkdownPost(filename string) (*Post, error) {
 	}
 	
 	// Convert markdown to HTML
-	htmlContent := s.markdownToHTML(markdownContent)
+	withTOC := frontmatter.TOC == nil || *frontmatter.TOC
+	renderer := s.renderer.WithSource(s.blogFS, path.Dir(filename))
+	rendered := renderer.WithTOC(withTOC).Render(markdownContent)
+	if err := rendered.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeValidation, "invalid post content")
+	}
+	
+	// The social card image resolves like an image in the post
+	image, err := renderer.ImageURL(frontmatter.Image)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeValidation, "invalid post image")
+	}
 	
-	// Generate slug from filename (strip directory path and extension)
+	// Generate slug from filename (strip directory path, extension and locale)
 	baseName := filepath.Base(filename)
-	slug := strings.TrimSuffix(baseName, ".md")
+	slug, lang := s.splitLocale(strings.TrimSuffix(baseName, ".md"))
+	if isYear(slug) {
+		return nil, errors.Validation("slug", fmt.Sprintf("slug %q is taken by the /blog/%s/ archive", slug, slug))
+	}
 	
 	// Calculate reading time if not provided
 	readingTime := frontmatter.ReadingTime
@@ -273,36 +568,75 @@ func (s *service) loadMarkdownPost(filename string) (*Post, error) {
 		readingTime = s.calculateReadingTime(string(markdownContent))
 	}
 	
+	// Revision dates come from git when the post is on disk
+	var revisions history.File
+	if s.history != nil {
+		revisions = s.history.Lookup(context.Background(), s.diskPath(filename))
+	}
+	
 	return &Post{
 		Slug:        slug,
+		Lang:        lang,
 		Title:       frontmatter.Title,
 		Date:        frontmatter.Date,
 		Summary:     frontmatter.Summary,
-		Content:     template.HTML(htmlContent),
+		Content:     template.HTML(rendered.HTML),
+		Markdown:    string(markdownContent),
 		ReadingTime: readingTime,
 		Tags:        frontmatter.Tags,
 		FileName:    filename,
+		Draft:       frontmatter.Draft,
+		PublishAt:   frontmatter.PublishAt,
+		TOC:         rendered.TOC,
+		TOCPlaced:   rendered.TOCPlaced,
+		Series:      frontmatter.Series,
+		SeriesOrder: frontmatter.SeriesOrder,
+		Description: frontmatter.Description,
+		Image:       image,
+		Canonical:   frontmatter.Canonical,
+		Authors:     namedAuthors(frontmatter.AuthorNames()),
+		Created:     revisions.Created,
+		Updated:     revisions.Updated,
+		Revisions:   revisions.Revisions,
+		
+		RelatedPinned:  frontmatter.Related,
+		RelatedExclude: frontmatter.RelatedExclude,
 	}, nil
 }
 
+// diskPath returns where a post file is on disk: under the content
+// directory when serving from disk, otherwise at its embedded path, which
+// mirrors the repository
+func (s *service) diskPath(filename string) string {
+	if s.contentDir != "" {
+		return filepath.Join(s.contentDir, filepath.FromSlash(filename))
+	}
+	return filepath.FromSlash(filename)
+}
+
+// LoadPost loads a single post file the way the service does, for tools that
+// check content without running the site. A nil renderer uses the site
+// defaults.
+func LoadPost(blogFS fs.FS, filename string, renderer *render.Renderer) (*Post, error) {
+	if renderer == nil {
+		renderer = render.Site()
+	}
+	s := &service{blogFS: blogFS, renderer: renderer}
+	return s.loadMarkdownPost(filename)
+}
+
+// ParseFrontmatter splits a post file into its frontmatter and markdown
+func ParseFrontmatter(content []byte) (*Frontmatter, []byte, error) {
+	return (&service{}).parseFrontmatter(content)
+}
+
 // parseFrontmatter parses YAML frontmatter from markdown content
 func (s *service) parseFrontmatter(content []byte) (*Frontmatter, []byte, error) {
-	// Check if content starts with frontmatter delimiter
-	if !bytes.HasPrefix(content, []byte("---\n")) {
-		return nil, nil, errors.New(errors.ErrCodeInvalidFormat, "missing frontmatter delimiter")
-	}
-	
-	// Find the end of frontmatter
-	endDelimiter := []byte("\n---\n")
-	endIndex := bytes.Index(content[4:], endDelimiter)
-	if endIndex == -1 {
-		return nil, nil, errors.New(errors.ErrCodeInvalidFormat, "missing frontmatter end delimiter")
+	frontmatterBytes, markdownContent, err := render.SplitFrontmatter(content)
+	if err != nil {
+		return nil, nil, errors.New(errors.ErrCodeInvalidFormat, err.Error())
 	}
 	
-	// Extract frontmatter and content
-	frontmatterBytes := content[4 : endIndex+4]
-	markdownContent := content[endIndex+8:] // Skip past "\n---\n"
-	
 	// Parse YAML frontmatter
 	var frontmatter Frontmatter
 	if err := yaml.Unmarshal(frontmatterBytes, &frontmatter); err != nil {
@@ -312,79 +646,6 @@ func (s *service) parseFrontmatter(content []byte) (*Frontmatter, []byte, error)
 	return &frontmatter, markdownContent, nil
 }
 
-// markdownToHTML converts markdown to HTML with syntax highlighting
-func (s *service) markdownToHTML(mdContent []byte) string {
-	// Configure markdown parser
-	extensions := parser.CommonExtensions | parser.AutoHeadingIDs | parser.NoEmptyLineBeforeBlock
-	p := parser.NewWithExtensions(extensions)
-	
-	// Configure HTML renderer with syntax highlighting
-	htmlFlags := mdhtml.CommonFlags | mdhtml.HrefTargetBlank
-	opts := mdhtml.RendererOptions{
-		Flags: htmlFlags,
-		RenderNodeHook: s.chromaRenderHook,
-	}
-	renderer := mdhtml.NewRenderer(opts)
-	
-	// Convert markdown to HTML
-	return string(markdown.ToHTML(mdContent, p, renderer))
-}
-
-// chromaRenderHook provides syntax highlighting for code blocks
-func (s *service) chromaRenderHook(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
-	if code, ok := node.(*ast.CodeBlock); ok && entering {
-		// Get the language from the code block info
-		language := ""
-		if code.Info != nil {
-			language = string(code.Info)
-		}
-		
-		// Handle Mermaid diagrams (client-side rendering for now)
-		if language == "mermaid" {
-			w.Write([]byte(`<div class="mermaid-container mermaid-csr"><div class="mermaid">`))
-			w.Write(code.Literal)
-			w.Write([]byte("</div></div>"))
-			return ast.GoToNext, true
-		}
-		
-		// Get lexer for the language
-		lexer := lexers.Get(language)
-		if lexer == nil {
-			lexer = lexers.Fallback
-		}
-		
-		// Configure formatter with cyberpunk theme
-		formatter := html.New(html.WithClasses(true), html.TabWidth(2))
-		style := styles.Get("monokai")
-		if style == nil {
-			style = styles.Fallback
-		}
-		
-		// Create iterator from code content
-		iterator, err := lexer.Tokenise(nil, string(code.Literal))
-		if err != nil {
-			// Fallback to plain text
-			w.Write([]byte("<pre><code>"))
-			w.Write(code.Literal)
-			w.Write([]byte("</code></pre>"))
-			return ast.GoToNext, true
-		}
-		
-		// Format the code
-		err = formatter.Format(w, style, iterator)
-		if err != nil {
-			// Fallback to plain text
-			w.Write([]byte("<pre><code>"))
-			w.Write(code.Literal)
-			w.Write([]byte("</code></pre>"))
-		}
-		
-		return ast.GoToNext, true
-	}
-	
-	return ast.GoToNext, false
-}
-
 // calculateReadingTime calculates reading time based on word count
 func (s *service) calculateReadingTime(text string) int {
 	// Average reading speed: 200 words per minute
@@ -396,35 +657,23 @@ func (s *service) calculateReadingTime(text string) int {
 	return minutes
 }
 
-// containsTag checks if tags contain a query (case-insensitive)
-func (s *service) containsTag(tags []string, query string) bool {
-	for _, tag := range tags {
-		if strings.Contains(strings.ToLower(tag), query) {
-			return true
-		}
-	}
-	return false
-}
-
 // loadBlogConfig loads the blog configuration from blog.yml
-func (s *service) loadBlogConfig() {
-	configPath := "content/blog.yml"
+func (s *service) loadBlogConfig() *BlogConfig {
+	configPath := blogConfigPath
 	configData, err := fs.ReadFile(s.blogFS, configPath)
 	if err != nil {
 		s.logger.Printf("BLOG: Warning - could not load blog config from %s: %v, using defaults", configPath, err)
-		s.blogConfig = s.getDefaultBlogConfig()
-		return
+		return s.getDefaultBlogConfig()
 	}
 
 	var config BlogConfig
 	if err := yaml.Unmarshal(configData, &config); err != nil {
 		s.logger.Printf("BLOG: Warning - could not parse blog config: %v, using defaults", err)
-		s.blogConfig = s.getDefaultBlogConfig()
-		return
+		return s.getDefaultBlogConfig()
 	}
 
-	s.blogConfig = &config
 	s.logger.Printf("BLOG: Loaded blog configuration with %d tag filters", len(config.Blog.TagFilters))
+	return &config
 }
 
 // getDefaultBlogConfig returns default blog configuration
@@ -435,6 +684,7 @@ func (s *service) getDefaultBlogConfig() *BlogConfig {
 			Subtitle   string       `yaml:"subtitle"`
 			TagFilters []TagFilter  `yaml:"tag_filters"`
 			Search     SearchConfig `yaml:"search"`
+			Code       CodeConfig   `yaml:"code"`
 		}{
 			Title:    "Technical Insights",
 			Subtitle: "Deep dives into blockchain, AI, and production engineering.",
@@ -454,7 +704,7 @@ func (s *service) getDefaultBlogConfig() *BlogConfig {
 
 // GetBlogConfig returns the blog configuration
 func (s *service) GetBlogConfig() *BlogConfig {
-	return s.blogConfig
+	return s.current().config
 }
 
 // Ensure service implements required interfaces
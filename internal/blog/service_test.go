@@ -2,14 +2,22 @@ package blog
 
 import (
 	"context"
+	"fmt"
 	"embed"
+	"io"
 	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"blockhead.consulting/internal/events"
+	"blockhead.consulting/internal/history"
+	"blockhead.consulting/internal/i18n"
+	"blockhead.consulting/internal/render"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -72,7 +80,7 @@ func TestServiceLifecycle(t *testing.T) {
 	// Test Start
 	err := svc.Start(ctx)
 	assert.NoError(t, err)
-	assert.Len(t, svc.posts, 2) // We have 2 test posts
+	assert.Len(t, svc.current().posts, 2) // We have 2 test posts
 	assert.Len(t, mockBus.publishedEvents, 1) // Should publish BlogInitialized event
 	
 	// Test Health
@@ -125,6 +133,69 @@ func TestGetBySlug(t *testing.T) {
 	assert.Nil(t, post)
 }
 
+func TestPostMetadata(t *testing.T) {
+	svc, _ := createTestService(t)
+	ctx := context.Background()
+	require.NoError(t, svc.Start(ctx))
+	
+	post, err := svc.GetBySlug(ctx, "second-post")
+	require.NoError(t, err)
+	assert.Equal(t, "Planning scalable AI systems", post.Description)
+	assert.Equal(t, "https://cdn.example.com/ai.png", post.Image)
+	assert.Equal(t, "https://example.com/ai-systems", post.Canonical)
+	assert.Equal(t, []Author{{ID: "guest-writer", Name: "Guest Writer"}}, post.Authors)
+	
+	// Metadata is optional
+	post, err = svc.GetBySlug(ctx, "first-post")
+	require.NoError(t, err)
+	assert.Empty(t, post.Description)
+	assert.Empty(t, post.Image)
+}
+
+// fakeHistory returns the same history for every file
+type fakeHistory struct {
+	file    history.File
+	lookups []string
+}
+
+func (f *fakeHistory) Lookup(ctx context.Context, file string) history.File {
+	f.lookups = append(f.lookups, file)
+	return f.file
+}
+
+func TestPostHistory(t *testing.T) {
+	created := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	updated := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	provider := &fakeHistory{file: history.File{
+		Created: created,
+		Updated: updated,
+		Revisions: []history.Revision{
+			{Hash: "b2", Date: updated, Subject: "Update benchmarks"},
+			{Hash: "a1", Date: created, Subject: "Add post"},
+		},
+	}}
+	
+	testDataFS, err := fs.Sub(testFS, "testdata")
+	require.NoError(t, err)
+	svc := NewServiceWithOptions(testDataFS, ".", log.New(io.Discard, "", 0), nil, WithHistory(provider))
+	ctx := context.Background()
+	require.NoError(t, svc.Start(ctx))
+	
+	post, err := svc.GetBySlug(ctx, "first-post")
+	require.NoError(t, err)
+	assert.Contains(t, provider.lookups, "first-post.md")
+	assert.Equal(t, created, post.Created)
+	assert.Equal(t, updated, post.Updated)
+	assert.Len(t, post.Revisions, 2)
+	assert.True(t, post.WasUpdated())
+	assert.Equal(t, updated, post.LastModified())
+	
+	// A single commit on the day of publication isn't an update
+	post.Revisions = post.Revisions[1:]
+	assert.False(t, post.WasUpdated())
+	assert.Equal(t, post.Date, post.LastModified())
+}
+
 func TestSearch(t *testing.T) {
 	svc, _ := createTestService(t)
 	ctx := context.Background()
@@ -202,16 +273,16 @@ func TestLoadPosts(t *testing.T) {
 	ctx := context.Background()
 	err := svc.LoadPosts(ctx)
 	assert.NoError(t, err)
-	assert.Len(t, svc.posts, 2)
+	assert.Len(t, svc.current().posts, 2)
 	
 	// Verify post details
-	firstPost := svc.postMap["first-post"]
+	firstPost := svc.current().postMap["first-post"]
 	assert.NotNil(t, firstPost)
 	assert.Equal(t, "First Test Post", firstPost.Title)
 	assert.Equal(t, []string{"blockchain", "consulting"}, firstPost.Tags)
 	assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), firstPost.Date)
 	
-	secondPost := svc.postMap["second-post"]
+	secondPost := svc.current().postMap["second-post"]
 	assert.NotNil(t, secondPost)
 	assert.Equal(t, "Second Test Post", secondPost.Title)
 	assert.Equal(t, []string{"ai", "llm"}, secondPost.Tags)
@@ -226,9 +297,6 @@ func TestEmptyBlogFS(t *testing.T) {
 	emptyFS := embed.FS{}
 	
 	svc := &service{
-		posts:    []Post{},
-		postMap:  make(map[string]*Post),
-		tagIndex: make(map[string][]int),
 		blogFS:   emptyFS,
 		blogDir:  ".",
 		logger:   logger,
@@ -238,11 +306,592 @@ func TestEmptyBlogFS(t *testing.T) {
 	ctx := context.Background()
 	err := svc.Start(ctx)
 	assert.NoError(t, err)
-	assert.Len(t, svc.posts, 0)
+	assert.Len(t, svc.current().posts, 0)
 }
 
 func TestInvalidMarkdownFile(t *testing.T) {
 	// This test would require a specific test file with invalid frontmatter
 	// For now, we'll skip this as it would require modifying the testdata
 	t.Skip("Requires specific invalid markdown test file")
-}
\ No newline at end of file
+}
+
+func createScheduleTestService(t *testing.T, now time.Time) *service {
+	logger := log.New(os.Stdout, "[blog-test] ", log.LstdFlags)
+	postFS := fstest.MapFS{
+		"live.md": {Data: []byte("---\ntitle: \"Live\"\ndate: 2024-01-10\ntags: [\"go\"]\n---\nLive post\n")},
+		"draft.md": {Data: []byte("---\ntitle: \"Draft\"\ndate: 2024-01-11\ntags: [\"go\", \"drafts\"]\ndraft: true\n---\nDraft post\n")},
+		"scheduled.md": {Data: []byte("---\ntitle: \"Scheduled\"\ndate: 2024-01-12\ntags: [\"go\"]\npublishAt: 2024-02-01T09:00:00Z\n---\nScheduled post\n")},
+	}
+	
+	svc := NewServiceWithOptions(postFS, ".", logger, &mockEventBus{})
+	svc.now = func() time.Time { return now }
+	require.NoError(t, svc.LoadPosts(context.Background()))
+	return svc
+}
+
+func TestUnpublishedPostsAreHidden(t *testing.T) {
+	ctx := context.Background()
+	svc := createScheduleTestService(t, time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC))
+	
+	posts := svc.GetAll(ctx)
+	require.Len(t, posts, 1)
+	assert.Equal(t, "live", posts[0].Slug)
+	
+	_, err := svc.GetBySlug(ctx, "draft")
+	assert.Error(t, err)
+	_, err = svc.GetBySlug(ctx, "scheduled")
+	assert.Error(t, err)
+	
+	assert.Len(t, svc.GetByTag(ctx, "go"), 1)
+	assert.Empty(t, svc.GetByTag(ctx, "drafts"))
+	assert.NotContains(t, svc.GetTags(ctx), "drafts")
+	assert.Empty(t, svc.Search(ctx, "Draft"))
+	
+	// Previews can still see unpublished posts
+	draft, err := svc.GetPreview(ctx, "draft")
+	require.NoError(t, err)
+	assert.True(t, draft.Draft)
+}
+
+func TestScheduledPostGoesLive(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 31, 23, 59, 0, 0, time.UTC)
+	svc := createScheduleTestService(t, now)
+	
+	assert.Len(t, svc.GetAll(ctx), 1)
+	
+	// No reload needed once the publish time passes
+	svc.now = func() time.Time { return now.Add(24 * time.Hour) }
+	posts := svc.GetAll(ctx)
+	require.Len(t, posts, 2)
+	assert.Equal(t, "scheduled", posts[0].Slug)
+	
+	post, err := svc.GetBySlug(ctx, "scheduled")
+	require.NoError(t, err)
+	assert.Equal(t, "Scheduled", post.Title)
+}
+
+func TestSearchResults(t *testing.T) {
+	svc, _ := createTestService(t)
+	ctx := context.Background()
+	
+	err := svc.Start(ctx)
+	require.NoError(t, err)
+	
+	// Body text is searched and highlighted
+	results := svc.SearchResults(ctx, "careful planning")
+	require.Len(t, results, 1)
+	assert.Equal(t, "second-post", results[0].Post.Slug)
+	assert.Contains(t, string(results[0].Snippet), "<mark>careful</mark> <mark>planning</mark>")
+	
+	// Query operators filter results
+	results = svc.SearchResults(ctx, "test tag:llm")
+	require.Len(t, results, 1)
+	assert.Equal(t, "second-post", results[0].Post.Slug)
+	
+	results = svc.SearchResults(ctx, "test before:2024-01-16")
+	require.Len(t, results, 1)
+	assert.Equal(t, "first-post", results[0].Post.Slug)
+	
+	// An empty query lists every post without excerpts
+	results = svc.SearchResults(ctx, "")
+	assert.Len(t, results, 2)
+	assert.Empty(t, results[0].Snippet)
+}
+
+func TestCanonicalTags(t *testing.T) {
+	svc, _ := createTestService(t)
+	config := *svc.GetBlogConfig()
+	config.Blog.TagFilters = []TagFilter{
+		{Display: "All", Tag: "all"},
+		{Display: "AI/ML", Tag: "ai", Aliases: []string{"AI", "llm"}},
+	}
+	svc.snap.Store(newSnapshot(&config))
+	ctx := context.Background()
+	
+	require.NoError(t, svc.Start(ctx))
+	
+	assert.Equal(t, "smart-contract-security", TagSlug("Smart Contract Security"))
+	assert.Equal(t, "r-d", TagSlug("r&d"))
+	assert.Equal(t, "c-plus-plus", TagSlug("C++"))
+	assert.Equal(t, "c-sharp", TagSlug("C#"))
+	assert.Equal(t, "c", TagSlug("C"))
+	
+	// Aliases and the display name resolve to the canonical tag
+	for _, tag := range []string{"ai", "AI", "AI/ML", "llm", "LLM"} {
+		assert.Equal(t, "ai", svc.CanonicalTag(tag), tag)
+	}
+	assert.Equal(t, "blockchain", svc.CanonicalTag("Blockchain"))
+	assert.Equal(t, "AI/ML", svc.TagName("llm"))
+	
+	// second-post is tagged both "ai" and "llm", and is listed once
+	posts := svc.GetByTag(ctx, "llm")
+	require.Len(t, posts, 1)
+	assert.Equal(t, "second-post", posts[0].Slug)
+	assert.Equal(t, []string{"ai", "blockchain", "consulting"}, svc.GetTags(ctx))
+	
+	results := svc.SearchResults(ctx, "tag:AI/ML")
+	require.Len(t, results, 1)
+	assert.Equal(t, "second-post", results[0].Post.Slug)
+}
+
+func TestGetByDate(t *testing.T) {
+	svc, _ := createTestService(t)
+	ctx := context.Background()
+	
+	require.NoError(t, svc.Start(ctx))
+	
+	assert.Len(t, svc.GetByDate(ctx, 2024, 0), 2)
+	assert.Len(t, svc.GetByDate(ctx, 2024, time.January), 2)
+	assert.Empty(t, svc.GetByDate(ctx, 2024, time.February))
+	assert.Empty(t, svc.GetByDate(ctx, 2023, 0))
+}
+
+func TestPaginate(t *testing.T) {
+	posts := make([]Post, 7)
+	for i := range posts {
+		posts[i].Slug = fmt.Sprintf("post-%d", i)
+	}
+	
+	page, pagination, ok := Paginate(posts, 1, 3)
+	require.True(t, ok)
+	assert.Len(t, page, 3)
+	assert.Equal(t, 3, pagination.TotalPages)
+	assert.False(t, pagination.HasPrev())
+	assert.True(t, pagination.HasNext())
+	
+	page, pagination, ok = Paginate(posts, 3, 3)
+	require.True(t, ok)
+	assert.Equal(t, []Post{posts[6]}, page)
+	assert.True(t, pagination.HasPrev())
+	assert.False(t, pagination.HasNext())
+	
+	_, _, ok = Paginate(posts, 4, 3)
+	assert.False(t, ok)
+	_, _, ok = Paginate(posts, 0, 3)
+	assert.False(t, ok)
+	
+	// An empty listing still has a first page
+	page, pagination, ok = Paginate(nil, 1, 3)
+	assert.True(t, ok)
+	assert.Empty(t, page)
+	assert.Equal(t, 1, pagination.TotalPages)
+}
+
+func TestRelatedTo(t *testing.T) {
+	logger := log.New(os.Stdout, "[blog-test] ", log.LstdFlags)
+	postFS := fstest.MapFS{
+		"go-channels.md": {Data: []byte("---\ntitle: \"Go Channels\"\ndate: 2024-01-10\ntags: [\"go\", \"concurrency\"]\n---\nChannels and goroutines make concurrency in Go pleasant.\n")},
+		"go-mutexes.md":  {Data: []byte("---\ntitle: \"Go Mutexes\"\ndate: 2024-01-11\ntags: [\"go\", \"concurrency\"]\n---\nMutexes guard shared state between goroutines.\n")},
+		"go-modules.md":  {Data: []byte("---\ntitle: \"Go Modules\"\ndate: 2024-01-12\ntags: [\"go\"]\nrelatedExclude: [\"go-channels\"]\n---\nModules version dependencies.\n")},
+		"wallets.md":     {Data: []byte("---\ntitle: \"Wallets\"\ndate: 2024-01-13\ntags: [\"crypto\"]\nrelated: [\"go-modules\"]\n---\nCustodial wallets hold keys.\n")},
+		"draft.md":       {Data: []byte("---\ntitle: \"Draft\"\ndate: 2024-01-14\ntags: [\"go\", \"concurrency\"]\ndraft: true\n---\nChannels and goroutines again.\n")},
+	}
+	
+	svc := NewServiceWithOptions(postFS, ".", logger, &mockEventBus{})
+	ctx := context.Background()
+	require.NoError(t, svc.LoadPosts(ctx))
+	
+	slugs := func(posts []Post) []string {
+		var result []string
+		for _, post := range posts {
+			result = append(result, post.Slug)
+		}
+		return result
+	}
+	
+	// Shared tags and text rank highest, drafts are never suggested
+	assert.Equal(t, []string{"go-mutexes", "go-modules"}, slugs(svc.RelatedTo(ctx, "go-channels", 3)))
+	assert.Equal(t, []string{"go-mutexes"}, slugs(svc.RelatedTo(ctx, "go-channels", 1)))
+	
+	// Exclusions apply to the post that declares them
+	assert.NotContains(t, slugs(svc.RelatedTo(ctx, "go-modules", 3)), "go-channels")
+	
+	// Pinned posts come first even without anything in common
+	assert.Equal(t, []string{"go-modules"}, slugs(svc.RelatedTo(ctx, "wallets", 3)))
+	
+	assert.Empty(t, svc.RelatedTo(ctx, "missing", 3))
+}
+
+func TestGetSeries(t *testing.T) {
+	logger := log.New(os.Stdout, "[blog-test] ", log.LstdFlags)
+	postFS := fstest.MapFS{
+		"part-two.md":   {Data: []byte("---\ntitle: \"Part Two\"\ndate: 2024-01-10\nseries: \"Trading Bots\"\nseriesOrder: 2\n---\nTwo\n")},
+		"part-one.md":   {Data: []byte("---\ntitle: \"Part One\"\ndate: 2024-01-12\nseries: \"Trading Bots\"\nseriesOrder: 1\n---\nOne\n")},
+		"appendix.md":   {Data: []byte("---\ntitle: \"Appendix\"\ndate: 2024-01-01\nseries: \"trading bots\"\n---\nExtra\n")},
+		"part-three.md": {Data: []byte("---\ntitle: \"Part Three\"\ndate: 2024-01-14\nseries: \"Trading Bots\"\nseriesOrder: 3\ndraft: true\n---\nThree\n")},
+		"standalone.md": {Data: []byte("---\ntitle: \"Standalone\"\ndate: 2024-01-15\n---\nAlone\n")},
+	}
+	
+	svc := NewServiceWithOptions(postFS, ".", logger, &mockEventBus{})
+	ctx := context.Background()
+	require.NoError(t, svc.LoadPosts(ctx))
+	
+	// Looked up by name or slug; unordered parts go last and drafts are skipped
+	series, err := svc.GetSeries(ctx, "trading-bots")
+	require.NoError(t, err)
+	assert.Equal(t, "trading-bots", series.Slug)
+	require.Len(t, series.Posts, 3)
+	assert.Equal(t, "part-one", series.Posts[0].Slug)
+	assert.Equal(t, "part-two", series.Posts[1].Slug)
+	assert.Equal(t, "appendix", series.Posts[2].Slug)
+	assert.Equal(t, "Trading Bots", series.Name)
+	
+	pos, ok := series.Position("part-two")
+	require.True(t, ok)
+	assert.Equal(t, 2, pos.Part)
+	assert.Equal(t, 3, pos.Total)
+	assert.Equal(t, "part-one", pos.Prev.Slug)
+	assert.Equal(t, "appendix", pos.Next.Slug)
+	
+	pos, ok = series.Position("part-one")
+	require.True(t, ok)
+	assert.Nil(t, pos.Prev)
+	
+	_, ok = series.Position("part-three")
+	assert.False(t, ok)
+	
+	_, err = svc.GetSeries(ctx, "no-such-series")
+	assert.Error(t, err)
+	
+	assert.Len(t, svc.GetAllSeries(ctx), 1)
+}
+
+func TestAuthors(t *testing.T) {
+	logger := log.New(os.Stdout, "[blog-test] ", log.LstdFlags)
+	postFS := fstest.MapFS{
+		"content/authors.yml": {Data: []byte("authors:\n  - id: ada\n    name: Ada Lovelace\n    bio: Writes about engines\n  - id: Grace\n    name: Grace Hopper\n    links:\n      - label: Website\n        url: https://example.com/grace\n")},
+		"content/blog/default.md":  {Data: []byte("---\ntitle: \"Default\"\ndate: 2024-01-10\n---\nBy the default author\n")},
+		"content/blog/shared.md":   {Data: []byte("---\ntitle: \"Shared\"\ndate: 2024-01-11\nauthors: [\"grace\", \"ada\"]\n---\nCo-authored\n")},
+		"content/blog/guest.md":    {Data: []byte("---\ntitle: \"Guest\"\ndate: 2024-01-12\nauthor: \"Sam Guest\"\n---\nA guest post\n")},
+		"content/blog/draft.md":    {Data: []byte("---\ntitle: \"Draft\"\ndate: 2024-01-13\nauthors: [\"ada\"]\ndraft: true\n---\nUnfinished\n")},
+	}
+	
+	svc := NewServiceWithOptions(postFS, "content/blog", logger, &mockEventBus{})
+	ctx := context.Background()
+	require.NoError(t, svc.LoadPosts(ctx))
+	
+	slugs := func(posts []Post) []string {
+		var result []string
+		for _, post := range posts {
+			result = append(result, post.Slug)
+		}
+		return result
+	}
+	
+	// Posts that name no author are credited to the first one
+	post, err := svc.GetBySlug(ctx, "default")
+	require.NoError(t, err)
+	require.Len(t, post.Authors, 1)
+	assert.Equal(t, "ada", post.Authors[0].ID)
+	assert.True(t, post.Authors[0].Registered)
+	
+	// IDs resolve to registered authors in the order written
+	post, err = svc.GetBySlug(ctx, "shared")
+	require.NoError(t, err)
+	require.Len(t, post.Authors, 2)
+	assert.Equal(t, "Grace Hopper", post.Authors[0].Name)
+	assert.Equal(t, "https://example.com/grace", post.Authors[0].Links[0].URL)
+	assert.Equal(t, "Ada Lovelace", post.Authors[1].Name)
+	
+	// Unregistered names are credited as written
+	post, err = svc.GetBySlug(ctx, "guest")
+	require.NoError(t, err)
+	assert.Equal(t, []Author{{ID: "sam-guest", Name: "Sam Guest"}}, post.Authors)
+	
+	// Listings skip drafts
+	assert.Equal(t, []string{"shared", "default"}, slugs(svc.GetByAuthor(ctx, "ada")))
+	assert.Equal(t, []string{"shared"}, slugs(svc.GetByAuthor(ctx, "grace")))
+	assert.Equal(t, []string{"guest"}, slugs(svc.GetByAuthor(ctx, "sam-guest")))
+	
+	author, err := svc.GetAuthor(ctx, "grace")
+	require.NoError(t, err)
+	assert.Equal(t, "Grace Hopper", author.Name)
+	author, err = svc.GetAuthor(ctx, "Grace Hopper")
+	require.NoError(t, err)
+	assert.Equal(t, "grace", author.ID, "registered authors can be named in full")
+	_, err = svc.GetAuthor(ctx, "sam-guest")
+	assert.Error(t, err, "only registered authors have pages")
+	
+	authors := svc.GetAuthors(ctx)
+	require.Len(t, authors, 2)
+	assert.Equal(t, "ada", authors[0].ID)
+}
+
+func TestLocales(t *testing.T) {
+	logger := log.New(os.Stdout, "[blog-test] ", log.LstdFlags)
+	postFS := fstest.MapFS{
+		"wallets.md":    {Data: []byte("---\ntitle: \"Wallets\"\ndate: 2024-01-10\ntags: [\"crypto\"]\n---\nCustodial wallets hold keys.\n")},
+		"wallets.es.md": {Data: []byte("---\ntitle: \"Carteras\"\n---\nLas carteras custodias guardan claves.\n")},
+		"bots.md":       {Data: []byte("---\ntitle: \"Bots\"\ndate: 2024-01-12\ntags: [\"crypto\"]\n---\nTrading bots.\n")},
+		"solo.es.md":    {Data: []byte("---\ntitle: \"Solo\"\ndate: 2024-01-14\n---\nSolo en español.\n")},
+		"v1.2.md":       {Data: []byte("---\ntitle: \"Dotted\"\ndate: 2024-01-01\n---\nA slug with a dot.\n")},
+		"secret.md":     {Data: []byte("---\ntitle: \"Secret\"\ndate: 2024-01-16\ndraft: true\n---\nNot yet.\n")},
+		"secret.es.md":  {Data: []byte("---\ntitle: \"Secreto\"\n---\nTodavía no.\n")},
+		"later.md":      {Data: []byte("---\ntitle: \"Later\"\ndate: 2024-01-18\npublishAt: 2999-01-01T00:00:00Z\n---\nScheduled.\n")},
+		"later.es.md":   {Data: []byte("---\ntitle: \"Luego\"\n---\nProgramado.\n")},
+	}
+	
+	svc := NewServiceWithOptions(postFS, ".", logger, &mockEventBus{}, WithLocales("en", "es"))
+	ctx := context.Background()
+	es := i18n.WithLocale(ctx, "es")
+	require.NoError(t, svc.LoadPosts(ctx))
+	
+	slugs := func(posts []Post) []string {
+		var result []string
+		for _, post := range posts {
+			result = append(result, post.Slug)
+		}
+		return result
+	}
+	
+	// The default locale never sees translations
+	assert.Equal(t, []string{"bots", "wallets", "v1.2"}, slugs(svc.GetAll(ctx)))
+	post, err := svc.GetBySlug(ctx, "wallets")
+	require.NoError(t, err)
+	assert.Equal(t, "Wallets", post.Title)
+	assert.Equal(t, "en", post.Lang)
+	assert.Equal(t, []string{"es"}, post.Translations)
+	
+	// Other locales see translations where there are any, originals otherwise
+	assert.Equal(t, []string{"solo", "bots", "wallets", "v1.2"}, slugs(svc.GetAll(es)))
+	post, err = svc.GetBySlug(es, "wallets")
+	require.NoError(t, err)
+	assert.Equal(t, "Carteras", post.Title)
+	assert.Equal(t, "es", post.Lang)
+	assert.Equal(t, []string{"en"}, post.Translations)
+	assert.Equal(t, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), post.Date, "translations inherit the date")
+	assert.Equal(t, []string{"bots", "wallets"}, slugs(svc.GetByTag(es, "crypto")), "and the tags")
+	
+	// Translations aren't published before their originals
+	for _, slug := range []string{"secret", "later"} {
+		_, err = svc.GetBySlug(es, slug)
+		assert.Error(t, err, slug)
+	}
+	
+	post, err = svc.GetBySlug(es, "bots")
+	require.NoError(t, err)
+	assert.Equal(t, "en", post.Lang)
+	
+	_, err = svc.GetBySlug(ctx, "solo")
+	assert.Error(t, err, "posts only written in another locale aren't in the default one")
+	
+	assert.Equal(t, []string{"wallets"}, slugs(svc.Search(es, "carteras")))
+	assert.Empty(t, svc.Search(ctx, "carteras"))
+	
+	// Unknown locales read the default posts
+	assert.Len(t, svc.GetAll(i18n.WithLocale(ctx, "fr")), 3)
+}
+
+func TestTableOfContents(t *testing.T) {
+	logger := log.New(os.Stdout, "[blog-test] ", log.LstdFlags)
+	body := "## Setup\n\nText\n\n### Install `ccxt`\n\nText\n\n## Usage\n\nText\n\n## Usage\n\nAgain\n"
+	postFS := fstest.MapFS{
+		"auto.md":   {Data: []byte("---\ntitle: \"Auto\"\ndate: 2024-01-10\n---\n" + body)},
+		"marker.md": {Data: []byte("---\ntitle: \"Marker\"\ndate: 2024-01-11\n---\nIntro\n\n[[toc]]\n\n" + body)},
+		"off.md":    {Data: []byte("---\ntitle: \"Off\"\ndate: 2024-01-12\ntoc: false\n---\n[[toc]]\n\n" + body)},
+		"short.md":  {Data: []byte("---\ntitle: \"Short\"\ndate: 2024-01-13\n---\n## Only\n\nOne heading\n")},
+	}
+	
+	svc := NewServiceWithOptions(postFS, ".", logger, &mockEventBus{})
+	ctx := context.Background()
+	require.NoError(t, svc.LoadPosts(ctx))
+	
+	post, err := svc.GetBySlug(ctx, "auto")
+	require.NoError(t, err)
+	require.Len(t, post.TOC, 3)
+	assert.Equal(t, TOCEntry{Level: 2, ID: "setup", Title: "Setup", Children: []TOCEntry{
+		{Level: 3, ID: "install-ccxt", Title: "Install ccxt"},
+	}}, post.TOC[0])
+	
+	// Repeated headings get distinct anchors, matching the rendered IDs
+	assert.Equal(t, "usage", post.TOC[1].ID)
+	assert.Equal(t, "usage-1", post.TOC[2].ID)
+	assert.Contains(t, string(post.Content), `<h2 id="usage-1">Usage <a class="heading-anchor" href="#usage-1"`)
+	assert.Contains(t, string(post.TOCHTML()), `<a href="#install-ccxt">Install ccxt</a>`)
+	
+	// A marker renders the TOC in place instead of above the post
+	post, err = svc.GetBySlug(ctx, "marker")
+	require.NoError(t, err)
+	assert.True(t, post.TOCPlaced)
+	assert.Empty(t, post.TOCHTML())
+	assert.Contains(t, string(post.Content), "<p>Intro</p>\n<nav class=\"toc\"")
+	assert.NotContains(t, string(post.Content), "[[toc]]")
+	
+	// Opting out drops both the TOC and the marker
+	post, err = svc.GetBySlug(ctx, "off")
+	require.NoError(t, err)
+	assert.Empty(t, post.TOC)
+	assert.Empty(t, post.TOCHTML())
+	assert.NotContains(t, string(post.Content), "toc")
+	
+	// Short posts don't get an automatic TOC
+	post, err = svc.GetBySlug(ctx, "short")
+	require.NoError(t, err)
+	assert.Len(t, post.TOC, 1)
+	assert.Empty(t, post.TOCHTML())
+}
+
+func writeTestPost(t *testing.T, root, slug, title string, modTime time.Time) {
+	path := filepath.Join(root, "content", "blog", slug+".md")
+	body := fmt.Sprintf("---\ntitle: %q\ndate: 2024-03-01\ntags: [\"go\"]\n---\n%s\n", title, title)
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestContentDirReload(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "content", "blog"), 0o755))
+	
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	writeTestPost(t, root, "kept", "Kept", modTime)
+	writeTestPost(t, root, "edited", "Original", modTime)
+	writeTestPost(t, root, "removed", "Removed", modTime)
+	
+	logger := log.New(os.Stdout, "[blog-test] ", log.LstdFlags)
+	mockBus := &mockEventBus{}
+	svc := NewServiceWithOptions(testFS, "content/blog", logger, mockBus, WithContentDir(root), WithWatchInterval(0), WithLocales("en", "es"))
+	require.NoError(t, svc.LoadPosts(ctx))
+	assert.Len(t, svc.GetAll(ctx), 3)
+	
+	stamps, err := svc.stampContent()
+	require.NoError(t, err)
+	
+	// Nothing changed, nothing reloaded
+	mockBus.publishedEvents = nil
+	stamps = svc.reloadIfChanged(ctx, stamps)
+	assert.Empty(t, mockBus.publishedEvents)
+	
+	writeTestPost(t, root, "edited", "Edited", modTime.Add(time.Minute))
+	writeTestPost(t, root, "added", "Added", modTime)
+	writeTestPost(t, root, "kept.es", "Guardado", modTime)
+	require.NoError(t, os.Remove(filepath.Join(root, "content", "blog", "removed.md")))
+	
+	stamps = svc.reloadIfChanged(ctx, stamps)
+	
+	post, err := svc.GetBySlug(ctx, "edited")
+	require.NoError(t, err)
+	assert.Equal(t, "Edited", post.Title)
+	_, err = svc.GetBySlug(ctx, "removed")
+	assert.Error(t, err)
+	_, err = svc.GetBySlug(ctx, "added")
+	assert.NoError(t, err)
+	
+	changes := map[events.EventType][]string{}
+	for _, event := range mockBus.publishedEvents {
+		if data, ok := event.Data().(map[string]interface{}); ok && data["slug"] != nil {
+			changes[event.Type()] = append(changes[event.Type()], data["slug"].(string)+"/"+data["locale"].(string))
+		}
+	}
+	// Translations are reported with the slug of their original
+	assert.Equal(t, []string{"added/en", "edited/en", "kept/es"}, changes[events.EventBlogUpdated])
+	assert.Equal(t, []string{"removed/en"}, changes[events.EventBlogDeleted])
+	
+	// The new stamps are the baseline for the next poll
+	mockBus.publishedEvents = nil
+	svc.reloadIfChanged(ctx, stamps)
+	assert.Empty(t, mockBus.publishedEvents)
+}
+
+func TestContentDirFallsBackToEmbed(t *testing.T) {
+	logger := log.New(os.Stdout, "[blog-test] ", log.LstdFlags)
+	
+	svc := NewServiceWithOptions(testFS, "testdata", logger, &mockEventBus{}, WithContentDir(t.TempDir()))
+	require.NoError(t, svc.Start(context.Background()))
+	defer svc.Stop(context.Background())
+	
+	assert.Empty(t, svc.contentDir)
+	assert.Nil(t, svc.stopWatch, "embedded content is never watched")
+	assert.Len(t, svc.GetAll(context.Background()), 2)
+}
+
+func TestSnapshotReloads(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New(io.Discard, "", 0)
+	postFS := fstest.MapFS{
+		"good.md":   {Data: []byte("---\ntitle: \"Good\"\ndate: 2024-01-10\ntags: [\"go\"]\n---\nGood post\n")},
+		"broken.md": {Data: []byte("---\ntitle: [unclosed\n---\nBroken post\n")},
+	}
+	
+	svc := NewServiceWithOptions(postFS, ".", logger, nil)
+	assert.EqualError(t, svc.Health(ctx), "no blog posts loaded")
+	
+	require.NoError(t, svc.LoadPosts(ctx))
+	status := svc.Status()
+	assert.Equal(t, uint64(1), status.Generation)
+	assert.Equal(t, 1, status.Posts)
+	require.Len(t, status.Errors, 1)
+	assert.Contains(t, status.Errors[0], "broken.md")
+	assert.NoError(t, svc.Health(ctx), "a broken post degrades the blog, it isn't down")
+	
+	// Readers keep working, and see whole snapshots, while posts reload
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				posts := svc.GetAll(ctx)
+				assert.Len(t, posts, 1)
+				assert.Len(t, svc.GetByTag(ctx, "go"), len(posts))
+				_, err := svc.GetBySlug(ctx, "good")
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		require.NoError(t, svc.LoadPosts(ctx))
+	}
+	wg.Wait()
+	assert.Equal(t, uint64(11), svc.Status().Generation)
+	
+	delete(postFS, "broken.md")
+	require.NoError(t, svc.LoadPosts(ctx))
+	assert.NoError(t, svc.Health(ctx))
+	assert.Empty(t, svc.Status().Errors)
+}
+
+func TestPostImages(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New(io.Discard, "", 0)
+	postFS := fstest.MapFS{
+		"content/blog/pictured.md": {Data: []byte("---\ntitle: \"Pictured\"\ndate: 2024-01-10\n---\n![Diagram](diagram.gif)\n")},
+		"content/blog/diagram.gif": {Data: []byte("GIF89a\x02\x00\x01\x00\x80\x00\x00\x00\x00\x00\xff\xff\xff!\xf9\x04\x00\x00\x00\x00\x00,\x00\x00\x00\x00\x02\x00\x01\x00\x00\x02\x02D\x01\x00;")},
+		"content/blog/no-alt.md":   {Data: []byte("---\ntitle: \"No alt\"\ndate: 2024-01-11\n---\n![](diagram.gif)\n")},
+	}
+	
+	renderer := render.New(render.Options{Images: render.NewImages(render.ImageOptions{Logger: logger})})
+	svc := NewServiceWithOptions(postFS, "content/blog", logger, nil, WithRenderer(renderer))
+	require.NoError(t, svc.LoadPosts(ctx))
+	
+	post, err := svc.GetBySlug(ctx, "pictured")
+	require.NoError(t, err)
+	assert.Regexp(t, `<img src="/images/diagram-[0-9a-f]{12}\.gif" width="2" height="1" alt="Diagram" loading="lazy" decoding="async">`, string(post.Content))
+	
+	// A post with an image missing alt text isn't published
+	_, err = svc.GetBySlug(ctx, "no-alt")
+	assert.Error(t, err)
+	require.Len(t, svc.Status().Errors, 1)
+	assert.Contains(t, svc.Status().Errors[0], "no-alt.md")
+	assert.Contains(t, svc.Status().Errors[0], "has no alt text")
+}
+
+func TestYearSlugs(t *testing.T) {
+	ctx := context.Background()
+	postFS := fstest.MapFS{
+		"2024.md":      {Data: []byte("---\ntitle: \"Year\"\ndate: 2024-01-10\n---\nShadowed by the archive\n")},
+		"2024-plan.md": {Data: []byte("---\ntitle: \"Plan\"\ndate: 2024-01-11\n---\nNot a year\n")},
+	}
+	
+	svc := NewServiceWithOptions(postFS, ".", log.New(io.Discard, "", 0), nil)
+	require.NoError(t, svc.LoadPosts(ctx))
+	
+	// /blog/2024 is the archive of the year, so no post can have that slug
+	_, err := svc.GetBySlug(ctx, "2024")
+	assert.Error(t, err)
+	require.Len(t, svc.Status().Errors, 1)
+	assert.Contains(t, svc.Status().Errors[0], `slug "2024" is taken by the /blog/2024/ archive`)
+	_, err = svc.GetBySlug(ctx, "2024-plan")
+	assert.NoError(t, err)
+}
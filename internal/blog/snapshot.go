@@ -0,0 +1,86 @@
+package blog
+
+import (
+	"time"
+
+	"blockhead.consulting/internal/search"
+)
+
+// snapshot is one complete, immutable load of the blog. Loads build a new
+// snapshot on the side and swap it in whole, so readers never lock and
+// never see a half-built index. Nothing reachable from a published
+// snapshot may be modified.
+type snapshot struct {
+	generation  uint64    // number of successful loads, zero before the first
+	loadedAt    time.Time // when the posts were loaded
+	loadErrors  []string  // files skipped by the load, or why the last load failed
+	posts       []Post
+	postMap     map[string]*Post
+	tagIndex    map[string][]int  // canonical tag -> post indices
+	tagAliases  map[string]string // tag slug -> canonical tag
+	tagNames    map[string]string // canonical tag -> name as first written
+	searchIndex *search.Index
+	related     map[string][]string // slug -> related slugs, best first
+	config      *BlogConfig
+	authors     *AuthorsConfig
+
+	// Locales, see locales.go. The default locale's snapshot holds a view
+	// of every other locale, built from the same load.
+	lang  string               // locale of a view, empty for the default
+	views map[string]*snapshot // locale -> view
+}
+
+// LoadStatus describes the posts currently being served
+type LoadStatus struct {
+	Generation uint64    `json:"generation"`
+	LoadedAt   time.Time `json:"loaded_at"`
+	Posts      int       `json:"posts"`
+	Errors     []string  `json:"errors,omitempty"`
+}
+
+// newSnapshot returns an empty snapshot using the given configuration
+func newSnapshot(config *BlogConfig) *snapshot {
+	return &snapshot{
+		postMap:    make(map[string]*Post),
+		tagIndex:   make(map[string][]int),
+		tagAliases: canonicalTags(config),
+		tagNames:   make(map[string]string),
+		config:     config,
+	}
+}
+
+// current returns the snapshot being served
+func (s *service) current() *snapshot {
+	if snap := s.snap.Load(); snap != nil {
+		return snap
+	}
+	return newSnapshot(nil)
+}
+
+// canonicalTag returns the slug of the tag page a tag belongs to
+func (snap *snapshot) canonicalTag(tag string) string {
+	slug := TagSlug(tag)
+	if canonical, ok := snap.tagAliases[slug]; ok {
+		return canonical
+	}
+	return slug
+}
+
+// canonicalPostTags returns the canonical tags of a post
+func (snap *snapshot) canonicalPostTags(post Post) []string {
+	tags := make([]string, 0, len(post.Tags))
+	for _, tag := range post.Tags {
+		tags = append(tags, snap.canonicalTag(tag))
+	}
+	return tags
+}
+
+// status summarizes the snapshot for health reporting
+func (snap *snapshot) status() LoadStatus {
+	return LoadStatus{
+		Generation: snap.generation,
+		LoadedAt:   snap.loadedAt,
+		Posts:      len(snap.posts),
+		Errors:     snap.loadErrors,
+	}
+}
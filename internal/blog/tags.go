@@ -0,0 +1,99 @@
+package blog
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TagSlug turns a tag as written in frontmatter into its URL form, so that
+// "Smart Contract Security" becomes "smart-contract-security" and "AI/ML"
+// becomes "ai-ml"
+func TagSlug(tag string) string {
+	return slugify(tag)
+}
+
+// slugSymbols are spelled out when they follow a word, so names like C++
+// and C# don't share the slug of C
+var slugSymbols = map[rune]string{
+	'+': "plus",
+	'#': "sharp",
+}
+
+// slugify lowercases s and joins its runs of letters and digits with
+// dashes, spelling out the symbols of slugSymbols
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if word, ok := slugSymbols[r]; ok && b.Len() > 0 {
+			b.WriteByte('-')
+			b.WriteString(word)
+			dash = true
+			continue
+		}
+		dash = true
+	}
+
+	return b.String()
+}
+
+// canonicalTags maps tag slugs to the tag of the filter in blog.yml that
+// lists them, either as its tag or as one of its aliases
+func canonicalTags(config *BlogConfig) map[string]string {
+	canonical := make(map[string]string)
+	if config == nil {
+		return canonical
+	}
+
+	for _, filter := range config.Blog.TagFilters {
+		tag := TagSlug(filter.Tag)
+		if tag == "" || tag == "all" {
+			continue
+		}
+		canonical[tag] = tag
+		canonical[TagSlug(filter.Display)] = tag
+		for _, alias := range filter.Aliases {
+			canonical[TagSlug(alias)] = tag
+		}
+	}
+
+	return canonical
+}
+
+// CanonicalTag returns the slug of the tag page a tag belongs to
+func (s *service) CanonicalTag(tag string) string {
+	return s.current().canonicalTag(tag)
+}
+
+// TagName returns the display name of a canonical tag
+func (s *service) TagName(tag string) string {
+	snap := s.current()
+	tag = snap.canonicalTag(tag)
+	if snap.config != nil {
+		for _, filter := range snap.config.Blog.TagFilters {
+			if TagSlug(filter.Tag) == tag {
+				return filter.Display
+			}
+		}
+	}
+	if name, ok := snap.tagNames[tag]; ok {
+		return name
+	}
+	return tag
+}
+
+// KnowsTag reports whether a tag belongs to one of the tag filters in
+// blog.yml, by its tag, display name or an alias
+func (c *BlogConfig) KnowsTag(tag string) bool {
+	_, ok := canonicalTags(c)[TagSlug(tag)]
+	return ok
+}
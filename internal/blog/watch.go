@@ -0,0 +1,250 @@
+package blog
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"blockhead.consulting/internal/events"
+	"blockhead.consulting/internal/history"
+	"blockhead.consulting/internal/render"
+)
+
+// DefaultWatchInterval is how often an on-disk content directory is polled
+// for changes unless WithWatchInterval says otherwise
+const DefaultWatchInterval = 2 * time.Second
+
+// blogConfigPath is where blog.yml lives relative to the content root
+const blogConfigPath = "content/blog.yml"
+
+// Option configures optional behaviour of the blog service
+type Option func(*service)
+
+// WithContentDir serves posts from root on disk instead of the filesystem
+// passed to the constructor. root has the same layout as the embedded
+// content (root/content/blog/*.md and root/content/blog.yml). When root
+// does not contain the blog directory the service logs a warning and keeps
+// the original filesystem, so a missing checkout falls back to the embed.
+func WithContentDir(root string) Option {
+	return func(s *service) {
+		if root == "" {
+			return
+		}
+
+		info, err := os.Stat(filepath.Join(root, filepath.FromSlash(s.blogDir)))
+		if err != nil || !info.IsDir() {
+			s.logger.Printf("BLOG: Warning - content directory %s has no %s, using embedded posts", root, s.blogDir)
+			return
+		}
+
+		s.blogFS = os.DirFS(root)
+		s.contentDir = root
+	}
+}
+
+// WithRenderer sets the markdown renderer used for posts, so they render
+// like the rest of the site. A table of contents is added per post.
+func WithRenderer(renderer *render.Renderer) Option {
+	return func(s *service) {
+		if renderer != nil {
+			s.renderer = renderer
+		}
+	}
+}
+
+// WithHistory fills in the created and updated dates and revisions of posts
+// from their history
+func WithHistory(provider history.Provider) Option {
+	return func(s *service) {
+		s.history = provider
+	}
+}
+
+// WithWatchInterval sets how often the content directory is polled for
+// changes. Zero or a negative interval disables watching. It only has an
+// effect together with WithContentDir.
+func WithWatchInterval(interval time.Duration) Option {
+	return func(s *service) {
+		s.watchInterval = interval
+	}
+}
+
+// fileStamp identifies one version of a content file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// contentStamps records the blog.yml, authors.yml, post and image files
+// currently on disk, posts keyed by slug and other files by name
+type contentStamps struct {
+	config  fileStamp
+	authors fileStamp
+	posts   map[string]fileStamp
+	assets  map[string]fileStamp
+}
+
+// stampContent stats the files a load reads. A missing blog.yml or
+// authors.yml stamps as zero so that creating it later counts as a change.
+func (s *service) stampContent() (contentStamps, error) {
+	stamps := contentStamps{posts: make(map[string]fileStamp), assets: make(map[string]fileStamp)}
+
+	if info, err := fs.Stat(s.blogFS, blogConfigPath); err == nil {
+		stamps.config = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	if info, err := fs.Stat(s.blogFS, authorsPath); err == nil {
+		stamps.authors = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	files, err := fs.ReadDir(s.blogFS, s.blogDir)
+	if err != nil {
+		return stamps, err
+	}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue // removed between listing and stat, the next poll sees it gone
+		}
+		stamp := fileStamp{modTime: info.ModTime(), size: info.Size()}
+		if slug, isPost := strings.CutSuffix(file.Name(), ".md"); isPost {
+			stamps.posts[slug] = stamp
+		} else {
+			// Images next to posts are rendered into them
+			stamps.assets[file.Name()] = stamp
+		}
+	}
+
+	return stamps, nil
+}
+
+// diff returns the slugs added or modified since prev and the slugs removed
+func (c contentStamps) diff(prev contentStamps) (updated, deleted []string) {
+	for slug, stamp := range c.posts {
+		if old, exists := prev.posts[slug]; !exists || !old.modTime.Equal(stamp.modTime) || old.size != stamp.size {
+			updated = append(updated, slug)
+		}
+	}
+	for slug := range prev.posts {
+		if _, exists := c.posts[slug]; !exists {
+			deleted = append(deleted, slug)
+		}
+	}
+	sort.Strings(updated)
+	sort.Strings(deleted)
+	return updated, deleted
+}
+
+// assetsChanged reports whether any file other than a post differs from prev
+func (c contentStamps) assetsChanged(prev contentStamps) bool {
+	if len(c.assets) != len(prev.assets) {
+		return true
+	}
+	for name, stamp := range c.assets {
+		if old, exists := prev.assets[name]; !exists || old != stamp {
+			return true
+		}
+	}
+	return false
+}
+
+// startWatching polls the content directory in the background until
+// stopWatching is called. It does nothing when serving the embed.
+func (s *service) startWatching() {
+	if s.contentDir == "" || s.watchInterval <= 0 || s.stopWatch != nil {
+		return
+	}
+
+	stamps, err := s.stampContent()
+	if err != nil {
+		s.logger.Printf("BLOG: Warning - could not stat %s: %v", s.contentDir, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopWatch = cancel
+	s.watchDone = make(chan struct{})
+
+	s.logger.Printf("BLOG: Watching %s for changes every %s", s.contentDir, s.watchInterval)
+	go s.watch(ctx, stamps)
+}
+
+// stopWatching stops the poller started by startWatching and waits for it
+func (s *service) stopWatching() {
+	if s.stopWatch == nil {
+		return
+	}
+	s.stopWatch()
+	<-s.watchDone
+	s.stopWatch = nil
+	s.watchDone = nil
+}
+
+// watch reloads posts whenever the stamps of the content files change
+func (s *service) watch(ctx context.Context, stamps contentStamps) {
+	defer close(s.watchDone)
+
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stamps = s.reloadIfChanged(ctx, stamps)
+		}
+	}
+}
+
+// reloadIfChanged reloads the blog when any content file differs from prev
+// and returns the stamps to compare against next time. When the reload
+// fails prev is returned so that the next poll tries again.
+func (s *service) reloadIfChanged(ctx context.Context, prev contentStamps) contentStamps {
+	current, err := s.stampContent()
+	if err != nil {
+		s.logger.Printf("BLOG: Warning - could not stat %s: %v", s.contentDir, err)
+		return prev
+	}
+
+	updated, deleted := current.diff(prev)
+	configChanged := current.config != prev.config
+	authorsChanged := current.authors != prev.authors
+	if len(updated) == 0 && len(deleted) == 0 && !configChanged && !authorsChanged && !current.assetsChanged(prev) {
+		return prev
+	}
+
+	if err := s.load(ctx, configChanged); err != nil {
+		s.logger.Printf("BLOG: Warning - reload failed, keeping previous posts: %v", err)
+		return prev
+	}
+	s.logger.Printf("BLOG: Reloaded posts from %s (%d changed, %d removed)", s.contentDir, len(updated), len(deleted))
+
+	s.publishChanges(ctx, events.EventBlogUpdated, updated)
+	s.publishChanges(ctx, events.EventBlogDeleted, deleted)
+
+	return current
+}
+
+// publishChanges publishes one event of the given type per changed post
+// file, with the slug and locale of the post. A translation's event carries
+// the slug of its original, as GetBySlug expects it.
+func (s *service) publishChanges(ctx context.Context, eventType events.EventType, names []string) {
+	if s.eventBus == nil {
+		return
+	}
+	for _, name := range names {
+		slug, lang := s.splitLocale(name)
+		s.eventBus.Publish(ctx, events.NewEventWithContext(ctx, eventType,
+			map[string]interface{}{
+				"slug":   slug,
+				"locale": lang,
+			},
+		))
+	}
+}
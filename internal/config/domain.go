@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 // SiteConfig represents the complete site configuration
 type SiteConfig struct {
 	Site           SiteInfo            `yaml:"site"`
@@ -13,6 +15,10 @@ type SiteConfig struct {
 	Expertise      ExpertiseInfo       `yaml:"expertise"`
 	BootSequences  BootSequencesInfo   `yaml:"boot_sequences"`
 	WorkExperience WorkExperienceInfo  `yaml:"work_experience"`
+	Robots         RobotsInfo          `yaml:"robots"`
+
+	// LastMod is when the files the configuration was read from last changed
+	LastMod time.Time `yaml:"-"`
 }
 
 type SiteInfo struct {
@@ -21,6 +27,11 @@ type SiteInfo struct {
 	Tagline     string `yaml:"tagline"`
 	Subtitle    string `yaml:"subtitle"`
 	HeroStyle   string `yaml:"hero_style"`
+	BaseURL     string `yaml:"base_url"` // Absolute origin used for feeds and canonical links
+	Author      string `yaml:"author"`   // Author of pages other than blog posts
+	Image       string `yaml:"image"`    // Default image of link previews, the profile image when empty
+	Twitter     string `yaml:"twitter"`  // Twitter/X handle for link previews
+	Language    string `yaml:"language"` // Locale served at the root of the site, en when empty
 }
 
 type AboutInfo struct {
@@ -37,6 +48,7 @@ type ContactInfo struct {
 type BrandingInfo struct {
 	LogoMain       string `yaml:"logo_main"`
 	LogoHero       string `yaml:"logo_hero"`
+	LogoImage      string `yaml:"logo_image"` // PNG or JPEG logo drawn on generated social images
 	PrimaryColor   string `yaml:"primary_color"`
 	SecondaryColor string `yaml:"secondary_color"`
 }
@@ -85,6 +97,13 @@ type FeaturesInfo struct {
 	AnalyticsEnabled bool `yaml:"analytics_enabled"`
 }
 
+// RobotsInfo configures the generated robots.txt
+type RobotsInfo struct {
+	Allow      []string `yaml:"allow"`
+	Disallow   []string `yaml:"disallow"`
+	CrawlDelay int      `yaml:"crawl_delay"`
+}
+
 type StatInfo struct {
 	Value string `yaml:"value"`
 	Label string `yaml:"label"`
@@ -154,6 +173,9 @@ type WorkConfig struct {
 	FinTech    WorkSection       `yaml:"fintech"`
 	Blockchain WorkSection       `yaml:"blockchain"`
 	AI         WorkSection       `yaml:"ai"`
+
+	// LastMod is when work.yml last changed
+	LastMod time.Time `yaml:"-"`
 }
 
 type WorkSection struct {
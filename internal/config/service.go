@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,6 +14,7 @@ import (
 // Service interface for configuration management
 type Service interface {
 	LoadConfig(configPath string) (*SiteConfig, error)
+	LoadLocaleConfig(configPath, locale string) (*SiteConfig, error)
 	LoadWorkConfig(configPath string) (*WorkConfig, error)
 }
 
@@ -51,13 +55,76 @@ func (s *service) LoadConfig(configPath string) (*SiteConfig, error) {
 
 	// Set defaults
 	s.setDefaults(&config)
+	config.LastMod = modTime(configPath)
 
 	s.logger.Printf("Loaded configuration from %s", configPath)
 	return &config, nil
 }
 
+// LoadLocaleConfig loads the site configuration of a locale: site.yml with
+// site.<locale>.yml from the same directory laid over it. Keys the overlay
+// leaves out keep their site.yml values; lists it sets replace them whole.
+// Without an overlay this is the same as LoadConfig.
+func (s *service) LoadLocaleConfig(configPath, locale string) (*SiteConfig, error) {
+	if configPath == "" {
+		configPath = "content/site.yml"
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var config SiteConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config YAML: %w", err)
+	}
+
+	overlayPath := LocaleConfigPath(configPath, locale)
+	overlay, err := os.ReadFile(overlayPath)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(overlay, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", overlayPath, err)
+		}
+		s.logger.Printf("Loaded %s configuration from %s", locale, overlayPath)
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("failed to read %s: %w", overlayPath, err)
+	}
+
+	s.setDefaults(&config)
+	config.LastMod = modTime(configPath)
+	if overlayMod := modTime(overlayPath); overlayMod.After(config.LastMod) {
+		config.LastMod = overlayMod
+	}
+	return &config, nil
+}
+
+// modTime returns when a file was last modified, zero if it doesn't exist
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// LocaleConfigPath returns where the overlay of a locale lives next to a
+// configuration file, site.yml -> site.es.yml
+func LocaleConfigPath(configPath, locale string) string {
+	ext := filepath.Ext(configPath)
+	return strings.TrimSuffix(configPath, ext) + "." + locale + ext
+}
+
 // setDefaults sets default values for missing config
 func (s *service) setDefaults(config *SiteConfig) {
+	// Normalize base URL so callers can append absolute paths
+	config.Site.BaseURL = strings.TrimRight(config.Site.BaseURL, "/")
+	
+	if config.Site.Language == "" {
+		config.Site.Language = "en"
+	}
+	
 	if config.Site.HeroStyle == "" {
 		config.Site.HeroStyle = "professional"
 	}
@@ -126,6 +193,8 @@ func (s *service) LoadWorkConfig(configPath string) (*WorkConfig, error) {
 		return nil, fmt.Errorf("failed to parse work config YAML: %w", err)
 	}
 
+	config.LastMod = modTime(configPath)
+
 	s.logger.Printf("Loaded work configuration from %s", configPath)
 	return &config, nil
 }
\ No newline at end of file
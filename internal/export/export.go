@@ -0,0 +1,339 @@
+// Package export writes a static copy of the site. Pages are requested from
+// the router in-process, the links between them are followed, and root
+// relative links are rewritten so the copy works from any directory.
+package export
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Options configures an export
+type Options struct {
+	// OutDir is the directory the site is written to. Existing files with
+	// the same names are overwritten.
+	OutDir string
+
+	// BaseURL is the origin requests are made against, for handlers that
+	// build absolute URLs from the request
+	BaseURL string
+
+	// Routes are the paths to start from. Pages, fragments and assets they
+	// link to are exported as well.
+	Routes []string
+
+	// Exclude lists path prefixes that are neither requested nor rewritten,
+	// such as APIs a static copy can't serve
+	Exclude []string
+
+	// Fragments lists path prefixes of HTML fragments that HTMX loads into
+	// other pages. Their links resolve against the page they're inserted
+	// into, so they're followed but left root relative.
+	Fragments []string
+
+	Logger *log.Logger
+}
+
+// Failure is a route that didn't answer 200 OK
+type Failure struct {
+	Path     string
+	Status   int
+	Referrer string // page that linked to it, empty for starting routes
+}
+
+// Result summarises an export
+type Result struct {
+	Files    int
+	Failures []Failure
+}
+
+// page is an exported response waiting for its links to be rewritten
+type page struct {
+	path        string
+	file        string
+	contentType string
+	body        []byte
+}
+
+// Link patterns in HTML and CSS. Only double quoted attributes are matched,
+// which is how the templates and the markdown renderer write them.
+var (
+	attrLink   = regexp.MustCompile(`(\s(?:href|src|poster|hx-get|hx-push-url)=")([^"]*)(")`)
+	srcsetLink = regexp.MustCompile(`(\ssrcset=")([^"]*)(")`)
+	cssLink    = regexp.MustCompile(`(url\(\s*['"]?)([^'")\s]+)(['"]?\s*\))`)
+)
+
+// Site exports every route reachable from opts.Routes through handler
+func Site(handler http.Handler, opts Options) (*Result, error) {
+	if opts.OutDir == "" {
+		return nil, fmt.Errorf("no output directory")
+	}
+	if opts.BaseURL == "" {
+		opts.BaseURL = "http://localhost"
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.Default()
+	}
+
+	e := &exporter{
+		handler: handler,
+		opts:    opts,
+		files:   make(map[string]string),
+		seen:    make(map[string]bool),
+		result:  &Result{},
+	}
+	for _, route := range opts.Routes {
+		e.enqueue(route, "")
+	}
+
+	var pages []*page
+	for len(e.queue) > 0 {
+		next := e.queue[0]
+		e.queue = e.queue[1:]
+		p, err := e.fetch(next.path, next.referrer)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			pages = append(pages, p)
+		}
+	}
+
+	// Links are rewritten once every target's file name is known
+	for _, p := range pages {
+		body := p.body
+		if !e.fragment(p.path) {
+			body = e.rewrite(p)
+		}
+		if err := e.write(p.file, body); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(e.result.Failures, func(i, j int) bool {
+		return e.result.Failures[i].Path < e.result.Failures[j].Path
+	})
+	return e.result, nil
+}
+
+// exporter holds the state of one export
+type exporter struct {
+	handler http.Handler
+	opts    Options
+	queue   []struct{ path, referrer string }
+	seen    map[string]bool
+	files   map[string]string // URL path to exported file
+	result  *Result
+}
+
+// enqueue schedules a path for export unless it has been seen or excluded
+func (e *exporter) enqueue(urlPath, referrer string) {
+	if e.seen[urlPath] || e.excluded(urlPath) {
+		return
+	}
+	e.seen[urlPath] = true
+	e.queue = append(e.queue, struct{ path, referrer string }{urlPath, referrer})
+}
+
+// excluded reports whether a path falls under an excluded prefix
+func (e *exporter) excluded(urlPath string) bool {
+	return hasPrefix(urlPath, e.opts.Exclude)
+}
+
+// fragment reports whether a path is an HTMX fragment
+func (e *exporter) fragment(urlPath string) bool {
+	return hasPrefix(urlPath, e.opts.Fragments)
+}
+
+// hasPrefix reports whether s starts with any of prefixes
+func hasPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetch requests a path from the router. Binary responses are written
+// straight away; HTML and CSS are returned so their links can be followed
+// and later rewritten.
+func (e *exporter) fetch(urlPath, referrer string) (*page, error) {
+	target := url.URL{Path: urlPath}
+	req := httptest.NewRequest(http.MethodGet, e.opts.BaseURL+target.EscapedPath(), nil)
+	rec := httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		e.opts.Logger.Printf("EXPORT: %s returned %d", urlPath, rec.Code)
+		e.result.Failures = append(e.result.Failures, Failure{Path: urlPath, Status: rec.Code, Referrer: referrer})
+		return nil, nil
+	}
+
+	contentType, _, _ := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+	p := &page{path: urlPath, file: exportFile(urlPath, contentType), contentType: contentType, body: rec.Body.Bytes()}
+	e.files[urlPath] = p.file
+
+	if !rewritable(contentType) {
+		return nil, e.write(p.file, p.body)
+	}
+	for _, link := range links(p) {
+		if target, ok := localPath(link); ok {
+			e.enqueue(target, urlPath)
+		}
+	}
+	return p, nil
+}
+
+// write saves an exported file
+func (e *exporter) write(file string, data []byte) error {
+	name := filepath.Join(e.opts.OutDir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return fmt.Errorf("create directory for %s: %w", file, err)
+	}
+	if err := os.WriteFile(name, data, 0644); err != nil {
+		return fmt.Errorf("write %s: %w", file, err)
+	}
+	e.result.Files++
+	return nil
+}
+
+// rewrite points the root relative links of a page at the exported files,
+// relative to the page. Links to anything that wasn't exported are kept.
+func (e *exporter) rewrite(p *page) []byte {
+	replace := func(link string) string {
+		target, ok := localPath(link)
+		if !ok {
+			return link
+		}
+		file, ok := e.files[target]
+		if !ok {
+			return link
+		}
+		rel := relativeURL(p.file, file)
+		if i := strings.IndexByte(link, '#'); i >= 0 {
+			rel += link[i:]
+		}
+		return rel
+	}
+
+	body := p.body
+	if p.contentType == "text/html" {
+		body = attrLink.ReplaceAllFunc(body, func(match []byte) []byte {
+			parts := attrLink.FindSubmatch(match)
+			return []byte(string(parts[1]) + replace(string(parts[2])) + string(parts[3]))
+		})
+		body = srcsetLink.ReplaceAllFunc(body, func(match []byte) []byte {
+			parts := srcsetLink.FindSubmatch(match)
+			candidates := strings.Split(string(parts[2]), ",")
+			for i, candidate := range candidates {
+				fields := strings.Fields(candidate)
+				if len(fields) > 0 {
+					fields[0] = replace(fields[0])
+					candidates[i] = strings.Join(fields, " ")
+				}
+			}
+			return []byte(string(parts[1]) + strings.Join(candidates, ", ") + string(parts[3]))
+		})
+	}
+	return cssLink.ReplaceAllFunc(body, func(match []byte) []byte {
+		parts := cssLink.FindSubmatch(match)
+		return []byte(string(parts[1]) + replace(string(parts[2])) + string(parts[3]))
+	})
+}
+
+// links returns the URLs an HTML page or stylesheet refers to
+func links(p *page) []string {
+	var found []string
+	if p.contentType == "text/html" {
+		for _, m := range attrLink.FindAllSubmatch(p.body, -1) {
+			found = append(found, string(m[2]))
+		}
+		for _, m := range srcsetLink.FindAllSubmatch(p.body, -1) {
+			for _, candidate := range strings.Split(string(m[2]), ",") {
+				if fields := strings.Fields(candidate); len(fields) > 0 {
+					found = append(found, fields[0])
+				}
+			}
+		}
+	}
+	for _, m := range cssLink.FindAllSubmatch(p.body, -1) {
+		found = append(found, string(m[2]))
+	}
+	return found
+}
+
+// localPath returns the path of a root relative link without its fragment.
+// Links with a query string aren't static, so they aren't local.
+func localPath(link string) (string, bool) {
+	if !strings.HasPrefix(link, "/") || strings.HasPrefix(link, "//") {
+		return "", false
+	}
+	u, err := url.Parse(link)
+	if err != nil || u.RawQuery != "" || u.Path == "" {
+		return "", false
+	}
+	return u.Path, true
+}
+
+// rewritable reports whether a content type has links to rewrite
+func rewritable(contentType string) bool {
+	return contentType == "text/html" || contentType == "text/css"
+}
+
+// exportFile names the file a URL path is exported to. HTML pages become
+// index.html files in a directory named after the path, so the copy can be
+// browsed from disk and served by any static host.
+func exportFile(urlPath, contentType string) string {
+	name := strings.TrimPrefix(urlPath, "/")
+	switch {
+	case name == "" || strings.HasSuffix(name, "/"):
+		return name + "index.html"
+	case contentType == "text/html" && path.Ext(name) != ".html":
+		return name + "/index.html"
+	}
+	return name
+}
+
+// relativeURL returns the link from one exported file to another
+func relativeURL(from, to string) string {
+	fromDir := strings.Split(path.Dir(from), "/")
+	if fromDir[0] == "." {
+		fromDir = nil
+	}
+	target := strings.Split(to, "/")
+
+	common := 0
+	for common < len(fromDir) && common < len(target)-1 && fromDir[common] == target[common] {
+		common++
+	}
+	parts := make([]string, 0, len(fromDir)-common+len(target)-common)
+	for range fromDir[common:] {
+		parts = append(parts, "..")
+	}
+	parts = append(parts, target[common:]...)
+	return strings.Join(parts, "/")
+}
+
+// Summary writes a report of the export to w
+func (r *Result) Summary(w io.Writer) {
+	for _, failure := range r.Failures {
+		if failure.Referrer != "" {
+			fmt.Fprintf(w, "✗ %s: %d (linked from %s)\n", failure.Path, failure.Status, failure.Referrer)
+		} else {
+			fmt.Fprintf(w, "✗ %s: %d\n", failure.Path, failure.Status)
+		}
+	}
+	fmt.Fprintf(w, "\n%d files written, %d routes failed\n", r.Files, len(r.Failures))
+}
@@ -0,0 +1,83 @@
+package export
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSite() http.Handler {
+	mux := http.NewServeMux()
+	html := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, body)
+		}
+	}
+	mux.HandleFunc("GET /{$}", html(`<link href="/static/site.css" rel="stylesheet"><a href="/blog">Blog</a> <a href="/blog/first#intro">First</a> <a href="https://example.com/">Out</a>`))
+	mux.HandleFunc("GET /blog", html(`<a href="/" hx-get="/content/home">Home</a> <a href="/blog/first">First</a> <a href="/blog/missing">Gone</a> <a href="/blog/search?q=go">Search</a>`))
+	mux.HandleFunc("GET /blog/first", html(`<img src="/images/a.png" srcset="/images/a-400w.png 400w, /images/a.png 800w" alt="A"> <a href="/api/slots">Slots</a>`))
+	mux.HandleFunc("GET /content/home", html(`<a href="/blog">Blog</a>`))
+	mux.HandleFunc("GET /blog/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<rss><link>https://example.com/blog</link></rss>`)
+	})
+	mux.HandleFunc("GET /static/site.css", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/css")
+		fmt.Fprint(w, `body { background: url("/static/bg.png"); }`)
+	})
+	png := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		fmt.Fprint(w, "png:"+r.URL.Path)
+	}
+	mux.HandleFunc("GET /static/bg.png", png)
+	mux.HandleFunc("GET /images/", png)
+	return mux
+}
+
+func TestSite(t *testing.T) {
+	dir := t.TempDir()
+	result, err := Site(testSite(), Options{
+		OutDir:    dir,
+		Routes:    []string{"/", "/blog/feed.xml"},
+		Exclude:   []string{"/api/", "/blog/search"},
+		Fragments: []string{"/content/"},
+	})
+	require.NoError(t, err)
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	assert.Equal(t, `<link href="static/site.css" rel="stylesheet"><a href="blog/index.html">Blog</a> <a href="blog/first/index.html#intro">First</a> <a href="https://example.com/">Out</a>`, read("index.html"))
+	assert.Equal(t, `<a href="../index.html" hx-get="../content/home/index.html">Home</a> <a href="first/index.html">First</a> <a href="/blog/missing">Gone</a> <a href="/blog/search?q=go">Search</a>`, read("blog/index.html"),
+		"links that weren't exported are kept")
+	assert.Equal(t, `<img src="../../images/a.png" srcset="../../images/a-400w.png 400w, ../../images/a.png 800w" alt="A"> <a href="/api/slots">Slots</a>`, read("blog/first/index.html"))
+	assert.Equal(t, `<a href="/blog">Blog</a>`, read("content/home/index.html"), "fragments keep root relative links")
+	assert.Equal(t, `body { background: url("bg.png"); }`, read("static/site.css"))
+	assert.Equal(t, "png:/images/a-400w.png", read("images/a-400w.png"))
+	assert.Contains(t, read("blog/feed.xml"), "https://example.com/blog")
+
+	assert.Equal(t, 9, result.Files)
+	assert.Equal(t, []Failure{{Path: "/blog/missing", Status: http.StatusNotFound, Referrer: "/blog"}}, result.Failures)
+}
+
+func TestRelativeURL(t *testing.T) {
+	tests := []struct{ from, to, want string }{
+		{"index.html", "blog/index.html", "blog/index.html"},
+		{"blog/index.html", "blog/first/index.html", "first/index.html"},
+		{"blog/first/index.html", "index.html", "../../index.html"},
+		{"blog/tag/go/index.html", "blog/feed.xml", "../../feed.xml"},
+		{"blog/index.html", "blog/index.html", "index.html"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, relativeURL(tt.from, tt.to), "%s to %s", tt.from, tt.to)
+	}
+}
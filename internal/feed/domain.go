@@ -0,0 +1,58 @@
+package feed
+
+import (
+	"time"
+)
+
+// Feed is a format-neutral representation of a syndication feed
+type Feed struct {
+	Title       string
+	Description string
+	BaseURL     string // Absolute site origin, e.g. https://blockhead.consulting
+	Link        string // Absolute URL of the HTML page the feed mirrors
+	FeedURL     string // Absolute URL of the feed document itself
+	Author      string
+	Language    string
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item is a single entry in a feed
+type Item struct {
+	ID        string
+	Title     string
+	URL       string
+	Summary   string
+	Content   string // Rendered HTML with absolute URLs
+	Published time.Time
+	Updated   time.Time
+	Tags      []string
+	Authors   []Person // Credited authors, the feed's author when empty
+}
+
+// Person is an author of a feed item
+type Person struct {
+	Name string
+	URL  string // Absolute URL of the author's page, empty when there's none
+}
+
+// Format identifies a feed serialization
+type Format string
+
+const (
+	FormatRSS  Format = "rss"
+	FormatAtom Format = "atom"
+	FormatJSON Format = "json"
+)
+
+// ContentType returns the HTTP content type for a feed format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatAtom:
+		return "application/atom+xml; charset=utf-8"
+	case FormatJSON:
+		return "application/feed+json; charset=utf-8"
+	default:
+		return "application/rss+xml; charset=utf-8"
+	}
+}
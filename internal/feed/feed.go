@@ -0,0 +1,372 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"blockhead.consulting/internal/blog"
+)
+
+// Options describes the feed being built from blog posts
+type Options struct {
+	Title       string
+	Description string
+	BaseURL     string
+	Path        string // Site-relative path of the HTML page, e.g. /blog
+	Prefix      string // Locale prefix of author links and posts in Language, e.g. /es
+	FeedPath    string // Site-relative path of the feed document
+	Author      string
+	Language    string
+	Limit       int // Maximum number of items, 0 for no limit
+}
+
+// FromPosts builds a feed from blog posts, newest first
+func FromPosts(opts Options, posts []blog.Post) *Feed {
+	baseURL := strings.TrimRight(opts.BaseURL, "/")
+
+	if opts.Limit > 0 && len(posts) > opts.Limit {
+		posts = posts[:opts.Limit]
+	}
+
+	f := &Feed{
+		Title:       opts.Title,
+		Description: opts.Description,
+		BaseURL:     baseURL,
+		Link:        baseURL + opts.Path,
+		FeedURL:     baseURL + opts.FeedPath,
+		Author:      opts.Author,
+		Language:    opts.Language,
+		Items:       make([]Item, 0, len(posts)),
+	}
+
+	for _, post := range posts {
+		// Posts not translated into the feed's language link to the original
+		url := baseURL + "/blog/" + post.Slug
+		if post.Lang == opts.Language {
+			url = baseURL + opts.Prefix + "/blog/" + post.Slug
+		}
+		f.Items = append(f.Items, Item{
+			ID:        url,
+			Title:     post.Title,
+			URL:       url,
+			Summary:   post.Summary,
+			Content:   AbsoluteURLs(string(post.Content), baseURL),
+			Published: post.Date,
+			Updated:   post.Date,
+			Tags:      post.Tags,
+			Authors:   postAuthors(post, baseURL+opts.Prefix),
+		})
+
+		if post.Date.After(f.Updated) {
+			f.Updated = post.Date
+		}
+	}
+
+	return f
+}
+
+// postAuthors returns the people credited with a post, linking registered
+// authors to their pages under siteURL
+func postAuthors(post blog.Post, siteURL string) []Person {
+	var people []Person
+	for _, author := range post.Authors {
+		person := Person{Name: author.Name}
+		if author.Registered {
+			person.URL = siteURL + "/blog/author/" + author.ID
+		}
+		people = append(people, person)
+	}
+	return people
+}
+
+// Render serializes the feed in the requested format
+func (f *Feed) Render(format Format) ([]byte, error) {
+	switch format {
+	case FormatRSS:
+		return f.RSS()
+	case FormatAtom:
+		return f.Atom()
+	case FormatJSON:
+		return f.JSON()
+	default:
+		return nil, fmt.Errorf("unsupported feed format: %s", format)
+	}
+}
+
+// rootRelativeAttr matches href/src attributes pointing at site-relative paths
+var rootRelativeAttr = regexp.MustCompile(`(\s(?:href|src))="/([^/"][^"]*)?"`)
+
+// AbsoluteURLs rewrites site-relative links in rendered HTML to absolute URLs
+// so feed readers resolve them against the site rather than the reader
+func AbsoluteURLs(html, baseURL string) string {
+	if baseURL == "" {
+		return html
+	}
+	return rootRelativeAttr.ReplaceAllString(html, `$1="`+baseURL+`/$2"`)
+}
+
+// RSS 2.0 document structure
+
+type rssDocument struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	DCNS      string     `xml:"xmlns:dc,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Description   string      `xml:"description"`
+	Language      string      `xml:"language,omitempty"`
+	LastBuildDate string      `xml:"lastBuildDate,omitempty"`
+	AtomLink      rssAtomLink `xml:"atom:link"`
+	Items         []rssItem   `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string     `xml:"title"`
+	Link        string     `xml:"link"`
+	GUID        rssGUID    `xml:"guid"`
+	PubDate     string     `xml:"pubDate"`
+	Description string     `xml:"description"`
+	Content     rssContent `xml:"content:encoded"`
+	Categories  []string   `xml:"category"`
+	Creators    []string   `xml:"dc:creator"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssContent struct {
+	Value string `xml:",cdata"`
+}
+
+// RSS renders the feed as RSS 2.0
+func (f *Feed) RSS() ([]byte, error) {
+	doc := rssDocument{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			Language:    f.Language,
+			AtomLink: rssAtomLink{
+				Href: f.FeedURL,
+				Rel:  "self",
+				Type: "application/rss+xml",
+			},
+		},
+	}
+
+	if !f.Updated.IsZero() {
+		doc.Channel.LastBuildDate = f.Updated.Format(time.RFC1123Z)
+	}
+
+	for _, item := range f.Items {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.URL,
+			GUID:        rssGUID{Value: item.ID, IsPermaLink: item.ID == item.URL},
+			PubDate:     item.Published.Format(time.RFC1123Z),
+			Description: item.Summary,
+			Content:     rssContent{Value: item.Content},
+			Categories:  item.Tags,
+			Creators:    item.authorNames(),
+		})
+	}
+
+	return marshalXML(doc)
+}
+
+// Atom document structure
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	Tagline string      `xml:"subtitle,omitempty"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  *atomPerson `xml:"author,omitempty"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Authors    []atomPerson   `xml:"author"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Summary    string         `xml:"summary,omitempty"`
+	Content    atomText       `xml:"content"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// Atom renders the feed as Atom 1.0
+func (f *Feed) Atom() ([]byte, error) {
+	doc := atomFeed{
+		Title:   f.Title,
+		Tagline: f.Description,
+		ID:      f.Link,
+		Updated: f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+			{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"},
+		},
+	}
+
+	if f.Author != "" {
+		doc.Author = &atomPerson{Name: f.Author}
+	}
+
+	for _, item := range f.Items {
+		entry := atomEntry{
+			Title:     item.Title,
+			ID:        item.ID,
+			Link:      atomLink{Href: item.URL, Rel: "alternate", Type: "text/html"},
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+			Summary:   item.Summary,
+			Content:   atomText{Type: "html", Value: item.Content},
+		}
+		for _, author := range item.Authors {
+			entry.Authors = append(entry.Authors, atomPerson{Name: author.Name, URI: author.URL})
+		}
+		for _, tag := range item.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return marshalXML(doc)
+}
+
+// JSON Feed 1.1 document structure
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Language    string         `json:"language,omitempty"`
+	Authors     []jsonAuthor   `json:"authors,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type jsonFeedItem struct {
+	ID            string       `json:"id"`
+	URL           string       `json:"url"`
+	Title         string       `json:"title"`
+	ContentHTML   string       `json:"content_html"`
+	Summary       string       `json:"summary,omitempty"`
+	DatePublished string       `json:"date_published"`
+	DateModified  string       `json:"date_modified,omitempty"`
+	Tags          []string     `json:"tags,omitempty"`
+	Authors       []jsonAuthor `json:"authors,omitempty"`
+}
+
+// JSON renders the feed as JSON Feed 1.1
+func (f *Feed) JSON() ([]byte, error) {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Language:    f.Language,
+		Items:       make([]jsonFeedItem, 0, len(f.Items)),
+	}
+
+	if f.Author != "" {
+		doc.Authors = []jsonAuthor{{Name: f.Author}}
+	}
+
+	for _, item := range f.Items {
+		var authors []jsonAuthor
+		for _, author := range item.Authors {
+			authors = append(authors, jsonAuthor{Name: author.Name, URL: author.URL})
+		}
+		doc.Items = append(doc.Items, jsonFeedItem{
+			ID:            item.ID,
+			URL:           item.URL,
+			Title:         item.Title,
+			ContentHTML:   item.Content,
+			Summary:       item.Summary,
+			DatePublished: item.Published.UTC().Format(time.RFC3339),
+			DateModified:  item.Updated.UTC().Format(time.RFC3339),
+			Tags:          item.Tags,
+			Authors:       authors,
+		})
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// authorNames returns the names of the item's authors
+func (item Item) authorNames() []string {
+	names := make([]string, 0, len(item.Authors))
+	for _, author := range item.Authors {
+		names = append(names, author.Name)
+	}
+	return names
+}
+
+// marshalXML encodes an XML document with the standard header
+func marshalXML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode feed: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
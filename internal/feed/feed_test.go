@@ -0,0 +1,137 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"html/template"
+	"testing"
+	"time"
+
+	"blockhead.consulting/internal/blog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPosts() []blog.Post {
+	return []blog.Post{
+		{
+			Slug:    "newer-post",
+			Title:   "Newer Post",
+			Date:    time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+			Summary: "The newer one",
+			Content: template.HTML(`<p>See <a href="/blog/older-post">older</a> and <img src="/static/images/x.png"></p>`),
+			Tags:    []string{"golang"},
+			Authors: []blog.Author{
+				{ID: "ada", Name: "Ada", Registered: true},
+				{ID: "sam-guest", Name: "Sam Guest"},
+			},
+		},
+		{
+			Slug:    "older-post",
+			Title:   "Older Post",
+			Date:    time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+			Summary: "The older one",
+			Content: template.HTML(`<p>External <a href="https://example.com/">link</a></p>`),
+			Tags:    []string{"ai", "llm"},
+		},
+	}
+}
+
+func testOptions() Options {
+	return Options{
+		Title:       "Test Blog",
+		Description: "Testing feeds",
+		BaseURL:     "https://example.test/",
+		Path:        "/blog",
+		FeedPath:    "/blog/feed.xml",
+		Author:      "Tester",
+		Language:    "en",
+	}
+}
+
+func TestFromPosts(t *testing.T) {
+	f := FromPosts(testOptions(), testPosts())
+
+	assert.Equal(t, "https://example.test/blog", f.Link)
+	assert.Equal(t, "https://example.test/blog/feed.xml", f.FeedURL)
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), f.Updated)
+	require.Len(t, f.Items, 2)
+	assert.Equal(t, "https://example.test/blog/newer-post", f.Items[0].URL)
+	assert.Contains(t, f.Items[0].Content, `href="https://example.test/blog/older-post"`)
+	assert.Contains(t, f.Items[0].Content, `src="https://example.test/static/images/x.png"`)
+	assert.Contains(t, f.Items[1].Content, `href="https://example.com/"`)
+	assert.Equal(t, []Person{{Name: "Ada", URL: "https://example.test/blog/author/ada"}, {Name: "Sam Guest"}}, f.Items[0].Authors)
+	assert.Empty(t, f.Items[1].Authors)
+}
+
+func TestFromPostsLocale(t *testing.T) {
+	opts := testOptions()
+	opts.Path = "/es/blog"
+	opts.Prefix = "/es"
+	opts.Language = "es"
+	posts := testPosts()
+	posts[0].Lang = "es"
+	posts[1].Lang = "en"
+
+	f := FromPosts(opts, posts)
+	require.Len(t, f.Items, 2)
+	assert.Equal(t, "https://example.test/es/blog", f.Link)
+	assert.Equal(t, "https://example.test/es/blog/newer-post", f.Items[0].URL)
+	assert.Equal(t, "https://example.test/blog/older-post", f.Items[1].URL, "untranslated posts link to the original")
+	assert.Equal(t, "https://example.test/es/blog/author/ada", f.Items[0].Authors[0].URL)
+}
+
+func TestFromPostsLimit(t *testing.T) {
+	opts := testOptions()
+	opts.Limit = 1
+
+	f := FromPosts(opts, testPosts())
+	require.Len(t, f.Items, 1)
+	assert.Equal(t, "Newer Post", f.Items[0].Title)
+}
+
+func TestRSS(t *testing.T) {
+	body, err := FromPosts(testOptions(), testPosts()).RSS()
+	require.NoError(t, err)
+
+	var doc rssDocument
+	require.NoError(t, xml.Unmarshal(body, &doc))
+	assert.Equal(t, "2.0", doc.Version)
+	assert.Equal(t, "Test Blog", doc.Channel.Title)
+	require.Len(t, doc.Channel.Items, 2)
+	assert.Equal(t, []string{"ai", "llm"}, doc.Channel.Items[1].Categories)
+	assert.Contains(t, string(body), "<content:encoded><![CDATA[<p>See")
+	assert.Contains(t, string(body), "<dc:creator>Sam Guest</dc:creator>")
+}
+
+func TestAtom(t *testing.T) {
+	body, err := FromPosts(testOptions(), testPosts()).Atom()
+	require.NoError(t, err)
+
+	var doc atomFeed
+	require.NoError(t, xml.Unmarshal(body, &doc))
+	assert.Equal(t, "2025-06-01T00:00:00Z", doc.Updated)
+	require.Len(t, doc.Entries, 2)
+	assert.Equal(t, "html", doc.Entries[0].Content.Type)
+	assert.Equal(t, "golang", doc.Entries[0].Categories[0].Term)
+	assert.Equal(t, []atomPerson{{Name: "Ada", URI: "https://example.test/blog/author/ada"}, {Name: "Sam Guest"}}, doc.Entries[0].Authors)
+	assert.Empty(t, doc.Entries[1].Authors)
+}
+
+func TestJSON(t *testing.T) {
+	body, err := FromPosts(testOptions(), testPosts()).JSON()
+	require.NoError(t, err)
+
+	var doc jsonFeed
+	require.NoError(t, json.Unmarshal(body, &doc))
+	assert.Equal(t, "https://jsonfeed.org/version/1.1", doc.Version)
+	assert.Equal(t, "https://example.test/blog/feed.xml", doc.FeedURL)
+	require.Len(t, doc.Items, 2)
+	assert.Equal(t, "2025-05-01T00:00:00Z", doc.Items[1].DatePublished)
+	assert.Equal(t, []jsonAuthor{{Name: "Ada", URL: "https://example.test/blog/author/ada"}, {Name: "Sam Guest"}}, doc.Items[0].Authors)
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	_, err := FromPosts(testOptions(), testPosts()).Render(Format("yaml"))
+	assert.Error(t, err)
+}
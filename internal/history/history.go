@@ -0,0 +1,197 @@
+// Package history reads when content files were created and changed from
+// their git history, falling back to the file modification time outside a
+// checkout or for files that were never committed.
+package history
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxRevisions is how many revisions of a file are kept by default
+const DefaultMaxRevisions = 20
+
+// DefaultTimeout bounds each git invocation
+const DefaultTimeout = 5 * time.Second
+
+// logFormat separates the fields of a commit with unit separators
+const logFormat = "%h%x1f%aI%x1f%an%x1f%s"
+
+// Revision is one commit that changed a file
+type Revision struct {
+	Hash    string // abbreviated commit hash
+	Date    time.Time
+	Author  string
+	Subject string
+}
+
+// File is the history of one content file
+type File struct {
+	// Created is the date of the first commit of the file, zero when it
+	// isn't known from git
+	Created time.Time
+
+	// Updated is the date of the last commit, or the modification time
+	// when the file isn't in git. Zero when the file doesn't exist on disk.
+	Updated time.Time
+
+	// Revisions are the commits that changed the file, newest first and at
+	// most MaxRevisions of them. Empty without git.
+	Revisions []Revision
+}
+
+// Provider looks up the history of content files
+type Provider interface {
+	// Lookup returns the history of a file on disk, relative to the working
+	// directory or absolute
+	Lookup(ctx context.Context, file string) File
+}
+
+// Options configures Git
+type Options struct {
+	// MaxRevisions limits the revisions kept per file, DefaultMaxRevisions
+	// when zero
+	MaxRevisions int
+
+	// Timeout bounds each git invocation, DefaultTimeout when zero
+	Timeout time.Duration
+
+	Logger *log.Logger
+}
+
+// Git is a Provider that runs git log. Results are cached until the file's
+// size or modification time changes, so commits that don't touch the
+// working copy show up after a restart.
+type Git struct {
+	opts Options
+	git  string // path of the git binary, empty when it isn't installed
+
+	mu    sync.Mutex
+	cache map[string]cached // by absolute path
+}
+
+// cached is the history of a file as it was on disk
+type cached struct {
+	modTime time.Time
+	size    int64
+	file    File
+}
+
+// New creates a provider, logging once if git isn't installed
+func New(opts Options) *Git {
+	if opts.MaxRevisions <= 0 {
+		opts.MaxRevisions = DefaultMaxRevisions
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.Default()
+	}
+
+	g := &Git{opts: opts, cache: make(map[string]cached)}
+	if path, err := exec.LookPath("git"); err == nil {
+		g.git = path
+	} else {
+		g.opts.Logger.Printf("HISTORY: git not found, using file modification times")
+	}
+	return g
+}
+
+// Lookup returns the history of a file. Files that don't exist have an
+// empty history.
+func (g *Git) Lookup(ctx context.Context, file string) File {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return File{}
+	}
+	info, err := os.Stat(abs)
+	if err != nil || info.IsDir() {
+		return File{}
+	}
+
+	g.mu.Lock()
+	entry, ok := g.cache[abs]
+	g.mu.Unlock()
+	if ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		return entry.file
+	}
+
+	history, err := g.log(ctx, abs)
+	if len(history.Revisions) == 0 {
+		history = File{Updated: info.ModTime()}
+	}
+	if err != nil {
+		// Not cached, so the next lookup asks git again
+		g.opts.Logger.Printf("HISTORY: %v", err)
+		return history
+	}
+
+	g.mu.Lock()
+	g.cache[abs] = cached{modTime: info.ModTime(), size: info.Size(), file: history}
+	g.mu.Unlock()
+	return history
+}
+
+// log reads the commits that changed a file, following renames. Outside a
+// repository, or for untracked files, there are none. An error means git
+// didn't answer, because it failed or timed out.
+//
+// Histories are shared by every caller, so git isn't cancelled with the
+// context of the request that happened to ask first; only the timeout
+// bounds it.
+func (g *Git) log(ctx context.Context, abs string) (File, error) {
+	if g.git == "" {
+		return File{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), g.opts.Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, g.git, "log", "--follow", "--format="+logFormat, "--", filepath.Base(abs))
+	cmd.Dir = filepath.Dir(abs)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// "not a git repository" is expected in deployments without .git
+		if strings.Contains(stderr.String(), "not a git repository") {
+			return File{}, nil
+		}
+		return File{}, fmt.Errorf("git log %s: %w %s", abs, err, strings.TrimSpace(stderr.String()))
+	}
+	return parseLog(out, g.opts.MaxRevisions), nil
+}
+
+// parseLog reads git log output in logFormat, keeping at most limit revisions
+func parseLog(out []byte, limit int) File {
+	var history File
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	for _, line := range lines {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			continue
+		}
+		revision := Revision{Hash: fields[0], Date: date, Author: fields[2], Subject: fields[3]}
+
+		if history.Updated.IsZero() {
+			history.Updated = date
+		}
+		history.Created = date // the log is newest first
+		if len(history.Revisions) < limit {
+			history.Revisions = append(history.Revisions, revision)
+		}
+	}
+	return history
+}
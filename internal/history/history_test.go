@@ -0,0 +1,79 @@
+package history
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLog(t *testing.T) {
+	out := []byte("c3\x1f2024-03-01T10:00:00+01:00\x1fAda\x1fFix typo\n" +
+		"b2\x1f2024-02-01T10:00:00Z\x1fAda\x1fAdd examples\n" +
+		"a1\x1f2024-01-01T10:00:00Z\x1fGrace\x1fFirst draft\n")
+
+	history := parseLog(out, 2)
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), history.Created.UTC())
+	assert.Equal(t, time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), history.Updated.UTC())
+	require.Len(t, history.Revisions, 2, "revisions are limited, the first commit still dates the file")
+	assert.Equal(t, Revision{Hash: "c3", Date: history.Updated, Author: "Ada", Subject: "Fix typo"}, history.Revisions[0])
+
+	assert.Equal(t, File{}, parseLog(nil, 10))
+}
+
+// git runs a git command in dir with fixed commit dates
+func git(t *testing.T, dir, date string, args ...string) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com", "GIT_COMMITTER_DATE="+date,
+	)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func TestLookup(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	post := filepath.Join(dir, "post.md")
+	git(t, dir, "", "init", "-q")
+
+	require.NoError(t, os.WriteFile(post, []byte("one"), 0o644))
+	git(t, dir, "2024-01-01T12:00:00Z", "add", "post.md")
+	git(t, dir, "2024-01-01T12:00:00Z", "commit", "-q", "-m", "Add post")
+	require.NoError(t, os.WriteFile(post, []byte("two"), 0o644))
+	git(t, dir, "2024-02-01T12:00:00Z", "commit", "-q", "-am", "Revise post")
+
+	// A request that's gone doesn't stop git, or leave a wrong answer cached
+	g := New(Options{Logger: log.New(io.Discard, "", 0)})
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	history := g.Lookup(cancelled, post)
+	assert.Equal(t, "2024-01-01", history.Created.UTC().Format(time.DateOnly))
+	assert.Equal(t, "2024-02-01", history.Updated.UTC().Format(time.DateOnly))
+	require.Len(t, history.Revisions, 2)
+	assert.Equal(t, "Revise post", history.Revisions[0].Subject)
+	assert.Equal(t, "Test", history.Revisions[0].Author)
+
+	// Files git doesn't know fall back to their modification time
+	draft := filepath.Join(dir, "draft.md")
+	require.NoError(t, os.WriteFile(draft, []byte("wip"), 0o644))
+	modTime := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(draft, modTime, modTime))
+	history = g.Lookup(context.Background(), draft)
+	assert.True(t, history.Created.IsZero())
+	assert.True(t, modTime.Equal(history.Updated))
+	assert.Empty(t, history.Revisions)
+
+	assert.Equal(t, File{}, g.Lookup(context.Background(), filepath.Join(dir, "missing.md")))
+}
@@ -0,0 +1,262 @@
+// Package i18n holds the locales the site is published in and the
+// translations of its UI strings. The default locale is served at the root
+// of the site and every other locale under a /{lang} prefix; the locale of a
+// request travels in its context.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocale is used when the site doesn't name its language
+const DefaultLocale = "en"
+
+// Locale is a language the site is published in
+type Locale struct {
+	Code string // lowercase language tag, e.g. "en" or "pt-br"
+	Name string // name of the language in itself, e.g. "Español"
+}
+
+// catalogFile is the YAML of one locale's UI strings
+type catalogFile struct {
+	Name     string            `yaml:"name"`
+	Messages map[string]string `yaml:"messages"`
+}
+
+// Catalog is the set of locales and their UI strings. A nil catalog has only
+// the default locale and no translations.
+type Catalog struct {
+	defaultLocale string
+	locales       []Locale                     // default first, then by code
+	messages      map[string]map[string]string // locale -> key -> message
+}
+
+// New creates a catalog from messages by locale code. Locales without
+// messages are still served, falling back to the default locale's strings.
+func New(defaultLocale string, names map[string]string, messages map[string]map[string]string) *Catalog {
+	defaultLocale = Normalize(defaultLocale)
+	if defaultLocale == "" {
+		defaultLocale = DefaultLocale
+	}
+	c := &Catalog{defaultLocale: defaultLocale, messages: make(map[string]map[string]string)}
+
+	codes := map[string]bool{defaultLocale: true}
+	for code := range names {
+		codes[Normalize(code)] = true
+	}
+	for code, m := range messages {
+		code = Normalize(code)
+		codes[code] = true
+		c.messages[code] = m
+	}
+	for code := range codes {
+		if code == "" {
+			continue
+		}
+		name := names[code]
+		if name == "" {
+			name = code
+		}
+		c.locales = append(c.locales, Locale{Code: code, Name: name})
+	}
+	sort.Slice(c.locales, func(i, j int) bool {
+		if (c.locales[i].Code == defaultLocale) != (c.locales[j].Code == defaultLocale) {
+			return c.locales[i].Code == defaultLocale
+		}
+		return c.locales[i].Code < c.locales[j].Code
+	})
+	return c
+}
+
+// Load reads a catalog from the <lang>.yml files in dir. A missing directory
+// leaves the site in its default locale only.
+func Load(fsys fs.FS, dir, defaultLocale string, logger *log.Logger) (*Catalog, error) {
+	names := make(map[string]string)
+	messages := make(map[string]map[string]string)
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		logger.Printf("I18N: No catalogs in %s, serving %s only", dir, defaultLocale)
+		return New(defaultLocale, names, messages), nil
+	}
+	for _, entry := range entries {
+		code, ok := strings.CutSuffix(entry.Name(), ".yml")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", entry.Name(), err)
+		}
+		var file catalogFile
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", entry.Name(), err)
+		}
+		code = Normalize(code)
+		names[code] = file.Name
+		messages[code] = file.Messages
+	}
+
+	c := New(defaultLocale, names, messages)
+	logger.Printf("I18N: Loaded %d locales, default %s", len(c.locales), c.defaultLocale)
+	return c, nil
+}
+
+// Normalize lowercases a language tag and uses dashes, "pt_BR" -> "pt-br"
+func Normalize(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "_", "-"))
+}
+
+// Default returns the code of the locale served at the root of the site
+func (c *Catalog) Default() string {
+	if c == nil {
+		return DefaultLocale
+	}
+	return c.defaultLocale
+}
+
+// Locales returns every locale, the default first
+func (c *Catalog) Locales() []Locale {
+	if c == nil {
+		return []Locale{{Code: DefaultLocale, Name: DefaultLocale}}
+	}
+	return c.locales
+}
+
+// Others returns the locales served under a prefix
+func (c *Catalog) Others() []Locale {
+	locales := c.Locales()
+	return locales[1:]
+}
+
+// Name returns the name of a locale in itself, the default's for unknown codes
+func (c *Catalog) Name(code string) string {
+	code = c.Resolve(code)
+	for _, locale := range c.Locales() {
+		if locale.Code == code {
+			return locale.Name
+		}
+	}
+	return code
+}
+
+// Supports reports whether the site is published in a locale
+func (c *Catalog) Supports(code string) bool {
+	code = Normalize(code)
+	for _, locale := range c.Locales() {
+		if locale.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns a supported locale, the default for "" and unknown codes
+func (c *Catalog) Resolve(code string) string {
+	code = Normalize(code)
+	if code != "" && c.Supports(code) {
+		return code
+	}
+	return c.Default()
+}
+
+// T returns the UI string for a key in a locale, falling back to the default
+// locale and then to the key itself. Arguments are formatted into the
+// message with fmt verbs.
+func (c *Catalog) T(code, key string, args ...interface{}) string {
+	message := key
+	if c != nil {
+		if m, ok := c.messages[c.Resolve(code)][key]; ok {
+			message = m
+		} else if m, ok := c.messages[c.defaultLocale][key]; ok {
+			message = m
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Prefix returns the path prefix of a locale, "" for the default
+func (c *Catalog) Prefix(code string) string {
+	code = c.Resolve(code)
+	if code == c.Default() {
+		return ""
+	}
+	return "/" + code
+}
+
+// Path returns a site-relative path in a locale
+func (c *Catalog) Path(code, p string) string {
+	prefix := c.Prefix(code)
+	if prefix != "" && p == "/" {
+		return prefix + "/"
+	}
+	return prefix + p
+}
+
+// Split returns the locale of a request path from its prefix and the path
+// without it. Paths without a locale prefix are in the default locale.
+func (c *Catalog) Split(p string) (string, string) {
+	segment, rest, _ := strings.Cut(strings.TrimPrefix(p, "/"), "/")
+	for _, locale := range c.Others() {
+		if segment == locale.Code {
+			return locale.Code, "/" + rest
+		}
+	}
+	return c.Default(), p
+}
+
+// Negotiate returns the supported locale an Accept-Language header prefers
+// most, or "" when it accepts none of them. Regional tags match their
+// language, so "es-MX" accepts "es".
+func (c *Catalog) Negotiate(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= bestQ {
+			continue
+		}
+		tag = Normalize(tag)
+		language, _, _ := strings.Cut(tag, "-")
+		switch {
+		case c.Supports(tag):
+			best, bestQ = tag, q
+		case c.Supports(language):
+			best, bestQ = language, q
+		}
+	}
+	return best
+}
+
+// localeKey is the context key of the request locale
+type localeKey struct{}
+
+// WithLocale returns a context carrying a locale
+func WithLocale(ctx context.Context, code string) context.Context {
+	return context.WithValue(ctx, localeKey{}, code)
+}
+
+// FromContext returns the locale of a context, "" when it has none
+func FromContext(ctx context.Context) string {
+	code, _ := ctx.Value(localeKey{}).(string)
+	return code
+}
@@ -0,0 +1,83 @@
+package i18n
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCatalog(t *testing.T) *Catalog {
+	fsys := fstest.MapFS{
+		"i18n/en.yml":    {Data: []byte("name: English\nmessages:\n  read_more: Read more\n  min_read: \"%d min read\"\n  only_english: Only in English\n")},
+		"i18n/es.yml":    {Data: []byte("name: Español\nmessages:\n  read_more: Leer más\n  min_read: \"%d min de lectura\"\n")},
+		"i18n/pt_BR.yml": {Data: []byte("name: Português\n")},
+	}
+	c, err := Load(fsys, "i18n", "en", log.New(io.Discard, "", 0))
+	require.NoError(t, err)
+	return c
+}
+
+func TestLoad(t *testing.T) {
+	c := testCatalog(t)
+
+	assert.Equal(t, "en", c.Default())
+	assert.Equal(t, []Locale{{"en", "English"}, {"es", "Español"}, {"pt-br", "Português"}}, c.Locales())
+	assert.True(t, c.Supports("ES"))
+	assert.False(t, c.Supports("fr"))
+	assert.Equal(t, "en", c.Resolve("fr"))
+}
+
+func TestT(t *testing.T) {
+	c := testCatalog(t)
+
+	assert.Equal(t, "Leer más", c.T("es", "read_more"))
+	assert.Equal(t, "5 min de lectura", c.T("es", "min_read", 5))
+	assert.Equal(t, "Only in English", c.T("es", "only_english"), "missing strings fall back to the default locale")
+	assert.Equal(t, "Read more", c.T("fr", "read_more"), "unknown locales use the default")
+	assert.Equal(t, "no.such.key", c.T("en", "no.such.key"))
+
+	var none *Catalog
+	assert.Equal(t, "read_more", none.T("en", "read_more"))
+	assert.Equal(t, "en", none.Default())
+}
+
+func TestPaths(t *testing.T) {
+	c := testCatalog(t)
+
+	assert.Equal(t, "/blog", c.Path("en", "/blog"))
+	assert.Equal(t, "/es/blog", c.Path("es", "/blog"))
+	assert.Equal(t, "/es/", c.Path("es", "/"))
+	assert.Equal(t, "/", c.Path("en", "/"))
+
+	lang, rest := c.Split("/es/blog/post")
+	assert.Equal(t, "es", lang)
+	assert.Equal(t, "/blog/post", rest)
+	lang, rest = c.Split("/es/")
+	assert.Equal(t, "es", lang)
+	assert.Equal(t, "/", rest)
+	lang, rest = c.Split("/essays")
+	assert.Equal(t, "en", lang)
+	assert.Equal(t, "/essays", rest)
+}
+
+func TestNegotiate(t *testing.T) {
+	c := testCatalog(t)
+
+	assert.Equal(t, "es", c.Negotiate("es-MX,es;q=0.9,en;q=0.8"))
+	assert.Equal(t, "en", c.Negotiate("fr-FR, en;q=0.5, es;q=0.4"))
+	assert.Equal(t, "pt-br", c.Negotiate("pt-BR"))
+	assert.Equal(t, "", c.Negotiate("fr, de;q=0.9"))
+	assert.Equal(t, "", c.Negotiate(""))
+	assert.Equal(t, "en", c.Negotiate("es;q=bad, en;q=0.1"))
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", FromContext(ctx))
+	assert.Equal(t, "es", FromContext(WithLocale(ctx, "es")))
+}
@@ -0,0 +1,126 @@
+package lint
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"regexp"
+
+	"blockhead.consulting/internal/blog"
+	"blockhead.consulting/internal/config"
+	"blockhead.consulting/internal/i18n"
+	"gopkg.in/yaml.v3"
+)
+
+// configFiles are the YAML configuration files and the types the site
+// decodes them into
+var configFiles = []struct {
+	file     string
+	required bool
+	target   func() interface{}
+}{
+	{"content/site.yml", true, func() interface{} { return &config.SiteConfig{} }},
+	{"content/work.yml", false, func() interface{} { return &config.WorkConfig{} }},
+	{"content/blog.yml", false, func() interface{} { return &blog.BlogConfig{} }},
+	{"content/authors.yml", false, func() interface{} { return &blog.AuthorsConfig{} }},
+}
+
+// unknownField matches yaml's error for a key the target type doesn't have
+var unknownField = regexp.MustCompile(`field (\S+) not found in type (\S+)$`)
+
+// checkConfig decodes each configuration file strictly, reporting keys the
+// site would silently ignore, and keeps the blog configuration, authors and
+// locales for the post checks
+func (l *linter) checkConfig() {
+	language := i18n.DefaultLocale
+	for _, cf := range configFiles {
+		data, err := fs.ReadFile(l.fsys, cf.file)
+		if err != nil {
+			if cf.required || !os.IsNotExist(err) {
+				l.report(cf.file, 1, "%v", err)
+			}
+			continue
+		}
+
+		target := cf.target()
+		l.decodeStrict(cf.file, data, target)
+
+		if _, ok := target.(*blog.BlogConfig); ok {
+			// Unknown keys don't stop the blog from loading its tag filters
+			var loaded blog.BlogConfig
+			if yaml.Unmarshal(data, &loaded) == nil {
+				l.config = &loaded
+			}
+		}
+		if authors, ok := target.(*blog.AuthorsConfig); ok {
+			l.checkAuthors(cf.file, data, authors)
+		}
+		if site, ok := target.(*config.SiteConfig); ok && site.Site.Language != "" {
+			language = site.Site.Language
+		}
+	}
+	l.checkOverlays()
+
+	locales, err := i18n.Load(l.fsys, i18nDir, language, log.New(io.Discard, "", 0))
+	if err != nil {
+		l.report(i18nDir, 1, "%v", err)
+		locales = i18n.New(language, nil, nil)
+	}
+	l.locales = locales
+}
+
+// checkOverlays decodes the site.<lang>.yml overlays of other locales like
+// site.yml
+func (l *linter) checkOverlays() {
+	overlays, _ := fs.Glob(l.fsys, "content/site.*.yml")
+	for _, file := range overlays {
+		data, err := fs.ReadFile(l.fsys, file)
+		if err != nil {
+			l.report(file, 1, "%v", err)
+			continue
+		}
+		l.decodeStrict(file, data, &config.SiteConfig{})
+	}
+}
+
+// decodeStrict decodes YAML into target, reporting unknown keys and
+// malformed values
+func (l *linter) decodeStrict(file string, data []byte, target interface{}) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(target); err != nil && err != io.EOF {
+		if typeErr, ok := err.(*yaml.TypeError); ok {
+			for i, message := range typeErr.Errors {
+				typeErr.Errors[i] = unknownField.ReplaceAllString(message, `unknown key "$1" (not in $2)`)
+			}
+		}
+		l.reportYAML(file, 0, "", err)
+	}
+}
+
+// checkAuthors reports authors without an id or name and ids used twice
+func (l *linter) checkAuthors(file string, data []byte, authors *blog.AuthorsConfig) {
+	seen := make(map[string]bool)
+	offset := 0
+	for _, author := range authors.Authors {
+		line := 0
+		if author.ID != "" {
+			_, key := lineOf(data, offset, "id:")
+			line, offset = lineOf(data, key, author.ID)
+		}
+		id := blog.AuthorSlug(author.ID)
+		switch {
+		case id == "":
+			l.report(file, line, "author %q has no id", author.Name)
+		case seen[id]:
+			l.report(file, line, "duplicate author id %q", author.ID)
+		}
+		if author.Name == "" {
+			l.report(file, line, "author %q has no name", author.ID)
+		}
+		seen[id] = true
+	}
+	l.authors = authors
+}
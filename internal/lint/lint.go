@@ -0,0 +1,440 @@
+// Package lint checks site content for problems that would break or degrade
+// the rendered site, reporting each one with its file and line.
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"blockhead.consulting/internal/blog"
+	"blockhead.consulting/internal/errors"
+	"blockhead.consulting/internal/i18n"
+	"blockhead.consulting/internal/render"
+	"gopkg.in/yaml.v3"
+)
+
+// Content locations relative to the site root
+const (
+	contentDir = "content"
+	blogDir    = "content/blog"
+	i18nDir    = "content/i18n"
+	pagesDir   = "content/pages"
+	staticDir  = "static"
+)
+
+// bioFiles are the markdown files of the home and about pages
+var bioFiles = []string{"content/bio-brief.md", "content/about.md"}
+
+// yamlLine matches the position at the start of a YAML error message
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+
+// Problem is something wrong at a line of a content file
+type Problem struct {
+	File    string
+	Line    int
+	Message string
+}
+
+// String formats the problem as "file:line: message"
+func (p Problem) String() string {
+	return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+}
+
+// document is a markdown file being checked
+type document struct {
+	file       string // path from the site root
+	dir        string // directory relative images resolve against
+	content    []byte
+	bodyStart  int // offset of the markdown after the frontmatter
+	inspection render.Inspection
+	renderErr  error          // content error from loading the post
+	translated bool           // a translation, taking what it leaves out from the original
+	searched   map[string]int // where to look for the next use of a destination
+}
+
+// linter collects the problems of one run
+type linter struct {
+	fsys     fs.FS
+	images   *render.Images
+	renderer *render.Renderer
+	config   *blog.BlogConfig
+	authors  *blog.AuthorsConfig
+	locales  *i18n.Catalog
+	posts    map[string]*document // by slug, the original of translated posts
+	pages    map[string]bool
+	problems []Problem
+}
+
+// Check lints the site under the root of fsys: posts in content/blog, pages
+// in content/pages, the bio and the YAML configuration. Posts are loaded the
+// way the blog service loads them. Problems are sorted by file and line; an
+// error means the content couldn't be checked at all.
+func Check(fsys fs.FS) ([]Problem, error) {
+	static, err := fs.Sub(fsys, staticDir)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", staticDir, err)
+	}
+	l := &linter{
+		fsys: fsys,
+		images: render.NewImages(render.ImageOptions{
+			Static: static,
+			Logger: log.New(io.Discard, "", 0),
+		}),
+		posts: make(map[string]*document),
+		pages: make(map[string]bool),
+	}
+	l.renderer = render.New(render.Options{HeadingAnchors: true, Sanitize: true, Images: l.images})
+
+	l.checkConfig()
+	posts, err := l.checkPosts()
+	if err != nil {
+		return nil, err
+	}
+	docs := append(posts, l.loadPages()...)
+	for _, file := range bioFiles {
+		if doc := l.loadDocument(file, contentDir); doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+	for _, doc := range docs {
+		l.checkReferences(doc)
+	}
+
+	sort.SliceStable(l.problems, func(i, j int) bool {
+		if l.problems[i].File != l.problems[j].File {
+			return l.problems[i].File < l.problems[j].File
+		}
+		return l.problems[i].Line < l.problems[j].Line
+	})
+	return l.problems, nil
+}
+
+// report records a problem, at line 1 when the line isn't known
+func (l *linter) report(file string, line int, format string, args ...interface{}) {
+	if line < 1 {
+		line = 1
+	}
+	l.problems = append(l.problems, Problem{File: file, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// reportYAML records a YAML error at the lines it names, offset by the lines
+// before the YAML in the file
+func (l *linter) reportYAML(file string, offset int, prefix string, err error) {
+	messages := []string{err.Error()}
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		messages = typeErr.Errors
+	}
+	for _, message := range messages {
+		line := 0
+		if m := yamlLine.FindStringSubmatch(message); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			message = message[len(m[0]):]
+		}
+		l.report(file, line+offset, "%s%s", prefix, message)
+	}
+}
+
+// checkPosts loads every post and checks its frontmatter and slug
+func (l *linter) checkPosts() ([]*document, error) {
+	entries, err := fs.ReadDir(l.fsys, blogDir)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", blogDir, err)
+	}
+
+	var docs []*document
+	slugs := make(map[string]string) // lowercased file name to file, for case-insensitive filesystems
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		file := path.Join(blogDir, entry.Name())
+		name := strings.TrimSuffix(entry.Name(), ".md")
+		slug, lang := l.splitLocale(name)
+		if other, ok := slugs[strings.ToLower(name)]; ok {
+			l.report(file, 1, "duplicate slug %q, also used by %s", name, other)
+		}
+		slugs[strings.ToLower(name)] = file
+		if blog.IsReservedSlug(slug) {
+			l.report(file, 1, "slug %q is taken by the /blog/%s route", slug, slug)
+		}
+		if base, code, ok := strings.Cut(name, "."); ok && lang == l.locales.Default() && looksLikeLocale(code) {
+			if _, err := fs.Stat(l.fsys, path.Join(blogDir, base+".md")); err == nil {
+				l.report(file, 1, "served as post %q, add %s/%s.yml to publish it as a translation", slug, i18nDir, code)
+			}
+		}
+
+		doc := l.loadDocument(file, blogDir)
+		if doc == nil {
+			continue
+		}
+		if lang != l.locales.Default() {
+			_, err := fs.Stat(l.fsys, path.Join(blogDir, slug+".md"))
+			doc.translated = err == nil
+		}
+		if _, ok := l.posts[slug]; !ok || lang == l.locales.Default() {
+			l.posts[slug] = doc
+		}
+		docs = append(docs, doc)
+		l.checkPost(doc)
+	}
+	return docs, nil
+}
+
+// splitLocale returns the slug and locale of a post file name without its
+// extension, the way the blog service reads translations
+func (l *linter) splitLocale(name string) (string, string) {
+	if dot := strings.LastIndex(name, "."); dot > 0 {
+		code := i18n.Normalize(name[dot+1:])
+		for _, locale := range l.locales.Others() {
+			if locale.Code == code {
+				return name[:dot], code
+			}
+		}
+	}
+	return name, l.locales.Default()
+}
+
+// localeCode matches language tags such as es or pt-br
+var localeCode = regexp.MustCompile(`^[a-z]{2}(-[a-z]{2})?$`)
+
+// looksLikeLocale reports whether the end of a file name could be a language
+func looksLikeLocale(code string) bool {
+	return localeCode.MatchString(i18n.Normalize(code))
+}
+
+// checkPost loads a post like the blog does and checks its frontmatter.
+// Content errors from rendering are left to checkReferences, which can
+// place them.
+func (l *linter) checkPost(doc *document) {
+	frontmatter, _, err := blog.ParseFrontmatter(doc.content)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Cause != nil {
+			l.reportYAML(doc.file, 1, "invalid frontmatter: ", appErr.Cause)
+		} else {
+			l.report(doc.file, 1, "invalid frontmatter: %s", describe(err))
+		}
+		return
+	}
+
+	header := doc.content[:doc.bodyStart]
+	imageChecked := false
+	if frontmatter.Image != "" {
+		if err := l.images.Check(l.fsys, doc.dir, frontmatter.Image); err != nil {
+			line, _ := lineOf(header, 0, "image:")
+			l.report(doc.file, line, "%v", err)
+			imageChecked = true
+		}
+	}
+
+	if _, err := blog.LoadPost(l.fsys, doc.file, l.renderer); err != nil {
+		switch {
+		case imageChecked && isImageError(err):
+			// Already reported at the image line
+		case errors.GetCode(err) == errors.ErrCodeValidation:
+			doc.renderErr = err
+		default:
+			l.report(doc.file, 1, "%s", describe(err))
+		}
+	}
+
+	if strings.TrimSpace(frontmatter.Title) == "" {
+		l.report(doc.file, 1, "missing title")
+	}
+	if frontmatter.Date.IsZero() && !doc.translated {
+		l.report(doc.file, 1, "missing date")
+	}
+	if l.config != nil {
+		_, tags := lineOf(header, 0, "tags:")
+		for _, tag := range frontmatter.Tags {
+			if !l.config.KnowsTag(tag) {
+				line, _ := lineOf(header, tags, tag)
+				l.report(doc.file, line, "unknown tag %q, not in the blog.yml tag filters", tag)
+			}
+		}
+	}
+	if l.authors != nil {
+		// Guests are written by name; an id-like name is most likely a typo
+		_, authors := lineOf(header, 0, "author")
+		for _, name := range frontmatter.AuthorNames() {
+			if _, ok := l.authors.Find(name); !ok && blog.AuthorSlug(name) == name {
+				line, _ := lineOf(header, authors, name)
+				l.report(doc.file, line, "unknown author %q, not in authors.yml", name)
+			}
+		}
+	}
+}
+
+// loadPages reads the markdown pages, content/pages is optional
+func (l *linter) loadPages() []*document {
+	entries, err := fs.ReadDir(l.fsys, pagesDir)
+	if err != nil {
+		return nil
+	}
+
+	var docs []*document
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		l.pages[strings.TrimSuffix(entry.Name(), ".md")] = true
+		if doc := l.loadDocument(path.Join(pagesDir, entry.Name()), pagesDir); doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// loadDocument reads a markdown file and inspects its body, or returns nil
+// when the file is missing
+func (l *linter) loadDocument(file, dir string) *document {
+	content, err := fs.ReadFile(l.fsys, file)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			l.report(file, 1, "%v", err)
+		}
+		return nil
+	}
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+
+	doc := &document{file: file, dir: dir, content: content, searched: make(map[string]int)}
+	_, body, err := render.SplitFrontmatter(content)
+	if err == nil {
+		doc.bodyStart = len(content) - len(body)
+	}
+	doc.inspection = render.Inspect(content[doc.bodyStart:])
+	return doc
+}
+
+// checkReferences checks the images and internal links of a document
+func (l *linter) checkReferences(doc *document) {
+	reported := len(l.problems)
+	for _, ref := range doc.inspection.References {
+		line := doc.lineOf(ref.Destination)
+		if ref.Image {
+			if ref.Text == "" {
+				l.report(doc.file, line, "image %s has no alt text", ref.Destination)
+			}
+			if err := l.images.Check(l.fsys, doc.dir, ref.Destination); err != nil {
+				l.report(doc.file, line, "%v", err)
+			}
+			continue
+		}
+		if message := l.checkLink(doc, ref.Destination); message != "" {
+			l.report(doc.file, line, "%s", message)
+		}
+	}
+
+	// Content errors that aren't about a reference, such as an image that
+	// can't be decoded, are reported as the blog service saw them
+	if doc.renderErr != nil && len(l.problems) == reported {
+		l.report(doc.file, 1, "%s", describe(doc.renderErr))
+	}
+}
+
+// checkLink returns what's wrong with a link, or "" when it's fine or isn't
+// internal. Links to posts, pages, static files and anchors are checked.
+func (l *linter) checkLink(doc *document, destination string) string {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return fmt.Sprintf("malformed link %s", destination)
+	}
+	if u.Scheme != "" || u.Host != "" {
+		return ""
+	}
+
+	switch {
+	case u.Path == "":
+		if u.Fragment != "" && !doc.inspection.Anchors[u.Fragment] {
+			return fmt.Sprintf("broken link %s, no such heading", destination)
+		}
+
+	case strings.HasPrefix(u.Path, "/blog/"):
+		slug := strings.TrimPrefix(u.Path, "/blog/")
+		if strings.Contains(slug, "/") || blog.IsReservedSlug(slug) || isYear(slug) {
+			return ""
+		}
+		post, ok := l.posts[slug]
+		if !ok {
+			return fmt.Sprintf("broken link %s, no post %q", destination, slug)
+		}
+		if u.Fragment != "" && !post.inspection.Anchors[u.Fragment] {
+			return fmt.Sprintf("broken link %s, no such heading in %s", destination, post.file)
+		}
+
+	case strings.HasPrefix(u.Path, "/pages/"):
+		slug := strings.TrimPrefix(u.Path, "/pages/")
+		if !l.pages[slug] {
+			return fmt.Sprintf("broken link %s, no page %q", destination, slug)
+		}
+
+	case strings.HasPrefix(u.Path, "/static/"):
+		name := path.Join(staticDir, strings.TrimPrefix(u.Path, "/static/"))
+		if _, err := fs.Stat(l.fsys, name); err != nil {
+			return fmt.Sprintf("broken link %s, %s doesn't exist", destination, name)
+		}
+	}
+	return ""
+}
+
+// describe returns an error's message without the error code of
+// application errors
+func describe(err error) string {
+	appErr, ok := err.(*errors.AppError)
+	switch {
+	case !ok:
+		return err.Error()
+	case appErr.Cause != nil:
+		return appErr.Message + ": " + describe(appErr.Cause)
+	default:
+		return appErr.Message
+	}
+}
+
+// isImageError reports whether loading a post failed on its frontmatter image
+func isImageError(err error) bool {
+	return strings.HasPrefix(describe(err), "invalid post image")
+}
+
+// isYear reports whether a /blog/ path segment is a yearly archive
+func isYear(segment string) bool {
+	_, err := strconv.Atoi(segment)
+	return len(segment) == 4 && err == nil
+}
+
+// lineOf finds the line of the next use of a destination in the document
+// body, so repeated links are placed at successive uses
+func (d *document) lineOf(destination string) int {
+	from := d.searched[destination]
+	if from == 0 {
+		from = d.bodyStart
+	}
+	line, end := lineOf(d.content, from, destination)
+	if line == 0 {
+		// Escaped or reference-style, place it at the start of the body
+		return bytes.Count(d.content[:d.bodyStart], []byte("\n")) + 1
+	}
+	d.searched[destination] = end
+	return line
+}
+
+// lineOf returns the 1-based line of the first occurrence of needle in
+// content at or after offset and the offset just past it, or 0 when needle
+// doesn't occur
+func lineOf(content []byte, offset int, needle string) (int, int) {
+	i := bytes.Index(content[offset:], []byte(needle))
+	if i < 0 {
+		return 0, offset
+	}
+	i += offset
+	return bytes.Count(content[:i], []byte("\n")) + 1, i + len(needle)
+}
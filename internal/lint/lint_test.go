@@ -0,0 +1,120 @@
+package lint
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const blogYAML = `blog:
+  title: "Test"
+  tag_filters:
+    - display: "Go"
+      tag: "golang"
+      aliases: ["go"]
+`
+
+func TestCheck(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/site.yml":         {Data: []byte("site:\n  name: Test\n  colour: red\n")},
+		"content/site.es.yml":      {Data: []byte("site:\n  tagline: Hola\n  taglin: Hola\n")},
+		"content/blog.yml":         {Data: []byte(blogYAML)},
+		"content/blog/good.md":     {Data: []byte("---\ntitle: Good\ndate: 2024-01-02\ntags: [go]\n---\n\n## Setup\n\nSee [the other post](/blog/other#usage), [setup](#setup) and [the page](/pages/about).\n\n![Diagram](diagram.svg)\n")},
+		"content/blog/diagram.svg": {Data: []byte("<svg/>")},
+		"content/blog/corrupt.md":  {Data: []byte("---\ntitle: Corrupt\ndate: 2024-01-03\n---\n\n![Chart](chart.png)\n")},
+		"content/blog/chart.png":   {Data: []byte("not a png")},
+		"content/blog/other.md":    {Data: []byte("---\ntitle: Other\ndate: 2024-01-01\ntags:\n  - golang\n  - Rust\n---\n\n## Install\n\nSee [missing](/blog/nope), [usage](#usage)\nand [usage again](/blog/good#nowhere).\n\n![](/static/missing.png)\n")},
+		"content/blog/cover.md":    {Data: []byte("---\ntitle: Cover\ndate: 2024-01-04\nimage: cover.png\n---\n\nBody\n")},
+		"content/blog/search.md":   {Data: []byte("---\ntitle: Search\n---\n\nBody\n")},
+		"content/blog/2024.md":     {Data: []byte("---\ntitle: Year\ndate: 2024-01-05\n---\n\nBody\n")},
+		"content/blog/broken.md":   {Data: []byte("---\ntitle: Broken\ndate: x: y\n---\n")},
+		"content/pages/about.md":   {Data: []byte("# About\n\n[Download](/static/cv.pdf)\n")},
+		"content/about.md":         {Data: []byte("[Good](/blog/good#setup) and [Gone](/pages/gone)\n")},
+		"static/cv.pdf":            {Data: []byte("%PDF")},
+	}
+
+	problems, err := Check(fsys)
+	require.NoError(t, err)
+
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	assert.Equal(t, []string{
+		`content/about.md:1: broken link /pages/gone, no page "gone"`,
+		`content/blog/2024.md:1: slug "2024" is taken by the /blog/2024/ archive`,
+		`content/blog/broken.md:3: invalid frontmatter: mapping values are not allowed in this context`,
+		`content/blog/corrupt.md:1: invalid post content: decode content/blog/chart.png: image: unknown format`,
+		`content/blog/cover.md:4: image cover.png not found`,
+		`content/blog/good.md:9: broken link /blog/other#usage, no such heading in content/blog/other.md`,
+		`content/blog/other.md:6: unknown tag "Rust", not in the blog.yml tag filters`,
+		`content/blog/other.md:11: broken link /blog/nope, no post "nope"`,
+		`content/blog/other.md:11: broken link #usage, no such heading`,
+		`content/blog/other.md:12: broken link /blog/good#nowhere, no such heading in content/blog/good.md`,
+		`content/blog/other.md:14: image /static/missing.png has no alt text`,
+		`content/blog/other.md:14: image /static/missing.png not found`,
+		`content/blog/search.md:1: slug "search" is taken by the /blog/search route`,
+		`content/blog/search.md:1: missing date`,
+		`content/site.es.yml:3: unknown key "taglin" (not in config.SiteInfo)`,
+		`content/site.yml:3: unknown key "colour" (not in config.SiteInfo)`,
+	}, got)
+}
+
+func TestCheckDuplicateSlugs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/site.yml":      {Data: []byte("site:\n  name: Test\n")},
+		"content/blog/Post.md":  {Data: []byte("---\ntitle: One\ndate: 2024-01-01\n---\n")},
+		"content/blog/post.md":  {Data: []byte("---\ntitle: Two\ndate: 2024-01-02\n---\n")},
+		"content/blog/other.md": {Data: []byte("---\ndate: 2024-01-03\n---\n")},
+	}
+
+	problems, err := Check(fsys)
+	require.NoError(t, err)
+	assert.Equal(t, []Problem{
+		{File: "content/blog/other.md", Line: 1, Message: "missing title"},
+		{File: "content/blog/post.md", Line: 1, Message: `duplicate slug "post", also used by content/blog/Post.md`},
+	}, problems)
+}
+
+func TestCheckTranslations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/site.yml":          {Data: []byte("site:\n  name: Test\n")},
+		"content/i18n/es.yml":       {Data: []byte("name: Español\n")},
+		"content/blog/post.md":      {Data: []byte("---\ntitle: Post\ndate: 2024-01-01\n---\nSee [the other](/blog/other).\n")},
+		"content/blog/post.es.md":   {Data: []byte("---\ntitle: Entrada\n---\n")},
+		"content/blog/post.fr.md":   {Data: []byte("---\ntitle: Article\ndate: 2024-01-01\n---\n")},
+		"content/blog/other.es.md":  {Data: []byte("---\ntitle: Solo en español\ndate: 2024-01-02\n---\n")},
+		"content/blog/release.1.md": {Data: []byte("---\ntitle: Release\ndate: 2024-01-03\n---\n")},
+	}
+
+	problems, err := Check(fsys)
+	require.NoError(t, err)
+	assert.Equal(t, []Problem{
+		{File: "content/blog/post.fr.md", Line: 1, Message: `served as post "post.fr", add content/i18n/fr.yml to publish it as a translation`},
+	}, problems)
+}
+
+func TestCheckAuthors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/site.yml":       {Data: []byte("site:\n  name: Test\n")},
+		"content/authors.yml":    {Data: []byte("authors:\n  - id: ada\n    name: Ada\n  - id: ada\n    name: Ada Again\n  - id: grace\n    twitter: grace\n")},
+		"content/blog/shared.md": {Data: []byte("---\ntitle: Shared\ndate: 2024-01-01\nauthors:\n  - ada\n  - grcae\n  - Sam Guest\n---\n")},
+		"content/blog/guest.md":  {Data: []byte("---\ntitle: Guest\ndate: 2024-01-02\nauthor: Sam Guest\n---\n")},
+	}
+
+	problems, err := Check(fsys)
+	require.NoError(t, err)
+
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	assert.Equal(t, []string{
+		`content/authors.yml:4: duplicate author id "ada"`,
+		`content/authors.yml:6: author "grace" has no name`,
+		`content/authors.yml:7: unknown key "twitter" (not in blog.Author)`,
+		`content/blog/shared.md:6: unknown author "grcae", not in authors.yml`,
+	}, got)
+}
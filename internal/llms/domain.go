@@ -0,0 +1,30 @@
+package llms
+
+import (
+	"time"
+)
+
+// Index is a map of the site for language models, served as /llms.txt and,
+// with the full text of every document, as /llms-full.txt. See
+// https://llmstxt.org for the format.
+type Index struct {
+	Title    string
+	Summary  string // One-line description shown as a blockquote under the title
+	Details  string // Markdown between the summary and the sections, empty for none
+	Sections []Section
+}
+
+// Section is a titled list of links in the index
+type Section struct {
+	Title string
+	Links []Link
+}
+
+// Link is a document listed in the index
+type Link struct {
+	Title string
+	URL   string    // Absolute URL, the markdown source where there is one
+	Notes string    // Short description after the link, empty for none
+	Date  time.Time // Publication date, zero when it has none
+	Body  string    // Full markdown for llms-full.txt, empty to leave the document out
+}
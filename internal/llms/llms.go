@@ -0,0 +1,250 @@
+package llms
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"blockhead.consulting/internal/blog"
+	"blockhead.consulting/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// frontmatter is the normalized frontmatter of a post served as markdown:
+// the fields readers need in a fixed order, with defaults resolved
+type frontmatter struct {
+	Title        string   `yaml:"title"`
+	Date         string   `yaml:"date,omitempty"`
+	Updated      string   `yaml:"updated,omitempty"`
+	Summary      string   `yaml:"summary,omitempty"`
+	Description  string   `yaml:"description,omitempty"`
+	Tags         []string `yaml:"tags,omitempty"`
+	Authors      []string `yaml:"authors,omitempty"`
+	Series       string   `yaml:"series,omitempty"`
+	SeriesOrder  int      `yaml:"seriesOrder,omitempty"`
+	Lang         string   `yaml:"lang,omitempty"`
+	Translations []string `yaml:"translations,omitempty"`
+	URL          string   `yaml:"url"`
+	Canonical    string   `yaml:"canonical,omitempty"`
+}
+
+// Post returns the markdown source of a post with normalized frontmatter.
+// url is the absolute URL of the post's page; site-relative links in the
+// body are made absolute against baseURL.
+func Post(post blog.Post, baseURL, url string) ([]byte, error) {
+	fm := frontmatter{
+		Title:        post.Title,
+		Summary:      post.Summary,
+		Tags:         post.Tags,
+		Series:       post.Series,
+		SeriesOrder:  post.SeriesOrder,
+		Lang:         post.Lang,
+		Translations: post.Translations,
+		URL:          url,
+		Canonical:    post.Canonical,
+	}
+	if !post.Date.IsZero() {
+		fm.Date = post.Date.Format("2006-01-02")
+	}
+	if post.WasUpdated() {
+		fm.Updated = post.Updated.Format("2006-01-02")
+	}
+	if post.Description != post.Summary {
+		fm.Description = post.Description
+	}
+	for _, author := range post.Authors {
+		fm.Authors = append(fm.Authors, author.Name)
+	}
+
+	header, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode frontmatter of %s: %w", post.Slug, err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.Write(header)
+	buf.WriteString("---\n\n")
+	buf.WriteString(strings.TrimSpace(AbsoluteLinks(post.Markdown, baseURL)))
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// PostLinks lists posts for the index, linking each to its markdown source
+// at baseURL+path(post)+".md"
+func PostLinks(posts []blog.Post, baseURL string, path func(blog.Post) string) []Link {
+	links := make([]Link, 0, len(posts))
+	for _, post := range posts {
+		links = append(links, Link{
+			Title: post.Title,
+			URL:   strings.TrimRight(baseURL, "/") + path(post) + ".md",
+			Notes: post.Summary,
+			Date:  post.Date,
+			Body:  AbsoluteLinks(post.Markdown, baseURL),
+		})
+	}
+	return links
+}
+
+// Work renders the work history of work.yml as markdown
+func Work(work *config.WorkConfig) string {
+	if work == nil {
+		return ""
+	}
+
+	var buf strings.Builder
+	paragraph(&buf, work.Intro)
+	for _, section := range []config.WorkSection{work.FinTech, work.Blockchain, work.AI} {
+		if section.Title == "" {
+			continue
+		}
+		fmt.Fprintf(&buf, "## %s\n\n", section.Title)
+		paragraph(&buf, section.Description)
+
+		for _, company := range section.Companies {
+			entry(&buf, company.Name, company.Role, company.Duration, company.Summary)
+			if details := company.DetailedInfo; details != nil {
+				paragraph(&buf, details.Description)
+				projects(&buf, details.KeyProjects)
+				list(&buf, details.Achievements)
+			}
+		}
+		for _, project := range section.Projects {
+			entry(&buf, project.Name, project.Role, project.Duration, project.Summary)
+			if project.Link != "" {
+				fmt.Fprintf(&buf, "Link: %s\n\n", project.Link)
+			}
+			if details := project.DetailedInfo; details != nil {
+				paragraph(&buf, details.Description)
+				projects(&buf, details.KeyFeatures)
+				projects(&buf, details.KeyContributions)
+				list(&buf, details.Achievements)
+			}
+		}
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// entry writes the heading and summary of a company or project
+func entry(buf *strings.Builder, name, role, duration, summary string) {
+	fmt.Fprintf(buf, "### %s\n\n", name)
+	var facts []string
+	for _, fact := range []string{role, duration} {
+		if fact = strings.TrimSpace(fact); fact != "" {
+			facts = append(facts, fact)
+		}
+	}
+	if len(facts) > 0 {
+		fmt.Fprintf(buf, "*%s*\n\n", strings.Join(facts, ", "))
+	}
+	paragraph(buf, summary)
+}
+
+// projects writes project details as a list
+func projects(buf *strings.Builder, details []config.ProjectDetail) {
+	var items []string
+	for _, d := range details {
+		item := "**" + d.Name + "**"
+		if d.Description != "" {
+			item += ": " + strings.TrimSpace(d.Description)
+		}
+		if d.Impact != "" {
+			item += " Impact: " + strings.TrimSpace(d.Impact)
+		}
+		if len(d.Technologies) > 0 {
+			item += " (" + strings.Join(d.Technologies, ", ") + ")"
+		}
+		items = append(items, item)
+	}
+	list(buf, items)
+}
+
+func paragraph(buf *strings.Builder, text string) {
+	if text = strings.TrimSpace(text); text != "" {
+		buf.WriteString(text + "\n\n")
+	}
+}
+
+func list(buf *strings.Builder, items []string) {
+	for _, item := range items {
+		buf.WriteString("- " + strings.TrimSpace(item) + "\n")
+	}
+	if len(items) > 0 {
+		buf.WriteString("\n")
+	}
+}
+
+// Text renders the index as llms.txt: the title, summary and details
+// followed by each section's links
+func (i *Index) Text() []byte {
+	var buf bytes.Buffer
+	i.header(&buf)
+	for _, section := range i.Sections {
+		if len(section.Links) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "## %s\n\n", section.Title)
+		for _, link := range section.Links {
+			fmt.Fprintf(&buf, "- [%s](%s)", link.Title, link.URL)
+			if notes := oneLine(link.Notes); notes != "" {
+				buf.WriteString(": " + notes)
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("\n")
+	}
+	return append(bytes.TrimRight(buf.Bytes(), "\n"), '\n')
+}
+
+// Full renders the index as llms-full.txt: the header followed by the full
+// text of every linked document that has a body, in section order
+func (i *Index) Full() []byte {
+	var buf bytes.Buffer
+	i.header(&buf)
+	for _, section := range i.Sections {
+		for _, link := range section.Links {
+			body := strings.TrimSpace(link.Body)
+			if body == "" {
+				continue
+			}
+			fmt.Fprintf(&buf, "---\n\n# %s\n\nURL: %s\n", link.Title, link.URL)
+			if !link.Date.IsZero() {
+				fmt.Fprintf(&buf, "Published: %s\n", link.Date.Format("2006-01-02"))
+			}
+			buf.WriteString("\n" + body + "\n\n")
+		}
+	}
+	return append(bytes.TrimRight(buf.Bytes(), "\n"), '\n')
+}
+
+// header writes the title, summary and details both documents start with
+func (i *Index) header(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "# %s\n\n", i.Title)
+	if summary := oneLine(i.Summary); summary != "" {
+		fmt.Fprintf(buf, "> %s\n\n", summary)
+	}
+	if details := strings.TrimSpace(i.Details); details != "" {
+		buf.WriteString(details + "\n\n")
+	}
+}
+
+// oneLine collapses whitespace so text fits on a line of the index
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// rootRelativeLink matches the destination of markdown links and images
+// pointing at site-relative paths
+var rootRelativeLink = regexp.MustCompile(`(\]\()/([^/)\s][^)\s]*)?([)\s])`)
+
+// AbsoluteLinks rewrites site-relative link and image destinations in
+// markdown to absolute URLs, so the source reads the same wherever it's
+// fetched from
+func AbsoluteLinks(markdown, baseURL string) string {
+	baseURL = strings.TrimRight(baseURL, "/")
+	if baseURL == "" {
+		return markdown
+	}
+	return rootRelativeLink.ReplaceAllString(markdown, `${1}`+baseURL+`/${2}${3}`)
+}
@@ -0,0 +1,154 @@
+package llms
+
+import (
+	"testing"
+	"time"
+
+	"blockhead.consulting/internal/blog"
+	"blockhead.consulting/internal/config"
+	"blockhead.consulting/internal/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPost(t *testing.T) {
+	post := blog.Post{
+		Slug:        "hello",
+		Title:       "Hello: World",
+		Date:        time.Date(2025, 6, 1, 9, 30, 0, 0, time.UTC),
+		Summary:     "A first post",
+		Description: "A first post",
+		Tags:        []string{"golang"},
+		Authors:     []blog.Author{{ID: "ada", Name: "Ada", Registered: true}},
+		Lang:        "en",
+		Updated:     time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+		Revisions:   []history.Revision{{}, {}},
+		Markdown:    "\n## Setup\n\nSee [the other post](/blog/other \"Other\"), ![chart](/static/chart.png) and [Go](https://go.dev).\n",
+	}
+
+	got, err := Post(post, "https://example.com/", "https://example.com/blog/hello")
+	require.NoError(t, err)
+	assert.Equal(t, `---
+title: 'Hello: World'
+date: "2025-06-01"
+updated: "2025-07-01"
+summary: A first post
+tags:
+    - golang
+authors:
+    - Ada
+lang: en
+url: https://example.com/blog/hello
+---
+
+## Setup
+
+See [the other post](https://example.com/blog/other "Other"), ![chart](https://example.com/static/chart.png) and [Go](https://go.dev).
+`, string(got))
+}
+
+func TestIndex(t *testing.T) {
+	posts := []blog.Post{
+		{Slug: "newer", Title: "Newer", Summary: "The newer\none", Date: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), Markdown: "Body of [newer](/blog/older).\n"},
+		{Slug: "older", Title: "Older", Markdown: "Body of older.\n"},
+	}
+	index := &Index{
+		Title:   "Example",
+		Summary: "An example site",
+		Sections: []Section{
+			{Title: "Pages", Links: []Link{{Title: "About", URL: "https://example.com/about", Body: "About me."}}},
+			{Title: "Blog", Links: PostLinks(posts, "https://example.com", func(post blog.Post) string { return "/blog/" + post.Slug })},
+			{Title: "Empty"},
+		},
+	}
+
+	assert.Equal(t, `# Example
+
+> An example site
+
+## Pages
+
+- [About](https://example.com/about)
+
+## Blog
+
+- [Newer](https://example.com/blog/newer.md): The newer one
+- [Older](https://example.com/blog/older.md)
+`, string(index.Text()))
+
+	assert.Equal(t, `# Example
+
+> An example site
+
+---
+
+# About
+
+URL: https://example.com/about
+
+About me.
+
+---
+
+# Newer
+
+URL: https://example.com/blog/newer.md
+Published: 2025-06-01
+
+Body of [newer](https://example.com/blog/older).
+
+---
+
+# Older
+
+URL: https://example.com/blog/older.md
+
+Body of older.
+`, string(index.Full()))
+}
+
+func TestWork(t *testing.T) {
+	work := &config.WorkConfig{
+		Intro: "What I've done.",
+		FinTech: config.WorkSection{
+			Title: "FinTech",
+			Companies: []config.WorkCompany{{
+				Name:     "Bank",
+				Role:     "Engineer",
+				Duration: "2020-2022",
+				Summary:  "Built payments.",
+				DetailedInfo: &config.WorkCompanyDetails{
+					KeyProjects:  []config.ProjectDetail{{Name: "Ledger", Description: "Double entry.", Technologies: []string{"Go"}}},
+					Achievements: []string{"Shipped"},
+				},
+			}},
+		},
+		AI: config.WorkSection{
+			Title:    "AI",
+			Projects: []config.WorkProject{{Name: "Agent", Summary: "An agent.", Link: "https://example.com/agent"}},
+		},
+	}
+
+	assert.Equal(t, `What I've done.
+
+## FinTech
+
+### Bank
+
+*Engineer, 2020-2022*
+
+Built payments.
+
+- **Ledger**: Double entry. (Go)
+
+- Shipped
+
+## AI
+
+### Agent
+
+An agent.
+
+Link: https://example.com/agent`, Work(work))
+	assert.Empty(t, Work(nil))
+}
@@ -0,0 +1,408 @@
+// Package ogimage draws the social preview images, or OpenGraph cards, of
+// blog posts: the title, date and tags in the site's colors next to its
+// logo, set in the Go fonts compiled into the binary.
+package ogimage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // registers JPEG logos
+	"image/png"
+	"io/fs"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Size of the cards, the 1.91:1 ratio OpenGraph and Twitter cards expect
+const (
+	Width  = 1200
+	Height = 630
+)
+
+// version is part of every key, bump it when the layout changes so cached
+// cards are redrawn
+const version = "1"
+
+// Layout in pixels
+const (
+	margin      = 80
+	accentWidth = 12
+	logoHeight  = 72
+	maxLines    = 3 // of the title
+)
+
+// Colors of the site's dark theme, used for what isn't branded
+var (
+	background = color.RGBA{0x0a, 0x0a, 0x0a, 0xff}
+	titleColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	mutedColor = color.RGBA{0xa8, 0xa8, 0xa8, 0xff}
+
+	defaultPrimary   = color.RGBA{0x00, 0xff, 0x88, 0xff}
+	defaultSecondary = color.RGBA{0x00, 0xd4, 0xff, 0xff}
+)
+
+// titleSizes are tried in order until the title fits in maxLines
+var titleSizes = []float64{72, 60, 52}
+
+// The embedded fonts, parsed once
+var (
+	boldFont    = mustParse(gobold.TTF)
+	regularFont = mustParse(goregular.TTF)
+)
+
+// Options configures a Generator
+type Options struct {
+	SiteName string
+
+	// Logo is a PNG or JPEG drawn above the title. "/static/..." paths
+	// resolve against Static. SVG logos can't be drawn and are skipped.
+	Logo   string
+	Static fs.FS
+
+	// PrimaryColor and SecondaryColor are CSS hex colors, the site's
+	// green and blue when empty or invalid
+	PrimaryColor   string
+	SecondaryColor string
+
+	Logger *log.Logger
+}
+
+// Card is what a preview image shows
+type Card struct {
+	Title string
+	Date  time.Time
+	Tags  []string
+}
+
+// Generator draws cards and keeps them by content hash, so a card is only
+// drawn again when something it shows changes
+type Generator struct {
+	opts      Options
+	logo      image.Image
+	primary   color.RGBA
+	secondary color.RGBA
+	style     string // digest of everything besides the card that shows
+
+	mu    sync.Mutex
+	cards map[string][]byte // key to PNG
+}
+
+// New creates a generator. Problems with the logo or colors are logged and
+// the card is drawn without them.
+func New(opts Options) *Generator {
+	if opts.Logger == nil {
+		opts.Logger = log.Default()
+	}
+	g := &Generator{
+		opts:      opts,
+		primary:   parseColor(opts.PrimaryColor, defaultPrimary, opts.Logger),
+		secondary: parseColor(opts.SecondaryColor, defaultSecondary, opts.Logger),
+		cards:     make(map[string][]byte),
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%v\x00%v\x00", version, opts.SiteName, g.primary, g.secondary)
+	if data := g.loadLogo(); data != nil {
+		hash.Write(data)
+	}
+	g.style = hex.EncodeToString(hash.Sum(nil))
+	return g
+}
+
+// loadLogo decodes the logo and returns its file contents, or nil when
+// there's no logo to draw
+func (g *Generator) loadLogo() []byte {
+	name := g.opts.Logo
+	if name == "" || g.opts.Static == nil {
+		return nil
+	}
+	if strings.EqualFold(path.Ext(name), ".svg") {
+		g.opts.Logger.Printf("OGIMAGE: Logo %s is SVG, drawing cards without it", name)
+		return nil
+	}
+	name = strings.TrimPrefix(strings.TrimPrefix(name, "/"), "static/")
+	data, err := fs.ReadFile(g.opts.Static, name)
+	if err != nil {
+		g.opts.Logger.Printf("OGIMAGE: Warning - logo %s: %v", g.opts.Logo, err)
+		return nil
+	}
+	logo, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		g.opts.Logger.Printf("OGIMAGE: Warning - decode logo %s: %v", g.opts.Logo, err)
+		return nil
+	}
+	g.logo = logo
+	return data
+}
+
+// CanDraw reports whether the embedded fonts have a glyph for every letter
+// of text. They cover Latin, Greek and Cyrillic but not CJK, which would be
+// drawn as boxes.
+func CanDraw(text string) bool {
+	var buf sfnt.Buffer
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		if index, err := boldFont.GlyphIndex(&buf, r); err != nil || index == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Key returns the content hash a card is stored under, usable as an ETag
+func (g *Generator) Key(card Card) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%s\x00", g.style, card.Title, card.Date.Format(time.DateOnly))
+	for _, tag := range card.Tags {
+		fmt.Fprintf(hash, "%s\x00", tag)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Render returns a card as PNG, drawing it unless it's stored
+func (g *Generator) Render(card Card) ([]byte, error) {
+	key := g.Key(card)
+	g.mu.Lock()
+	data, ok := g.cards[key]
+	g.mu.Unlock()
+	if ok {
+		return data, nil
+	}
+
+	data, err := g.draw(card)
+	if err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	g.cards[key] = data
+	g.mu.Unlock()
+	return data, nil
+}
+
+// Generate draws the cards of a set of posts ahead of requests. Unchanged
+// cards are kept, cards that aren't in the set any more are dropped.
+func (g *Generator) Generate(cards []Card) {
+	next := make(map[string][]byte, len(cards))
+	drawn := 0
+	for _, card := range cards {
+		key := g.Key(card)
+		g.mu.Lock()
+		data, ok := g.cards[key]
+		g.mu.Unlock()
+		if !ok {
+			var err error
+			if data, err = g.draw(card); err != nil {
+				g.opts.Logger.Printf("OGIMAGE: Failed to draw card for %q: %v", card.Title, err)
+				continue
+			}
+			drawn++
+		}
+		next[key] = data
+	}
+
+	g.mu.Lock()
+	g.cards = next
+	g.mu.Unlock()
+	g.opts.Logger.Printf("OGIMAGE: %d cards ready, %d drawn", len(next), drawn)
+}
+
+// draw lays out and encodes a card
+func (g *Generator) draw(card Card) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	// Accent bar down the left edge, fading from primary to secondary
+	for y := 0; y < Height; y++ {
+		c := blend(g.primary, g.secondary, float64(y)/float64(Height-1))
+		draw.Draw(img, image.Rect(0, y, accentWidth, y+1), image.NewUniform(c), image.Point{}, draw.Src)
+	}
+
+	// Logo and site name
+	top := margin - 20
+	x := margin
+	if g.logo != nil {
+		b := g.logo.Bounds()
+		width := b.Dx() * logoHeight / b.Dy()
+		xdraw.CatmullRom.Scale(img, image.Rect(x, top, x+width, top+logoHeight), g.logo, b, draw.Over, nil)
+		x += width + 28
+	}
+	if g.opts.SiteName != "" {
+		face, err := newFace(boldFont, 32)
+		if err != nil {
+			return nil, err
+		}
+		drawText(img, face, g.secondary, x, top+logoHeight/2+12, fit(face, strings.ToUpper(g.opts.SiteName), Width-margin-x))
+		face.Close()
+	}
+
+	// Title, in the largest size that fits
+	textWidth := Width - 2*margin
+	var (
+		face  font.Face
+		lines []string
+		size  float64
+	)
+	for _, size = range titleSizes {
+		if face != nil {
+			face.Close()
+		}
+		var err error
+		if face, err = newFace(boldFont, size); err != nil {
+			return nil, err
+		}
+		if lines = wrap(face, card.Title, textWidth); len(lines) <= maxLines {
+			break
+		}
+	}
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+		lines[maxLines-1] = fit(face, lines[maxLines-1]+"…", textWidth)
+	}
+	lineHeight := int(size * 1.2)
+	y := top + logoHeight + 70 + int(size)
+	for _, line := range lines {
+		drawText(img, face, titleColor, margin, y, line)
+		y += lineHeight
+	}
+	face.Close()
+
+	// Date and tags along the bottom
+	face, err := newFace(regularFont, 30)
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+	x, y = margin, Height-margin+10
+	if !card.Date.IsZero() {
+		date := card.Date.Format("January 2, 2006")
+		drawText(img, face, mutedColor, x, y, date)
+		x += font.MeasureString(face, date+"   ").Round()
+	}
+	for _, tag := range card.Tags {
+		label := "#" + strings.ToLower(strings.ReplaceAll(tag, " ", ""))
+		width := font.MeasureString(face, label).Round()
+		if x+width > Width-margin {
+			break
+		}
+		drawText(img, face, g.primary, x, y, label)
+		x += width + font.MeasureString(face, "  ").Round()
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode card: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// wrap breaks text into lines no wider than width, shortening words that
+// don't fit on a line of their own
+func wrap(face font.Face, text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if font.MeasureString(face, candidate).Round() <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = fit(face, word, width)
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fit shortens text with an ellipsis until it's no wider than width
+func fit(face font.Face, text string, width int) string {
+	if font.MeasureString(face, text).Round() <= width {
+		return text
+	}
+	runes := []rune(strings.TrimSuffix(text, "…"))
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		shortened := strings.TrimRight(string(runes), " ,.;:-") + "…"
+		if font.MeasureString(face, shortened).Round() <= width {
+			return shortened
+		}
+	}
+	return ""
+}
+
+// drawText draws text with its baseline at y
+func drawText(img draw.Image, face font.Face, c color.Color, x, y int, text string) {
+	d := font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(text)
+}
+
+// newFace returns a face of a font at a pixel size. Faces aren't safe for
+// concurrent use, so every card gets its own.
+func newFace(f *opentype.Font, size float64) (font.Face, error) {
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("load font: %w", err)
+	}
+	return face, nil
+}
+
+// blend mixes two colors, t from 0 (all a) to 1 (all b)
+func blend(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t + 0.5)
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 0xff}
+}
+
+// parseColor parses a #rgb or #rrggbb color, logging and returning
+// fallback when it isn't one
+func parseColor(value string, fallback color.RGBA, logger *log.Logger) color.RGBA {
+	if value == "" {
+		return fallback
+	}
+	hexDigits := strings.TrimPrefix(value, "#")
+	if len(hexDigits) == 3 {
+		hexDigits = string([]byte{hexDigits[0], hexDigits[0], hexDigits[1], hexDigits[1], hexDigits[2], hexDigits[2]})
+	}
+	n, err := strconv.ParseUint(hexDigits, 16, 32)
+	if len(hexDigits) != 6 || err != nil {
+		logger.Printf("OGIMAGE: Warning - invalid color %q, using the default", value)
+		return fallback
+	}
+	return color.RGBA{uint8(n >> 16), uint8(n >> 8), uint8(n), 0xff}
+}
+
+// mustParse parses an embedded font
+func mustParse(ttf []byte) *opentype.Font {
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		panic(fmt.Sprintf("ogimage: parse embedded font: %v", err))
+	}
+	return f
+}
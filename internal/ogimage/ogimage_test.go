@@ -0,0 +1,107 @@
+package ogimage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"log"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLogo(t *testing.T) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			img.Set(x, y, color.RGBA{0xff, 0x00, 0x00, 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func testGenerator(t *testing.T) *Generator {
+	return New(Options{
+		SiteName:     "Example",
+		Logo:         "/static/logo.png",
+		Static:       fstest.MapFS{"logo.png": {Data: testLogo(t)}},
+		PrimaryColor: "#0f8",
+		Logger:       log.New(io.Discard, "", 0),
+	})
+}
+
+var testCard = Card{
+	Title: "A title long enough that it has to wrap over more than one line of the card, and then some more words so it needs a smaller size",
+	Date:  time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	Tags:  []string{"Go", "Open Source"},
+}
+
+func TestRender(t *testing.T) {
+	g := testGenerator(t)
+
+	data, err := g.Render(testCard)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, Width, Height), img.Bounds())
+
+	// The accent bar fades from the primary color, the logo is drawn red
+	assert.Equal(t, color.RGBA{0x00, 0xff, 0x88, 0xff}, color.RGBAModel.Convert(img.At(0, 0)))
+	r, gr, b, _ := img.At(margin+10, margin-20+logoHeight/2).RGBA()
+	assert.Equal(t, []uint32{0xffff, 0, 0}, []uint32{r, gr, b})
+
+	again, err := g.Render(testCard)
+	require.NoError(t, err)
+	assert.Same(t, &data[0], &again[0], "unchanged cards are served from the cache")
+}
+
+func TestKey(t *testing.T) {
+	g := testGenerator(t)
+	key := g.Key(testCard)
+	assert.Len(t, key, 64)
+	assert.Equal(t, key, g.Key(testCard))
+
+	changed := testCard
+	changed.Tags = []string{"Go"}
+	assert.NotEqual(t, key, g.Key(changed))
+
+	// The style is part of the key, so rebranding redraws every card
+	other := New(Options{SiteName: "Other", Logger: log.New(io.Discard, "", 0)})
+	assert.NotEqual(t, key, other.Key(testCard))
+}
+
+func TestGenerate(t *testing.T) {
+	g := testGenerator(t)
+	stale := Card{Title: "Old title"}
+	_, err := g.Render(stale)
+	require.NoError(t, err)
+
+	g.Generate([]Card{testCard})
+	assert.Len(t, g.cards, 1)
+	assert.Contains(t, g.cards, g.Key(testCard))
+	assert.NotContains(t, g.cards, g.Key(stale))
+}
+
+func TestLogoFallback(t *testing.T) {
+	g := New(Options{
+		Logo:   "/static/logo.svg",
+		Static: fstest.MapFS{"logo.svg": {Data: []byte("<svg/>")}},
+		Logger: log.New(io.Discard, "", 0),
+	})
+	assert.Nil(t, g.logo)
+	_, err := g.Render(testCard)
+	assert.NoError(t, err)
+}
+
+func TestCanDraw(t *testing.T) {
+	assert.True(t, CanDraw("Déjà vu: Ελληνικά, русский — 100%"))
+	assert.False(t, CanDraw("スマートコントラクト監査"))
+	assert.False(t, CanDraw("Go 入門"))
+}
@@ -13,7 +13,8 @@ type Page struct {
 	Subtitle string
 	Content  template.HTML
 	Meta     map[string]interface{} // For custom frontmatter fields
-	LastMod  time.Time
+	Created  time.Time              // First commit of the file, zero outside git
+	LastMod  time.Time              // Last commit, or the file's modification time outside git
 }
 
 // Service defines the pages service interface
@@ -6,33 +6,51 @@ import (
 	"html/template"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
-	"github.com/yuin/goldmark"
-	"github.com/yuin/goldmark/extension"
-	"github.com/yuin/goldmark/parser"
-	"github.com/yuin/goldmark/renderer/html"
-	"github.com/yuin/goldmark/text"
-	"go.abhg.dev/goldmark/frontmatter"
+	"blockhead.consulting/internal/history"
+	"blockhead.consulting/internal/render"
+	"gopkg.in/yaml.v3"
 )
 
 // service implements the pages service using file storage
 type service struct {
 	contentDir string
 	logger     *log.Logger
+	renderer   *render.Renderer
+	history    history.Provider
 }
 
-// NewService creates a new pages service
-func NewService(contentDir string, logger *log.Logger) Service {
+// Option configures the pages service
+type Option func(*service)
+
+// WithHistory dates pages from their history instead of their modification time
+func WithHistory(provider history.Provider) Option {
+	return func(s *service) {
+		s.history = provider
+	}
+}
+
+// NewService creates a new pages service. A nil renderer uses the site defaults.
+func NewService(contentDir string, logger *log.Logger, renderer *render.Renderer, opts ...Option) Service {
 	if contentDir == "" {
 		contentDir = "content/pages"
 	}
-	return &service{
+	if renderer == nil {
+		renderer = render.Site()
+	}
+	s := &service{
 		contentDir: contentDir,
 		logger:     logger,
+		renderer:   renderer,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // GetPage returns a page by slug
@@ -74,42 +92,44 @@ func (s *service) loadPage(ctx context.Context, filename, slug string) (*Page, e
 		s.logger.Printf("PAGES: Error reading %s: %v", filePath, err)
 		return nil, fmt.Errorf("failed to read page file %s: %w", filePath, err)
 	}
+	
+	// Prefer git history, falling back to the file modification time
+	var created time.Time
+	lastMod := time.Now()
+	if info, err := os.Stat(filePath); err == nil {
+		lastMod = info.ModTime()
+	}
+	if s.history != nil {
+		revisions := s.history.Lookup(ctx, filePath)
+		created = revisions.Created
+		if !revisions.Updated.IsZero() {
+			lastMod = revisions.Updated
+		}
+	}
 
-	// Parse frontmatter and markdown
-	md := goldmark.New(
-		goldmark.WithExtensions(
-			extension.GFM,
-			&frontmatter.Extender{},
-		),
-		goldmark.WithParserOptions(
-			parser.WithAutoHeadingID(),
-		),
-		goldmark.WithRendererOptions(
-			html.WithHardWraps(),
-			html.WithXHTML(),
-		),
-	)
-
-	// Create parser context and parse the document
-	parseCtx := parser.NewContext()
-	reader := text.NewReader(content)
-	doc := md.Parser().Parse(reader, parser.WithContext(parseCtx))
+	// Split frontmatter from markdown, a page without frontmatter is all markdown
+	frontmatterBytes, markdownContent, err := render.SplitFrontmatter(content)
+	if err != nil && err != render.ErrNoFrontmatter {
+		s.logger.Printf("PAGES: Warning - %v in %s", err, filename)
+	}
 
 	// Extract frontmatter with flexible structure
 	var frontMatter map[string]interface{}
-	if d := frontmatter.Get(parseCtx); d != nil {
-		if err := d.Decode(&frontMatter); err != nil {
+	if len(frontmatterBytes) > 0 {
+		if err := yaml.Unmarshal(frontmatterBytes, &frontMatter); err != nil {
 			s.logger.Printf("PAGES: Warning - failed to decode frontmatter in %s: %v", filename, err)
-			frontMatter = make(map[string]interface{})
+			frontMatter = nil
 		}
-	} else {
+	}
+	if frontMatter == nil {
 		frontMatter = make(map[string]interface{})
 	}
 
 	// Convert markdown to HTML
-	var buf strings.Builder
-	if err := md.Renderer().Render(&buf, content, doc); err != nil {
-		return nil, fmt.Errorf("failed to render markdown: %w", err)
+	rendered := s.renderer.WithSource(os.DirFS(s.contentDir), path.Dir(filename)).Render(markdownContent)
+	if err := rendered.Err(); err != nil {
+		s.logger.Printf("PAGES: Error rendering %s: %v", filename, err)
+		return nil, fmt.Errorf("invalid page content in %s: %w", filename, err)
 	}
 
 	// Extract standard fields with fallbacks
@@ -127,9 +147,10 @@ func (s *service) loadPage(ctx context.Context, filename, slug string) (*Page, e
 		Slug:     slug,
 		Title:    title,
 		Subtitle: subtitle,
-		Content:  template.HTML(buf.String()),
+		Content:  template.HTML(rendered.HTML),
 		Meta:     frontMatter,
-		LastMod:  time.Now(), // TODO: Get actual file modification time
+		Created:  created,
+		LastMod:  lastMod,
 	}
 
 	s.logger.Printf("PAGES: Successfully loaded page: %s", page.Title)
@@ -0,0 +1,208 @@
+package render
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	mdhtml "github.com/gomarkdown/markdown/html"
+)
+
+// admonitionTitles are the callout kinds, GitHub's alert set, and their
+// default titles
+var admonitionTitles = map[string]string{
+	"note":      "Note",
+	"tip":       "Tip",
+	"important": "Important",
+	"warning":   "Warning",
+	"caution":   "Caution",
+}
+
+// admonitionMarker matches the "[!NOTE] Optional title" line opening a callout
+var admonitionMarker = regexp.MustCompile(`(?i)^\[!(note|tip|important|warning|caution)\][ \t]*([^\n]*)\n?`)
+
+// admonition is a callout box made from a blockquote starting with [!KIND]
+type admonition struct {
+	ast.Container
+	kind  string
+	title string
+}
+
+// codeTabs groups adjacent labelled code fences into one tabbed block
+type codeTabs struct {
+	ast.Container
+	id string
+}
+
+// transformBlocks turns blockquotes marked as callouts into admonitions and
+// runs of adjacent labelled code fences into tab groups
+func transformBlocks(doc ast.Node) {
+	var containers []ast.Node
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if entering && node.AsContainer() != nil {
+			containers = append(containers, node)
+		}
+		return ast.GoToNext
+	})
+
+	groups := 0
+	for _, container := range containers {
+		children := container.GetChildren()
+		var out []ast.Node
+		for i := 0; i < len(children); i++ {
+			child := children[i]
+			if quote, ok := child.(*ast.BlockQuote); ok {
+				if callout := toAdmonition(quote); callout != nil {
+					callout.SetParent(container)
+					out = append(out, callout)
+					continue
+				}
+			}
+
+			// Collect labelled fences up to the first block that isn't one
+			end := i
+			for end < len(children) && codeTabLabel(children[end]) != "" {
+				end++
+			}
+			if end == i {
+				out = append(out, child)
+				continue
+			}
+			groups++
+			tabs := &codeTabs{}
+			tabs.SetParent(container)
+			adopt(tabs, children[i:end])
+			tabs.id = codeTabsID(tabs, groups)
+			out = append(out, tabs)
+			i = end - 1
+		}
+		container.SetChildren(out)
+	}
+}
+
+// toAdmonition converts a blockquote opening with a [!KIND] marker, or
+// returns nil when it's an ordinary quote
+func toAdmonition(quote *ast.BlockQuote) *admonition {
+	first, ok := ast.GetFirstChild(quote).(*ast.Paragraph)
+	if !ok {
+		return nil
+	}
+	text, ok := ast.GetFirstChild(first).(*ast.Text)
+	if !ok {
+		return nil
+	}
+	match := admonitionMarker.FindSubmatchIndex(text.Literal)
+	if match == nil {
+		return nil
+	}
+
+	kind := strings.ToLower(string(text.Literal[match[2]:match[3]]))
+	title := strings.TrimSpace(string(text.Literal[match[4]:match[5]]))
+	if title == "" {
+		title = admonitionTitles[kind]
+	}
+	text.Literal = text.Literal[match[1]:]
+
+	children := quote.GetChildren()
+	if len(first.GetChildren()) == 1 && len(bytes.TrimSpace(text.Literal)) == 0 {
+		// Drop the paragraph that held only the marker
+		children = children[1:]
+	}
+	callout := &admonition{kind: kind, title: title}
+	adopt(callout, children)
+	return callout
+}
+
+// adopt makes nodes the children of parent. The nodes' old parent is about
+// to be replaced, so it isn't updated.
+func adopt(parent ast.Node, nodes []ast.Node) {
+	children := make([]ast.Node, len(nodes))
+	for i, node := range nodes {
+		node.SetParent(parent)
+		children[i] = node
+	}
+	parent.SetChildren(children)
+}
+
+// codeTabLabel returns the tab label of a code fence, "```go [Go]", or ""
+func codeTabLabel(node ast.Node) string {
+	code, ok := node.(*ast.CodeBlock)
+	if !ok || !code.IsFenced {
+		return ""
+	}
+	return parseFenceInfo(code.Info).label
+}
+
+// codeTabsID names a tab group after its contents, so the radio buttons of
+// different posts on one page don't interfere
+func codeTabsID(tabs *codeTabs, n int) string {
+	h := sha256.New()
+	for _, child := range tabs.GetChildren() {
+		h.Write(child.(*ast.CodeBlock).Info)
+		h.Write(child.(*ast.CodeBlock).Literal)
+	}
+	return fmt.Sprintf("code-tabs-%s-%d", hex.EncodeToString(h.Sum(nil))[:8], n)
+}
+
+// blocksRenderHook writes admonitions and code tab groups. Tabs are radio
+// buttons with labels, switched by CSS alone so no script is needed.
+func (r *Renderer) blocksRenderHook(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+	switch node := node.(type) {
+	case *admonition:
+		if entering {
+			fmt.Fprintf(w, "<div class=\"admonition admonition-%s\" role=\"note\">\n", node.kind)
+			fmt.Fprintf(w, "<p class=\"admonition-title\">%s</p>\n", html.EscapeString(node.title))
+		} else {
+			io.WriteString(w, "</div>\n")
+		}
+		return ast.GoToNext, true
+
+	case *codeTabs:
+		if !entering {
+			return ast.GoToNext, true
+		}
+		fences := node.GetChildren()
+		io.WriteString(w, "<div class=\"code-tabs\">\n")
+		for i, fence := range fences {
+			checked := ""
+			if i == 0 {
+				checked = " checked"
+			}
+			fmt.Fprintf(w, "<input type=\"radio\" class=\"code-tab-input\" name=\"%s\" id=\"%s-%d\"%s>", node.id, node.id, i, checked)
+			fmt.Fprintf(w, "<label class=\"code-tab-label\" for=\"%s-%d\">%s</label>\n", node.id, i, html.EscapeString(codeTabLabel(fence)))
+		}
+		for i, fence := range fences {
+			fmt.Fprintf(w, "<div class=\"code-tab-panel\" role=\"region\" aria-labelledby=\"%s-%d\">\n", node.id, i)
+			r.codeRenderHook(w, fence, true)
+			io.WriteString(w, "</div>\n")
+		}
+		io.WriteString(w, "</div>\n")
+		return ast.SkipChildren, true
+
+	case *ast.List:
+		if !node.IsFootnotesList {
+			return ast.GoToNext, false
+		}
+		if entering {
+			io.WriteString(w, "<section class=\"footnotes\" role=\"doc-endnotes\" aria-label=\"Footnotes\">\n<hr>\n<ol>\n")
+		} else {
+			io.WriteString(w, "</ol>\n</section>\n")
+		}
+		return ast.GoToNext, true
+
+	case *ast.Link:
+		if node.NoteID == 0 || !entering {
+			return ast.GoToNext, node.NoteID != 0
+		}
+		slug := string(mdhtml.Slugify(node.Destination))
+		fmt.Fprintf(w, "<sup class=\"footnote-ref\" id=\"fnref:%s\"><a href=\"#fn:%s\" role=\"doc-noteref\" aria-label=\"Footnote %d\">%d</a></sup>", slug, slug, node.NoteID, node.NoteID)
+		return ast.SkipChildren, true
+	}
+	return ast.GoToNext, false
+}
@@ -0,0 +1,39 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderAdmonitions(t *testing.T) {
+	md := []byte("> [!WARNING] Mind the gas\n> Calls can *revert*.\n\nBetween\n\n> [!tip]\n> Use a multisig.\n\nBetween\n\n> [!NOTICE]\n> Not a callout\n")
+
+	out := Site().Render(md).HTML
+	assert.Contains(t, out, "<div class=\"admonition admonition-warning\" role=\"note\">\n<p class=\"admonition-title\">Mind the gas</p>\n<p>Calls can <em>revert</em>.</p>\n</div>")
+	assert.Contains(t, out, "<div class=\"admonition admonition-tip\" role=\"note\">\n<p class=\"admonition-title\">Tip</p>\n<p>Use a multisig.</p>\n</div>", "kinds are case-insensitive and titled by default")
+	assert.Contains(t, out, "<blockquote>\n<p>[!NOTICE]", "unknown kinds stay quotes")
+}
+
+func TestRenderFootnotes(t *testing.T) {
+	md := []byte("Gas is cheap[^gas], mostly[^2].\n\n[^gas]: On L2s.\n[^2]: Not on mainnet.\n")
+
+	out := Site().Render(md).HTML
+	assert.Contains(t, out, `<sup class="footnote-ref" id="fnref:gas"><a href="#fn:gas" role="doc-noteref" aria-label="Footnote 1">1</a></sup>`)
+	assert.Contains(t, out, `<section class="footnotes" role="doc-endnotes" aria-label="Footnotes">`)
+	assert.Contains(t, out, `<li id="fn:2">Not on mainnet. <a class="footnote-return" href="#fnref:2">`)
+}
+
+func TestRenderCodeTabs(t *testing.T) {
+	md := []byte("```go [Go]\nfmt.Println(1)\n```\n```python [Python]\nprint(1)\n```\n\nBetween\n\n```ts [TypeScript]\nconsole.log(1)\n```\n\n```go\nplain()\n```\n")
+
+	out := Site().Render(md).HTML
+	assert.Equal(t, 2, strings.Count(out, `<div class="code-tabs">`), "fences separated by other blocks form separate groups")
+	assert.Regexp(t, `<input type="radio" class="code-tab-input" name="(code-tabs-[0-9a-f]{8}-1)" id="code-tabs-[0-9a-f]{8}-1-0" checked><label class="code-tab-label" for="code-tabs-[0-9a-f]{8}-1-0">Go</label>`, out)
+	assert.Contains(t, out, `>Python</label>`)
+	assert.Contains(t, out, `<span class="nx">fmt</span>`, "panels are highlighted in the fence language")
+	assert.Equal(t, 3, strings.Count(out, `<div class="code-tab-panel"`))
+	assert.NotContains(t, out, "<script")
+	assert.Contains(t, out, "</div>\n<pre class=\"chroma\"><code><span class=\"line\"><span class=\"cl\"><span class=\"nf\">plain</span>", "unlabelled fences render normally")
+}
@@ -0,0 +1,55 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// Default chroma styles for code blocks. The site only has a dark theme, so
+// readers with a light color scheme get a dark style too.
+const (
+	DefaultLightCodeStyle = "monokai"
+	DefaultDarkCodeStyle  = "monokai"
+)
+
+// ChromaCSS generates the stylesheet for highlighted code, coloring it with
+// the light style or the dark one depending on the reader's color scheme.
+// Empty names use the defaults; unknown names are an error.
+func ChromaCSS(light, dark string) ([]byte, error) {
+	if light == "" {
+		light = DefaultLightCodeStyle
+	}
+	if dark == "" {
+		dark = DefaultDarkCodeStyle
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "/* Generated from the chroma styles %q (light) and %q (dark) */\n", light, dark)
+	for _, scheme := range []struct{ name, style string }{{"light", light}, {"dark", dark}} {
+		if err := writeChromaScheme(&buf, scheme.name, scheme.style); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeChromaScheme writes one style's classes inside a prefers-color-scheme
+// media query
+func writeChromaScheme(w io.Writer, scheme, name string) error {
+	style, ok := styles.Registry[name]
+	if !ok {
+		return fmt.Errorf("unknown chroma style %q for the %s color scheme", name, scheme)
+	}
+
+	fmt.Fprintf(w, "@media (prefers-color-scheme: %s) {\n", scheme)
+	formatter := chromahtml.New(chromahtml.WithClasses(true))
+	if err := formatter.WriteCSS(w, style); err != nil {
+		return fmt.Errorf("write %s chroma style: %w", name, err)
+	}
+	io.WriteString(w, "}\n")
+	return nil
+}
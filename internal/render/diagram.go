@@ -0,0 +1,402 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// MaxDiagramBytes is the largest SVG a diagram may render to. Larger output
+// is discarded and the diagram falls back to the browser.
+const MaxDiagramBytes = 2 << 20
+
+// DefaultDiagramTimeout bounds how long one diagram may take to render
+const DefaultDiagramTimeout = 20 * time.Second
+
+// A diagram that failed to render is retried after diagramRetryDelay,
+// doubling with every failure up to diagramMaxRetryDelay, so a broken
+// diagram doesn't run the renderer on every load but a fixed renderer is
+// picked up without a restart
+const (
+	diagramRetryDelay    = time.Minute
+	diagramMaxRetryDelay = time.Hour
+)
+
+// maxFailedDiagrams bounds the failures remembered for backoff; the oldest
+// is forgotten when it's full
+const maxFailedDiagrams = 1000
+
+// DiagramRenderer turns the source of a diagram code fence into SVG
+type DiagramRenderer interface {
+	// Language is the code fence language the renderer handles
+	Language() string
+
+	// Fingerprint identifies the renderer and its settings. It is part of
+	// the cache key, so changing it re-renders every diagram.
+	Fingerprint() string
+
+	// Available reports whether the renderer can run on this machine
+	Available() bool
+
+	// Render converts diagram source to SVG
+	Render(ctx context.Context, source []byte) ([]byte, error)
+}
+
+// CommandRenderer renders diagrams with an external command. Arguments may
+// contain {in} and {out}, which are replaced with temporary input and output
+// files; without them the source is piped to stdin and SVG read from stdout.
+type CommandRenderer struct {
+	Lang      string   // code fence language
+	Command   string   // executable name or path
+	Args      []string // command line arguments
+	Extension string   // file extension for {in}, e.g. ".mmd"
+
+	lookOnce  sync.Once
+	available bool
+}
+
+// MermaidRenderer renders ```mermaid fences with the Mermaid CLI (mmdc)
+func MermaidRenderer() *CommandRenderer {
+	return &CommandRenderer{
+		Lang:      "mermaid",
+		Command:   "mmdc",
+		Args:      []string{"-i", "{in}", "-o", "{out}", "-t", "dark", "-b", "transparent"},
+		Extension: ".mmd",
+	}
+}
+
+// GraphvizRenderer renders ```dot fences with Graphviz
+func GraphvizRenderer() *CommandRenderer {
+	return &CommandRenderer{
+		Lang:    "dot",
+		Command: "dot",
+		Args:    []string{"-Tsvg"},
+	}
+}
+
+// D2Renderer renders ```d2 fences with the D2 CLI
+func D2Renderer() *CommandRenderer {
+	return &CommandRenderer{
+		Lang:      "d2",
+		Command:   "d2",
+		Args:      []string{"--theme=200", "{in}", "{out}"},
+		Extension: ".d2",
+	}
+}
+
+// DefaultDiagramRenderers returns the renderers for every supported diagram language
+func DefaultDiagramRenderers() []DiagramRenderer {
+	return []DiagramRenderer{MermaidRenderer(), GraphvizRenderer(), D2Renderer()}
+}
+
+// Language returns the code fence language
+func (c *CommandRenderer) Language() string {
+	return c.Lang
+}
+
+// Fingerprint returns the command line used to render
+func (c *CommandRenderer) Fingerprint() string {
+	return c.Command + " " + strings.Join(c.Args, " ")
+}
+
+// Available reports whether the command is installed
+func (c *CommandRenderer) Available() bool {
+	c.lookOnce.Do(func() {
+		_, err := exec.LookPath(c.Command)
+		c.available = err == nil
+	})
+	return c.available
+}
+
+// Render runs the command on the diagram source
+func (c *CommandRenderer) Render(ctx context.Context, source []byte) ([]byte, error) {
+	usesFiles := false
+	for _, arg := range c.Args {
+		if strings.Contains(arg, "{in}") || strings.Contains(arg, "{out}") {
+			usesFiles = true
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	var outputFile string
+	if usesFiles {
+		tempDir, err := os.MkdirTemp("", "diagram-*")
+		if err != nil {
+			return nil, fmt.Errorf("create temp dir: %w", err)
+		}
+		defer os.RemoveAll(tempDir)
+
+		inputFile := filepath.Join(tempDir, "diagram"+c.Extension)
+		outputFile = filepath.Join(tempDir, "diagram.svg")
+		if err := os.WriteFile(inputFile, source, 0o600); err != nil {
+			return nil, fmt.Errorf("write diagram source: %w", err)
+		}
+		replacer := strings.NewReplacer("{in}", inputFile, "{out}", outputFile)
+		for _, arg := range c.Args {
+			cmd.Args = append(cmd.Args, replacer.Replace(arg))
+		}
+	} else {
+		cmd.Args = append(cmd.Args, c.Args...)
+		cmd.Stdin = bytes.NewReader(source)
+	}
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", c.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	if !usesFiles {
+		return stdout.Bytes(), nil
+	}
+	svg, err := os.ReadFile(outputFile)
+	if err != nil {
+		return nil, fmt.Errorf("read %s output: %w", c.Command, err)
+	}
+	return svg, nil
+}
+
+// DiagramOptions configures Diagrams
+type DiagramOptions struct {
+	// Cache stores rendered SVG on disk. Without it every load re-renders.
+	Cache *DiagramCache
+
+	// CacheOnly never runs a renderer; diagrams missing from the cache are
+	// left for the browser. Production sets it so no CLI is needed there.
+	CacheOnly bool
+
+	// Timeout bounds one render, DefaultDiagramTimeout when zero
+	Timeout time.Duration
+
+	Logger *log.Logger
+}
+
+// Diagrams renders diagram code fences to SVG through the cache
+type Diagrams struct {
+	opts      DiagramOptions
+	renderers map[string]DiagramRenderer
+
+	mu     sync.Mutex
+	failed map[string]diagramFailure // by cache key
+	now    func() time.Time
+}
+
+// diagramFailure is when a diagram last failed to render and how many
+// times in a row it has
+type diagramFailure struct {
+	at       time.Time
+	failures int
+}
+
+// retryAt returns when a failed diagram may be rendered again
+func (f diagramFailure) retryAt() time.Time {
+	delay := diagramRetryDelay
+	for i := 1; i < f.failures && delay < diagramMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > diagramMaxRetryDelay {
+		delay = diagramMaxRetryDelay
+	}
+	return f.at.Add(delay)
+}
+
+// NewDiagrams creates a diagram pipeline for the given renderers
+func NewDiagrams(opts DiagramOptions, renderers ...DiagramRenderer) *Diagrams {
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultDiagramTimeout
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.Default()
+	}
+
+	d := &Diagrams{
+		opts:      opts,
+		renderers: make(map[string]DiagramRenderer, len(renderers)),
+		failed:    make(map[string]diagramFailure),
+		now:       time.Now,
+	}
+	for _, renderer := range renderers {
+		d.renderers[renderer.Language()] = renderer
+	}
+	return d
+}
+
+// Handles reports whether a code fence language is a diagram
+func (d *Diagrams) Handles(language string) bool {
+	if d == nil {
+		return false
+	}
+	_, ok := d.renderers[language]
+	return ok
+}
+
+// Render returns the SVG for a diagram, from the cache when possible. It
+// reports false when the diagram has to be rendered in the browser instead.
+func (d *Diagrams) Render(language string, source []byte) ([]byte, bool) {
+	if !d.Handles(language) {
+		return nil, false
+	}
+	svg, _, err := d.render(context.Background(), language, source, d.opts.CacheOnly)
+	if err != nil {
+		return nil, false
+	}
+	return svg, svg != nil
+}
+
+// Prerender makes sure a diagram is in the cache, rendering it if needed.
+// It reports whether the diagram was already cached.
+func (d *Diagrams) Prerender(ctx context.Context, language string, source []byte) (cached bool, err error) {
+	if d.opts.Cache == nil {
+		return false, fmt.Errorf("no diagram cache configured")
+	}
+	_, cached, err = d.render(ctx, language, source, false)
+	return cached, err
+}
+
+// render looks the diagram up in the cache and renders it on a miss unless
+// cacheOnly is set, in which case a miss returns no SVG and no error
+func (d *Diagrams) render(ctx context.Context, language string, source []byte, cacheOnly bool) (svg []byte, cached bool, err error) {
+	renderer, ok := d.renderers[language]
+	if !ok {
+		return nil, false, fmt.Errorf("no renderer for %q diagrams", language)
+	}
+
+	key := DiagramKey(renderer, source)
+	if d.opts.Cache != nil {
+		if svg, ok := d.opts.Cache.Get(key); ok {
+			return svg, true, nil
+		}
+	}
+	if cacheOnly {
+		return nil, false, nil
+	}
+
+	d.mu.Lock()
+	failure, failed := d.failed[key]
+	d.mu.Unlock()
+	if failed && d.now().Before(failure.retryAt()) {
+		return nil, false, fmt.Errorf("%s diagram %s failed to render earlier", language, key[:12])
+	}
+	if !renderer.Available() {
+		return nil, false, fmt.Errorf("%s renderer is not installed (%s)", language, renderer.Fingerprint())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+
+	svg, err = renderer.Render(ctx, source)
+	if err == nil && len(svg) > MaxDiagramBytes {
+		err = fmt.Errorf("rendered SVG is %d bytes, over the %d byte limit", len(svg), MaxDiagramBytes)
+	}
+	if err != nil {
+		d.opts.Logger.Printf("RENDER: %s diagram %s falls back to the browser: %v", language, key[:12], err)
+		d.recordFailure(key, failure)
+		return nil, false, err
+	}
+	if failed {
+		d.mu.Lock()
+		delete(d.failed, key)
+		d.mu.Unlock()
+	}
+
+	if d.opts.Cache != nil {
+		if err := d.opts.Cache.Put(key, svg); err != nil {
+			d.opts.Logger.Printf("RENDER: Warning - could not cache %s diagram: %v", language, err)
+		}
+	}
+	return svg, false, nil
+}
+
+// recordFailure remembers that a diagram failed again after its previous
+// failure, making room by forgetting the oldest failure when needed
+func (d *Diagrams) recordFailure(key string, previous diagramFailure) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.failed[key]; !ok && len(d.failed) >= maxFailedDiagrams {
+		oldest := ""
+		for k, f := range d.failed {
+			if oldest == "" || f.at.Before(d.failed[oldest].at) {
+				oldest = k
+			}
+		}
+		delete(d.failed, oldest)
+	}
+	d.failed[key] = diagramFailure{at: d.now(), failures: previous.failures + 1}
+}
+
+// DiagramKey returns the content address of a diagram's SVG
+func DiagramKey(renderer DiagramRenderer, source []byte) string {
+	h := sha256.New()
+	io.WriteString(h, renderer.Language())
+	h.Write([]byte{0})
+	io.WriteString(h, renderer.Fingerprint())
+	h.Write([]byte{0})
+	h.Write(source)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DiagramBlock is a diagram code fence found in a markdown document
+type DiagramBlock struct {
+	Language string
+	Source   []byte
+}
+
+// DiagramBlocks returns the code fences in a markdown document whose
+// language the diagrams pipeline handles
+func (d *Diagrams) DiagramBlocks(md []byte) []DiagramBlock {
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.NoEmptyLineBeforeBlock)
+	doc := markdown.Parse(md, p)
+
+	var blocks []DiagramBlock
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if code, ok := node.(*ast.CodeBlock); ok && entering {
+			language := parseFenceInfo(code.Info).language
+			if d.Handles(language) {
+				blocks = append(blocks, DiagramBlock{Language: language, Source: code.Literal})
+			}
+		}
+		return ast.GoToNext
+	})
+	return blocks
+}
+
+// writeDiagram writes a diagram as inline SVG, or in a form the browser can
+// handle when no SVG is available: mermaid.js picks up mermaid diagrams and
+// other languages show their source
+func (r *Renderer) writeDiagram(w io.Writer, language string, source []byte) {
+	if svg, ok := r.opts.Diagrams.Render(language, source); ok {
+		fmt.Fprintf(w, `<div class="diagram-container %s-container %s-ssr">`, language, language)
+		w.Write(svg)
+		io.WriteString(w, "</div>")
+		return
+	}
+
+	if language == "mermaid" {
+		io.WriteString(w, `<div class="diagram-container mermaid-container mermaid-csr"><div class="mermaid">`)
+		io.WriteString(w, html.EscapeString(string(source)))
+		io.WriteString(w, "</div></div>")
+		return
+	}
+
+	fmt.Fprintf(w, `<div class="diagram-container diagram-source" data-diagram="%s">`, language)
+	highlightCode(w, fenceOptions{language: language}, source)
+	io.WriteString(w, "</div>")
+}
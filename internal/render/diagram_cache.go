@@ -0,0 +1,151 @@
+package render
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDiagramCacheBytes is the default size limit of the diagram cache
+const DefaultDiagramCacheBytes = 64 << 20
+
+// DiagramCache stores rendered diagrams on disk, one SVG file per content
+// address. Once the files add up to more than the size limit the least
+// recently used ones are removed.
+type DiagramCache struct {
+	dir      string
+	maxBytes int64
+
+	mu sync.Mutex // serializes writes and pruning
+}
+
+// NewDiagramCache creates a cache in dir holding at most maxBytes of SVG.
+// A maxBytes of zero or less uses DefaultDiagramCacheBytes.
+func NewDiagramCache(dir string, maxBytes int64) *DiagramCache {
+	if maxBytes <= 0 {
+		maxBytes = DefaultDiagramCacheBytes
+	}
+	return &DiagramCache{dir: dir, maxBytes: maxBytes}
+}
+
+// Dir returns the cache directory
+func (c *DiagramCache) Dir() string {
+	return c.dir
+}
+
+// Get returns the cached SVG for a key
+func (c *DiagramCache) Get(key string) ([]byte, bool) {
+	path, ok := c.path(key)
+	if !ok {
+		return nil, false
+	}
+	svg, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	// Mark as recently used, the cache may be read-only in production
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return svg, true
+}
+
+// Put stores the SVG for a key and prunes the cache to its size limit
+func (c *DiagramCache) Put(key string, svg []byte) error {
+	path, ok := c.path(key)
+	if !ok {
+		return fmt.Errorf("invalid diagram cache key %q", key)
+	}
+	if int64(len(svg)) > c.maxBytes {
+		return fmt.Errorf("diagram of %d bytes is larger than the whole cache", len(svg))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("create diagram cache: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see partial SVG
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create diagram cache file: %w", err)
+	}
+	if _, err := tmp.Write(svg); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write diagram cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write diagram cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("store diagram cache file: %w", err)
+	}
+
+	return c.prune()
+}
+
+// prune removes the least recently used diagrams until the cache fits its
+// size limit. The caller holds c.mu.
+func (c *DiagramCache) prune() error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("read diagram cache: %w", err)
+	}
+
+	type cached struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []cached
+	var total int64
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".svg") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, cached{filepath.Join(c.dir, entry.Name()), info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+	if total <= c.maxBytes {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, file := range files {
+		if total <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(file.path); err == nil {
+			total -= file.size
+		}
+	}
+	return nil
+}
+
+// path returns the file for a key, rejecting keys that aren't hex digests
+func (c *DiagramCache) path(key string) (string, bool) {
+	if len(key) != 64 {
+		return "", false
+	}
+	for _, r := range key {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return "", false
+		}
+	}
+	return filepath.Join(c.dir, key+".svg"), true
+}
@@ -0,0 +1,141 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDiagramRenderer renders every diagram to the same SVG and counts calls
+type fakeDiagramRenderer struct {
+	lang        string
+	svg         string
+	err         error
+	unavailable bool
+	calls       int
+}
+
+func (f *fakeDiagramRenderer) Language() string    { return f.lang }
+func (f *fakeDiagramRenderer) Fingerprint() string { return "fake " + f.lang }
+func (f *fakeDiagramRenderer) Available() bool     { return !f.unavailable }
+
+func (f *fakeDiagramRenderer) Render(ctx context.Context, source []byte) ([]byte, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []byte(f.svg), nil
+}
+
+func TestDiagramsCache(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	cache := NewDiagramCache(t.TempDir(), 0)
+	dot := &fakeDiagramRenderer{lang: "dot", svg: "<svg>dot</svg>"}
+	diagrams := NewDiagrams(DiagramOptions{Cache: cache, Logger: logger}, dot)
+	renderer := New(Options{Diagrams: diagrams})
+
+	md := []byte("```dot\ndigraph { a -> b }\n```\n")
+	out := renderer.Render(md).HTML
+	assert.Contains(t, out, `<div class="diagram-container dot-container dot-ssr"><svg>dot</svg></div>`)
+
+	// A second render, or a restart with the same cache, is served from disk
+	renderer.Render(md)
+	restarted := NewDiagrams(DiagramOptions{Cache: cache, CacheOnly: true, Logger: logger}, &fakeDiagramRenderer{lang: "dot", unavailable: true})
+	assert.Contains(t, New(Options{Diagrams: restarted}).Render(md).HTML, "<svg>dot</svg>")
+	assert.Equal(t, 1, dot.calls)
+
+	key := DiagramKey(dot, []byte("digraph { a -> b }\n"))
+	_, err := os.Stat(filepath.Join(cache.Dir(), key+".svg"))
+	assert.NoError(t, err)
+
+	cached, err := diagrams.Prerender(context.Background(), "dot", []byte("digraph { a -> b }\n"))
+	require.NoError(t, err)
+	assert.True(t, cached)
+}
+
+func TestDiagramsFallBack(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	failing := &fakeDiagramRenderer{lang: "d2", err: fmt.Errorf("boom")}
+	missing := &fakeDiagramRenderer{lang: "mermaid", unavailable: true}
+	diagrams := NewDiagrams(DiagramOptions{Logger: logger}, failing, missing)
+	renderer := New(Options{Diagrams: diagrams})
+
+	out := renderer.Render([]byte("```d2\na -> b\n```\n\n```mermaid\ngraph TD; A-->B\n```\n")).HTML
+	assert.Contains(t, out, `<div class="diagram-container diagram-source" data-diagram="d2">`)
+	assert.Contains(t, out, `<div class="diagram-container mermaid-container mermaid-csr">`)
+	assert.Equal(t, 0, missing.calls)
+
+	// Failures aren't retried on every load, only after a growing delay
+	now := time.Now()
+	diagrams.now = func() time.Time { return now }
+	renderer.Render([]byte("```d2\na -> b\n```\n"))
+	assert.Equal(t, 1, failing.calls)
+	now = now.Add(diagramRetryDelay + time.Second)
+	renderer.Render([]byte("```d2\na -> b\n```\n"))
+	assert.Equal(t, 2, failing.calls)
+	now = now.Add(diagramRetryDelay + time.Second)
+	renderer.Render([]byte("```d2\na -> b\n```\n"))
+	assert.Equal(t, 2, failing.calls, "the second failure waits twice as long")
+
+	// A renderer that works again clears the failure
+	failing.err, failing.svg = nil, "<svg>d2</svg>"
+	now = now.Add(diagramRetryDelay)
+	assert.Contains(t, renderer.Render([]byte("```d2\na -> b\n```\n")).HTML, "<svg>d2</svg>")
+	assert.Empty(t, diagrams.failed)
+
+	// Remembered failures are bounded
+	failing.err = fmt.Errorf("boom")
+	for i := 0; i <= maxFailedDiagrams; i++ {
+		now = now.Add(time.Millisecond)
+		diagrams.Render("d2", []byte(fmt.Sprintf("a -> b%d", i)))
+	}
+	assert.Len(t, diagrams.failed, maxFailedDiagrams)
+	_, ok := diagrams.failed[DiagramKey(failing, []byte("a -> b0"))]
+	assert.False(t, ok, "the oldest failure is forgotten")
+
+	// Oversized output is rejected
+	huge := &fakeDiagramRenderer{lang: "dot", svg: strings.Repeat("x", MaxDiagramBytes+1)}
+	_, ok = NewDiagrams(DiagramOptions{Logger: logger}, huge).Render("dot", []byte("a"))
+	assert.False(t, ok)
+}
+
+func TestDiagramCachePrunesLeastRecentlyUsed(t *testing.T) {
+	cache := NewDiagramCache(t.TempDir(), 30)
+	keys := make([]string, 3)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%064x", i)
+		require.NoError(t, cache.Put(keys[i], []byte("0123456789")))
+		// Spread modification times so eviction order is deterministic
+		at := time.Now().Add(time.Duration(i-10) * time.Minute)
+		require.NoError(t, os.Chtimes(filepath.Join(cache.Dir(), keys[i]+".svg"), at, at))
+	}
+
+	_, ok := cache.Get(keys[0]) // now the most recently used
+	require.True(t, ok)
+	require.NoError(t, cache.Put(fmt.Sprintf("%064x", 9), []byte("0123456789")))
+
+	_, ok = cache.Get(keys[0])
+	assert.True(t, ok)
+	_, ok = cache.Get(keys[1])
+	assert.False(t, ok, "least recently used diagram is evicted")
+
+	assert.Error(t, cache.Put("../escape", []byte("x")))
+	assert.Error(t, cache.Put(keys[2], make([]byte, 31)))
+}
+
+func TestDiagramBlocks(t *testing.T) {
+	diagrams := NewDiagrams(DiagramOptions{}, DefaultDiagramRenderers()...)
+	blocks := diagrams.DiagramBlocks([]byte("```go\nx := 1\n```\n\n```mermaid\ngraph TD\n```\n\n```dot\ndigraph {}\n```\n"))
+	require.Len(t, blocks, 2)
+	assert.Equal(t, "mermaid", blocks[0].Language)
+	assert.Equal(t, "digraph {}\n", string(blocks[1].Source))
+}
@@ -0,0 +1,232 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	mdhtml "github.com/gomarkdown/markdown/html"
+)
+
+// fenceOptions are the settings in a code fence info string, such as
+// ```go {3,5-7} title="main.go" linenos start=10 [Go]
+type fenceOptions struct {
+	language    string
+	label       string   // [Label], makes the fence a tab
+	title       string   // title="..." or filename="...", shown above the code
+	highlight   [][2]int // {3,5-7} or hl_lines="3 5-7", counted from the block's first line
+	lineNumbers bool     // linenos, also implied by start
+	start       int      // start=N, number of the first line
+	diff        bool     // diff-<lang> or a diff flag, +/- lines over highlighted code
+}
+
+// parseFenceInfo reads the options of a code fence. Unknown options are
+// ignored so that fences written for other renderers still highlight.
+func parseFenceInfo(info []byte) fenceOptions {
+	var opts fenceOptions
+	for i, token := range fenceTokens(string(info)) {
+		key, value, hasValue := strings.Cut(token, "=")
+		value = strings.Trim(value, `"'`)
+		switch {
+		case strings.HasPrefix(token, "{") && strings.HasSuffix(token, "}"):
+			opts.highlight = append(opts.highlight, parseLineRanges(token[1:len(token)-1])...)
+		case strings.HasPrefix(token, "[") && strings.HasSuffix(token, "]"):
+			opts.label = strings.TrimSpace(token[1 : len(token)-1])
+		case hasValue:
+			switch strings.ToLower(key) {
+			case "title", "filename":
+				opts.title = value
+			case "hl_lines", "highlight":
+				opts.highlight = append(opts.highlight, parseLineRanges(value)...)
+			case "start", "linenostart":
+				if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+					opts.start = n
+					opts.lineNumbers = true
+				}
+			case "linenos", "linenumbers", "showlinenumbers":
+				opts.lineNumbers = value != "false"
+			}
+		case i == 0:
+			opts.language = token
+			if language, ok := strings.CutPrefix(token, "diff-"); ok && language != "" {
+				opts.language = language
+				opts.diff = true
+			}
+		default:
+			switch strings.ToLower(token) {
+			case "linenos", "linenumbers", "showlinenumbers":
+				opts.lineNumbers = true
+			case "diff":
+				opts.diff = true
+			}
+		}
+	}
+	return opts
+}
+
+// fenceTokens splits an info string on spaces, keeping {...}, [...] and
+// quoted values together
+func fenceTokens(info string) []string {
+	var tokens []string
+	var current strings.Builder
+	var closer rune
+	for _, r := range info {
+		switch {
+		case closer != 0:
+			current.WriteRune(r)
+			if r == closer {
+				closer = 0
+			}
+		case r == ' ' || r == '\t':
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+			switch r {
+			case '{':
+				closer = '}'
+			case '[':
+				closer = ']'
+			case '"', '\'':
+				closer = r
+			}
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// parseLineRanges reads line ranges like "3,5-7" or "3 5-7"
+func parseLineRanges(spec string) [][2]int {
+	var ranges [][2]int
+	for _, part := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == ' ' }) {
+		from, to, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			continue
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(strings.TrimSpace(to)); err != nil || end < start {
+				continue
+			}
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+	return ranges
+}
+
+// diffLineClass marks the lines of a diff
+var diffLineClass = map[byte]string{'+': "diff-add", '-': "diff-del", ' ': "diff-ctx"}
+
+// writeCodeBlock writes a fenced code block with its caption, line numbers,
+// highlighted lines and diff markers
+func writeCodeBlock(w io.Writer, opts fenceOptions, source []byte) {
+	if opts.title != "" {
+		io.WriteString(w, `<figure class="code-block">`)
+		fmt.Fprintf(w, `<figcaption class="code-title">%s</figcaption>`, html.EscapeString(opts.title))
+	}
+
+	// A diff is highlighted as its language, with the markers taken off the
+	// lines and put back as classes
+	var lineClasses []string
+	if opts.diff {
+		lines := strings.SplitAfter(string(source), "\n")
+		if lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+		var stripped strings.Builder
+		lineClasses = make([]string, len(lines))
+		for i, line := range lines {
+			if class, ok := diffLineClass[line[0]]; ok {
+				lineClasses[i] = class
+				line = line[1:]
+			}
+			stripped.WriteString(line)
+		}
+		source = []byte(stripped.String())
+	}
+
+	var buf bytes.Buffer
+	highlightCode(&buf, opts, source)
+	if lineClasses == nil {
+		w.Write(buf.Bytes())
+	} else {
+		w.Write(addLineClasses(buf.Bytes(), lineClasses))
+	}
+
+	if opts.title != "" {
+		io.WriteString(w, "</figure>")
+	}
+}
+
+// addLineClasses adds a class to each line of chroma output, in order
+func addLineClasses(formatted []byte, classes []string) []byte {
+	marker := []byte(`<span class="line`)
+	var out bytes.Buffer
+	for line := 0; ; line++ {
+		i := bytes.Index(formatted, marker)
+		if i < 0 {
+			out.Write(formatted)
+			return out.Bytes()
+		}
+		out.Write(formatted[:i+len(marker)])
+		if line < len(classes) && classes[line] != "" {
+			out.WriteString(" " + classes[line])
+		}
+		formatted = formatted[i+len(marker):]
+	}
+}
+
+// highlightCode writes code highlighted with chroma classes, or escaped
+// plain text when the code can't be tokenised
+func highlightCode(w io.Writer, opts fenceOptions, source []byte) {
+	// Get lexer for the language
+	lexer := lexers.Get(opts.language)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+
+	// Tokens get classes, which /static/chroma.css colors
+	formatterOpts := []chromahtml.Option{chromahtml.WithClasses(true), chromahtml.TabWidth(2)}
+	if opts.lineNumbers {
+		formatterOpts = append(formatterOpts, chromahtml.WithLineNumbers(true))
+	}
+	if opts.start > 0 {
+		formatterOpts = append(formatterOpts, chromahtml.BaseLineNumber(opts.start))
+	}
+	if len(opts.highlight) > 0 {
+		// chroma counts from the first displayed number
+		ranges := make([][2]int, len(opts.highlight))
+		for i, r := range opts.highlight {
+			ranges[i] = r
+			if opts.start > 0 {
+				ranges[i] = [2]int{r[0] + opts.start - 1, r[1] + opts.start - 1}
+			}
+		}
+		formatterOpts = append(formatterOpts, chromahtml.HighlightLines(ranges))
+	}
+	formatter := chromahtml.New(formatterOpts...)
+
+	iterator, err := lexer.Tokenise(nil, string(source))
+	if err == nil {
+		// With classes the style's colors aren't written, any style will do
+		err = formatter.Format(w, styles.Fallback, iterator)
+	}
+	if err != nil {
+		// Fallback to plain text
+		io.WriteString(w, "<pre><code>")
+		mdhtml.EscapeHTML(w, source)
+		io.WriteString(w, "</code></pre>")
+	}
+}
@@ -0,0 +1,39 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+)
+
+// Frontmatter errors
+var (
+	ErrNoFrontmatter       = errors.New("missing frontmatter delimiter")
+	ErrUnclosedFrontmatter = errors.New("missing frontmatter end delimiter")
+)
+
+// SplitFrontmatter separates the YAML frontmatter between "---" lines at the
+// start of a document from the markdown after it. Windows line endings are
+// accepted.
+func SplitFrontmatter(content []byte) (frontmatter, body []byte, err error) {
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+
+	// Check if content starts with frontmatter delimiter
+	if !bytes.HasPrefix(content, []byte("---\n")) {
+		return nil, content, ErrNoFrontmatter
+	}
+
+	// Find the end of frontmatter, which may also be the end of the file
+	rest := content[4:]
+	if bytes.HasPrefix(rest, []byte("---\n")) || bytes.Equal(rest, []byte("---")) {
+		return nil, bytes.TrimPrefix(bytes.TrimPrefix(rest, []byte("---")), []byte("\n")), nil
+	}
+	endIndex := bytes.Index(rest, []byte("\n---\n"))
+	if endIndex == -1 {
+		if bytes.HasSuffix(rest, []byte("\n---")) {
+			return rest[:len(rest)-4], nil, nil
+		}
+		return nil, content, ErrUnclosedFrontmatter
+	}
+
+	return rest[:endIndex], rest[endIndex+5:], nil
+}
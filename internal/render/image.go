@@ -0,0 +1,441 @@
+package render
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"image"
+	"image/draw"
+	_ "image/gif" // registers GIF for image.DecodeConfig
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// DefaultImageWidths are the widths, in pixels, of the resized variants
+// offered in an image's srcset. Widths at or above the original are skipped.
+var DefaultImageWidths = []int{400, 800, 1600}
+
+// DefaultImageSizes is the sizes attribute of responsive images, matching
+// the width of the blog content column
+const DefaultImageSizes = "(max-width: 800px) 100vw, 800px"
+
+// DefaultImagePrefix is the URL path processed images are served under
+const DefaultImagePrefix = "/images/"
+
+// ImageOptions configures Images
+type ImageOptions struct {
+	// Static holds the site's static files. "/static/images/a.png" and,
+	// when it isn't next to the document, "a.png" resolve to images/a.png.
+	Static fs.FS
+
+	// Widths of the resized variants, DefaultImageWidths when empty
+	Widths []int
+
+	// Sizes is the sizes attribute of images with variants,
+	// DefaultImageSizes when empty
+	Sizes string
+
+	// Prefix is the URL path images are served under, DefaultImagePrefix
+	// when empty. The router must send it to Images.ServeHTTP.
+	Prefix string
+
+	Logger *log.Logger
+}
+
+// Images resolves the local images of markdown documents, generates resized
+// variants of them and serves every version under a fingerprinted name, so
+// responses can be cached forever.
+type Images struct {
+	opts ImageOptions
+
+	mu        sync.RWMutex
+	files     map[string]imageFile       // served name to contents
+	processed map[string]*processedImage // source digest to result
+	used      map[string]bool            // processed keys referenced since the last Prune
+}
+
+// imageFile is one served version of an image
+type imageFile struct {
+	data        []byte
+	contentType string
+}
+
+// processedImage describes how to reference a processed image
+type processedImage struct {
+	src           string
+	srcset        string
+	width, height int
+	files         []string // served names of the original and its variants
+}
+
+// NewImages creates an image pipeline
+func NewImages(opts ImageOptions) *Images {
+	if len(opts.Widths) == 0 {
+		opts.Widths = DefaultImageWidths
+	}
+	if opts.Sizes == "" {
+		opts.Sizes = DefaultImageSizes
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = DefaultImagePrefix
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.Default()
+	}
+
+	return &Images{
+		opts:      opts,
+		files:     make(map[string]imageFile),
+		processed: make(map[string]*processedImage),
+		used:      make(map[string]bool),
+	}
+}
+
+// Prune drops the images that haven't been referenced since the previous
+// Prune, with their variants, and returns how many it dropped. Called after
+// every load of the content, it keeps images of edited or deleted content
+// from piling up; images rendered between loads, such as the bio's, stay.
+func (i *Images) Prune() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	dropped := 0
+	for key, processed := range i.processed {
+		if i.used[key] {
+			continue
+		}
+		for _, name := range processed.files {
+			delete(i.files, name)
+		}
+		delete(i.processed, key)
+		dropped++
+	}
+	i.used = make(map[string]bool)
+	return dropped
+}
+
+// Prefix returns the URL path images are served under
+func (i *Images) Prefix() string {
+	return i.opts.Prefix
+}
+
+// ServeHTTP serves processed images. Names are fingerprinted, so a name
+// always refers to the same bytes and may be cached indefinitely.
+func (i *Images) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, i.opts.Prefix)
+
+	i.mu.RLock()
+	file, ok := i.files[name]
+	i.mu.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", file.contentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if file.contentType == "image/svg+xml" {
+		// SVG can carry script, keep it from running if opened directly
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	}
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(file.data))
+}
+
+// resolve finds the file a markdown image destination refers to. It reports
+// false for images that aren't local files, such as remote URLs.
+func (i *Images) resolve(source fs.FS, dir, destination string) (data []byte, name string, local bool, err error) {
+	u, err := url.Parse(destination)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return nil, "", false, nil
+	}
+	ref := u.Path
+
+	var candidates []struct {
+		fsys fs.FS
+		name string
+	}
+	add := func(fsys fs.FS, name string) {
+		name = path.Clean(name)
+		if fsys != nil && fs.ValidPath(name) {
+			candidates = append(candidates, struct {
+				fsys fs.FS
+				name string
+			}{fsys, name})
+		}
+	}
+	switch {
+	case strings.HasPrefix(ref, "/static/"):
+		add(i.opts.Static, strings.TrimPrefix(ref, "/static/"))
+	case strings.HasPrefix(ref, "/"):
+		// Served by some other route
+		return nil, "", false, nil
+	default:
+		add(source, path.Join(dir, ref))
+		add(i.opts.Static, path.Join("images", ref))
+	}
+
+	for _, candidate := range candidates {
+		data, err := fs.ReadFile(candidate.fsys, candidate.name)
+		if err == nil {
+			return data, candidate.name, true, nil
+		}
+	}
+	return nil, "", true, fmt.Errorf("image %s not found", destination)
+}
+
+// Check reports an error when a markdown image destination in dir of source
+// refers to a local file that doesn't exist. Remote images aren't checked.
+func (i *Images) Check(source fs.FS, dir, destination string) error {
+	_, _, _, err := i.resolve(source, dir, destination)
+	return err
+}
+
+// ImageURL returns the URL a markdown image destination is served from, for
+// images referenced outside the markdown such as a social card. Local images
+// go through the image pipeline when one is configured; other destinations,
+// including "", are returned as they are.
+func (r *Renderer) ImageURL(destination string) (string, error) {
+	images := r.opts.Images
+	if images == nil || destination == "" {
+		return destination, nil
+	}
+	data, name, local, err := images.resolve(r.source, r.sourceDir, destination)
+	if err != nil || !local {
+		return destination, err
+	}
+	processed, err := images.process(name, data)
+	if err != nil {
+		return destination, err
+	}
+	return processed.src, nil
+}
+
+// PruneImages drops the images nothing has rendered since the previous
+// call, see Images.Prune. It does nothing without an image pipeline.
+func (r *Renderer) PruneImages() int {
+	if r == nil || r.opts.Images == nil {
+		return 0
+	}
+	return r.opts.Images.Prune()
+}
+
+// process fingerprints an image and generates its resized variants. Results
+// are kept by content, so reloading unchanged content does no work.
+func (i *Images) process(name string, data []byte) (*processedImage, error) {
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	ext := strings.ToLower(path.Ext(name))
+	key := digest + ext
+
+	i.mu.Lock()
+	processed, ok := i.processed[key]
+	if ok {
+		i.used[key] = true
+	}
+	i.mu.Unlock()
+	if ok {
+		return processed, nil
+	}
+
+	contentType := mime.TypeByExtension(ext)
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("%s is not an image", name)
+	}
+
+	base := imageBaseName(strings.TrimSuffix(path.Base(name), path.Ext(name))) + "-" + digest[:12]
+	files := map[string]imageFile{base + ext: {data, contentType}}
+	processed = &processedImage{src: i.opts.Prefix + base + ext}
+
+	switch ext {
+	case ".jpg", ".jpeg", ".png":
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", name, err)
+		}
+		bounds := img.Bounds()
+		processed.width, processed.height = bounds.Dx(), bounds.Dy()
+
+		var srcset []string
+		for _, width := range i.variantWidths(processed.width) {
+			variant, err := encodeImage(resizeImage(img, width), ext)
+			if err != nil {
+				return nil, fmt.Errorf("resize %s: %w", name, err)
+			}
+			variantName := fmt.Sprintf("%s-%dw%s", base, width, ext)
+			files[variantName] = imageFile{variant, contentType}
+			srcset = append(srcset, fmt.Sprintf("%s%s %dw", i.opts.Prefix, variantName, width))
+		}
+		if len(srcset) > 0 {
+			srcset = append(srcset, fmt.Sprintf("%s %dw", processed.src, processed.width))
+			processed.srcset = strings.Join(srcset, ", ")
+		}
+	case ".gif":
+		// Animated GIFs can't be resized without losing frames, only measured
+		if config, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+			processed.width, processed.height = config.Width, config.Height
+		}
+	}
+
+	i.mu.Lock()
+	for name, file := range files {
+		i.files[name] = file
+		processed.files = append(processed.files, name)
+	}
+	i.processed[key] = processed
+	i.used[key] = true
+	i.mu.Unlock()
+
+	if processed.srcset != "" {
+		i.opts.Logger.Printf("RENDER: Processed image %s into %d variants", name, len(files)-1)
+	}
+	return processed, nil
+}
+
+// variantWidths returns the configured widths smaller than the original
+func (i *Images) variantWidths(original int) []int {
+	var widths []int
+	for _, width := range i.opts.Widths {
+		if width > 0 && width < original {
+			widths = append(widths, width)
+		}
+	}
+	sort.Ints(widths)
+	return widths
+}
+
+// imageBaseName reduces a file name to characters that are safe in a URL
+func imageBaseName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	if b.Len() == 0 {
+		return "image"
+	}
+	return b.String()
+}
+
+// resizeImage scales an image down to the given width, averaging the source
+// pixels that fall into each destination pixel
+func resizeImage(src image.Image, width int) *image.RGBA {
+	bounds := src.Bounds()
+	rgba, ok := src.(*image.RGBA)
+	if !ok || bounds.Min != (image.Point{}) {
+		rgba = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+	}
+
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	height := max(1, (srcH*width+srcW/2)/srcW)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcH/height, max((y+1)*srcH/height, y*srcH/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcW/width, max((x+1)*srcW/width, x*srcW/width+1)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				row := rgba.Pix[sy*rgba.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint32(p[0])
+					g += uint32(p[1])
+					b += uint32(p[2])
+					a += uint32(p[3])
+					n++
+				}
+			}
+
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0], d[1], d[2], d[3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
+
+// encodeImage encodes a resized variant in the format of the original
+func encodeImage(img image.Image, ext string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if ext == ".png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	}
+	return buf.Bytes(), err
+}
+
+// imageRenderHook writes markdown images with their dimensions, variants and
+// lazy loading. Local images are served through the image pipeline when one
+// is configured; problems are recorded in the result.
+func (r *Renderer) imageRenderHook(w io.Writer, node ast.Node, entering bool, result *Result) (ast.WalkStatus, bool) {
+	img, ok := node.(*ast.Image)
+	if !ok {
+		return ast.GoToNext, false
+	}
+	if !entering {
+		return ast.GoToNext, true
+	}
+
+	destination := string(img.Destination)
+	alt := strings.TrimSpace(nodeText(img))
+	if alt == "" {
+		result.Errors = append(result.Errors, fmt.Errorf("image %s has no alt text", destination))
+	}
+
+	var processed *processedImage
+	if images := r.opts.Images; images != nil && destination != "" {
+		data, name, local, err := images.resolve(r.source, r.sourceDir, destination)
+		if err == nil && local {
+			processed, err = images.process(name, data)
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, err)
+		}
+	}
+
+	src := destination
+	if processed != nil {
+		src = processed.src
+	}
+	fmt.Fprintf(w, `<img src="%s"`, html.EscapeString(src))
+	if processed != nil {
+		if processed.srcset != "" {
+			fmt.Fprintf(w, ` srcset="%s" sizes="%s"`, html.EscapeString(processed.srcset), html.EscapeString(r.opts.Images.opts.Sizes))
+		}
+		if processed.width > 0 {
+			fmt.Fprintf(w, ` width="%d" height="%d"`, processed.width, processed.height)
+		}
+	}
+	fmt.Fprintf(w, ` alt="%s"`, html.EscapeString(alt))
+	if len(img.Title) > 0 {
+		fmt.Fprintf(w, ` title="%s"`, html.EscapeString(string(img.Title)))
+	}
+	io.WriteString(w, ` loading="lazy" decoding="async">`)
+	return ast.SkipChildren, true
+}
@@ -0,0 +1,112 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPNG returns a PNG of the given size
+func testPNG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 200, 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestRenderImages(t *testing.T) {
+	content := fstest.MapFS{
+		"blog/chart.png": {Data: testPNG(t, 1000, 500)},
+	}
+	static := fstest.MapFS{
+		"images/logo.png": {Data: testPNG(t, 300, 100)},
+	}
+	images := NewImages(ImageOptions{Static: static, Logger: log.New(io.Discard, "", 0)})
+	renderer := New(Options{Images: images}).WithSource(content, "blog")
+
+	result := renderer.Render([]byte("![A chart](chart.png \"Sales\")\n\n![Logo](/static/images/logo.png) ![Also logo](logo.png)\n\n![Remote](https://example.com/a.png)\n"))
+	require.NoError(t, result.Err())
+
+	assert.Regexp(t, `<img src="/images/chart-[0-9a-f]{12}\.png" srcset="/images/chart-[0-9a-f]{12}-400w\.png 400w, /images/chart-[0-9a-f]{12}-800w\.png 800w, /images/chart-[0-9a-f]{12}\.png 1000w" sizes="\(max-width: 800px\) 100vw, 800px" width="1000" height="500" alt="A chart" title="Sales" loading="lazy" decoding="async">`, result.HTML)
+	assert.Regexp(t, `<img src="/images/logo-[0-9a-f]{12}\.png" width="300" height="100" alt="Logo" loading="lazy"`, result.HTML, "images smaller than every variant get none")
+	assert.Regexp(t, `<img src="/images/logo-[0-9a-f]{12}\.png" width="300" height="100" alt="Also logo"`, result.HTML, "bare names fall back to static/images")
+	assert.Contains(t, result.HTML, `<img src="https://example.com/a.png" alt="Remote" loading="lazy" decoding="async">`)
+
+	// Every version is served, with a long cache lifetime
+	variant := regexp.MustCompile(`/images/chart-[0-9a-f]{12}-400w\.png`).FindString(result.HTML)
+	rec := httptest.NewRecorder()
+	images.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, variant, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Cache-Control"), "immutable")
+	resized, err := png.DecodeConfig(rec.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 400, resized.Width)
+	assert.Equal(t, 200, resized.Height)
+
+	rec = httptest.NewRecorder()
+	images.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/images/missing.png", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestPruneImages(t *testing.T) {
+	content := fstest.MapFS{
+		"old.png": {Data: testPNG(t, 1000, 500)},
+		"new.png": {Data: testPNG(t, 900, 500)},
+	}
+	images := NewImages(ImageOptions{Logger: log.New(io.Discard, "", 0)})
+	renderer := New(Options{Images: images}).WithSource(content, ".")
+	served := func(html string) int {
+		status := http.StatusOK
+		for _, src := range regexp.MustCompile(`/images/[a-z0-9-]+\.png`).FindAllString(html, -1) {
+			rec := httptest.NewRecorder()
+			images.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, src, nil))
+			if rec.Code != http.StatusOK {
+				status = rec.Code
+			}
+		}
+		return status
+	}
+
+	old := renderer.Render([]byte("![Old](old.png)\n")).HTML
+	assert.Equal(t, 0, images.Prune(), "images of the latest load are kept")
+
+	// The next load no longer uses the old image
+	current := renderer.Render([]byte("![New](new.png)\n")).HTML
+	assert.Equal(t, 1, images.Prune())
+	assert.Equal(t, http.StatusNotFound, served(old), "the old image and its variants are dropped")
+	assert.Equal(t, http.StatusOK, served(current))
+	assert.Len(t, images.files, 3)
+}
+
+func TestRenderImageErrors(t *testing.T) {
+	images := NewImages(ImageOptions{Logger: log.New(io.Discard, "", 0)})
+	renderer := New(Options{Images: images}).WithSource(fstest.MapFS{}, ".")
+
+	result := renderer.Render([]byte("![](https://example.com/a.png)\n\n![Gone](missing.png)\n"))
+	require.Len(t, result.Errors, 2)
+	assert.EqualError(t, result.Errors[0], "image https://example.com/a.png has no alt text")
+	assert.EqualError(t, result.Errors[1], "image missing.png not found")
+	assert.ErrorContains(t, result.Err(), "no alt text; image missing.png not found")
+
+	// Without a pipeline local images are left alone, alt text is still required
+	result = New(Options{}).Render([]byte("![](local.png)\n"))
+	assert.Contains(t, result.HTML, `<img src="local.png" alt="" loading="lazy" decoding="async">`)
+	assert.Len(t, result.Errors, 1)
+}
@@ -0,0 +1,64 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	mdhtml "github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// Reference is a link or image in a markdown document
+type Reference struct {
+	Destination string
+	Text        string // link text or image alt text
+	Image       bool
+}
+
+// Inspection describes the anchors a markdown document defines and what it
+// links to, for checking links without rendering
+type Inspection struct {
+	Anchors    map[string]bool // heading and footnote IDs, as rendered
+	References []Reference
+}
+
+// Inspect parses a markdown document the way Render does and lists its
+// anchors and references
+func Inspect(md []byte) Inspection {
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.AutoHeadingIDs | parser.NoEmptyLineBeforeBlock | parser.Footnotes)
+	doc := markdown.Parse(md, p)
+	uniqueHeadingIDs(doc)
+
+	inspection := Inspection{Anchors: make(map[string]bool)}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch node := node.(type) {
+		case *ast.Heading:
+			if node.HeadingID != "" {
+				inspection.Anchors[node.HeadingID] = true
+			}
+		case *ast.Link:
+			if node.NoteID != 0 {
+				slug := string(mdhtml.Slugify(node.Destination))
+				inspection.Anchors["fn:"+slug] = true
+				inspection.Anchors["fnref:"+slug] = true
+				return ast.GoToNext
+			}
+			inspection.References = append(inspection.References, Reference{
+				Destination: string(node.Destination),
+				Text:        strings.TrimSpace(nodeText(node)),
+			})
+		case *ast.Image:
+			inspection.References = append(inspection.References, Reference{
+				Destination: string(node.Destination),
+				Text:        strings.TrimSpace(nodeText(node)),
+				Image:       true,
+			})
+		}
+		return ast.GoToNext
+	})
+	return inspection
+}
@@ -0,0 +1,175 @@
+// Package render turns markdown into HTML the same way for every part of the
+// site: blog posts, the bio and standalone pages.
+package render
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	mdhtml "github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// Options configures a Renderer
+type Options struct {
+	// HeadingAnchors adds a "#" permalink to every section heading
+	HeadingAnchors bool
+
+	// TOC builds a table of contents from the headings and replaces
+	// [[toc]] markers with it. Without it markers are dropped.
+	TOC bool
+
+	// Sanitize strips scripts, event handlers and unsafe URLs from raw HTML
+	// and links
+	Sanitize bool
+
+	// Diagrams renders diagram code fences (mermaid, dot, d2) to SVG on
+	// the server. Without it mermaid diagrams are left for mermaid.js in the
+	// browser and other diagrams show their source.
+	Diagrams *Diagrams
+
+	// Images serves local images resized and fingerprinted. Without it
+	// image sources are left as written.
+	Images *Images
+}
+
+// Result is a rendered markdown document
+type Result struct {
+	HTML      string
+	TOC       []TOCEntry // headings of the document, when Options.TOC is set
+	TOCPlaced bool       // a [[toc]] marker rendered the TOC inside HTML
+
+	// Errors are problems that should keep the document from being
+	// published, such as images without alt text
+	Errors []error
+}
+
+// Err returns the result's errors as one error, or nil when there are none
+func (r Result) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	messages := make([]string, len(r.Errors))
+	for i, err := range r.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Errorf("%s", strings.Join(messages, "; "))
+}
+
+// Renderer converts markdown to HTML. It is safe for concurrent use.
+type Renderer struct {
+	opts Options
+
+	// source and sourceDir locate the document, for relative image paths
+	source    fs.FS
+	sourceDir string
+}
+
+// New creates a renderer with the given options
+func New(opts Options) *Renderer {
+	return &Renderer{opts: opts}
+}
+
+// Site returns the renderer used for the site's own content, with heading
+// permalinks and sanitized HTML
+func Site() *Renderer {
+	return New(Options{HeadingAnchors: true, Sanitize: true})
+}
+
+// WithTOC returns a copy of the renderer that also builds a table of contents
+func (r *Renderer) WithTOC(enabled bool) *Renderer {
+	c := *r
+	c.opts.TOC = enabled
+	return &c
+}
+
+// WithSource returns a copy of the renderer for a document in dir of fsys,
+// so images next to the document can be found
+func (r *Renderer) WithSource(fsys fs.FS, dir string) *Renderer {
+	c := *r
+	c.source = fsys
+	c.sourceDir = dir
+	return &c
+}
+
+// Render converts markdown to HTML
+func (r *Renderer) Render(md []byte) Result {
+	// The parser keeps state, so every document gets its own
+	extensions := parser.CommonExtensions | parser.AutoHeadingIDs | parser.NoEmptyLineBeforeBlock | parser.Footnotes
+	p := parser.NewWithExtensions(extensions)
+
+	doc := markdown.Parse(md, p)
+	uniqueHeadingIDs(doc)
+	if r.opts.Sanitize {
+		sanitizeLinks(doc)
+	}
+	transformBlocks(doc)
+
+	var result Result
+	if r.opts.TOC {
+		result.TOC = buildTOC(doc)
+	}
+
+	renderer := mdhtml.NewRenderer(mdhtml.RendererOptions{
+		Flags:                      mdhtml.CommonFlags | mdhtml.HrefTargetBlank | mdhtml.FootnoteReturnLinks,
+		FootnoteReturnLinkContents: `↩<span class="visually-hidden"> Back to text</span>`,
+	})
+	var headingHook mdhtml.RenderNodeFunc
+	if r.opts.HeadingAnchors {
+		headingHook = headingRenderHook(renderer)
+	}
+	renderer.Opts.RenderNodeHook = func(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+		if isTOCMarker(node) {
+			if entering && len(result.TOC) > 0 {
+				io.WriteString(w, renderTOC(result.TOC))
+				result.TOCPlaced = true
+			}
+			return ast.SkipChildren, true
+		}
+		if headingHook != nil {
+			if status, handled := headingHook(w, node, entering); handled {
+				return status, handled
+			}
+		}
+		if r.opts.Sanitize {
+			if status, handled := sanitizeRenderHook(w, node, entering); handled {
+				return status, handled
+			}
+		}
+		if status, handled := r.blocksRenderHook(w, node, entering); handled {
+			return status, handled
+		}
+		if status, handled := r.imageRenderHook(w, node, entering, &result); handled {
+			return status, handled
+		}
+		return r.codeRenderHook(w, node, entering)
+	}
+
+	result.HTML = string(markdown.Render(doc, renderer))
+	return result
+}
+
+// codeRenderHook provides syntax highlighting for code blocks and diagram support
+func (r *Renderer) codeRenderHook(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+	code, ok := node.(*ast.CodeBlock)
+	if !ok || !entering {
+		return ast.GoToNext, false
+	}
+
+	// Get the language and options from the code block info
+	fence := parseFenceInfo(code.Info)
+	language := fence.language
+
+	// Handle diagrams, on the server when possible
+	if language == "mermaid" || r.opts.Diagrams.Handles(language) {
+		r.writeDiagram(w, language, code.Literal)
+		return ast.GoToNext, true
+	}
+
+	writeCodeBlock(w, fence, code.Literal)
+	return ast.GoToNext, true
+}
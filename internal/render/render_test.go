@@ -0,0 +1,171 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	md := []byte("# Title\n\n## Setup\n\nSome `code` and [a link](https://example.com).\n\n## Setup\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n```go\nfunc main() {}\n```\n")
+
+	result := Site().Render(md)
+
+	assert.Contains(t, result.HTML, `<h1 id="title">Title</h1>`)
+	assert.Contains(t, result.HTML, `<h2 id="setup">Setup <a class="heading-anchor" href="#setup"`)
+	assert.Contains(t, result.HTML, `<h2 id="setup-1">`, "duplicate headings get unique IDs")
+	assert.Contains(t, result.HTML, `<table>`)
+	assert.Contains(t, result.HTML, `target="_blank"`)
+	assert.Contains(t, result.HTML, `<pre class="chroma">`)
+	assert.Empty(t, result.TOC, "no table of contents unless asked for")
+
+	plain := New(Options{}).Render(md)
+	assert.NotContains(t, plain.HTML, "heading-anchor")
+}
+
+func TestRenderTOC(t *testing.T) {
+	md := []byte("Intro\n\n[[toc]]\n\n## One\n\n### One A\n\n## Two\n")
+
+	result := Site().WithTOC(true).Render(md)
+	require.Len(t, result.TOC, 2)
+	assert.Equal(t, "one-a", result.TOC[0].Children[0].ID)
+	assert.Equal(t, 3, CountTOCEntries(result.TOC))
+	assert.True(t, result.TOCPlaced)
+	assert.Contains(t, result.HTML, `<nav class="toc"`)
+	assert.NotContains(t, result.HTML, "[[toc]]")
+
+	result = Site().Render(md)
+	assert.False(t, result.TOCPlaced)
+	assert.NotContains(t, result.HTML, "[[toc]]", "markers are dropped without a TOC")
+}
+
+func TestRenderMermaid(t *testing.T) {
+	md := []byte("```mermaid\ngraph TD; A-->B\n```\n")
+
+	result := New(Options{}).Render(md)
+	assert.Contains(t, result.HTML, `<div class="diagram-container mermaid-container mermaid-csr"><div class="mermaid">graph TD; A--&gt;B`)
+
+	diagrams := NewDiagrams(DiagramOptions{}, &fakeDiagramRenderer{lang: "mermaid", svg: "<svg></svg>"})
+	ssr := New(Options{Diagrams: diagrams}).Render(md)
+	assert.Contains(t, ssr.HTML, `<div class="diagram-container mermaid-container mermaid-ssr"><svg></svg></div>`)
+}
+
+func TestSanitize(t *testing.T) {
+	md := []byte(`<table class="project-table" onclick="steal()"><tr><td>ok</td></tr></table>
+
+<script>alert(1)</script>
+
+Inline <span style="color: red" onmouseover="x()">span</span> and <a href="javascript:alert(1)">bad</a>.
+
+[click](javascript:alert(1)) [fine](/blog) ![img](data:image/png;base64,AAAA)
+`)
+
+	out := Site().Render(md).HTML
+	assert.Contains(t, out, `<table class="project-table">`)
+	assert.Contains(t, out, `<span style="color: red">span</span>`)
+	assert.NotContains(t, out, "onclick")
+	assert.NotContains(t, out, "onmouseover")
+	assert.NotContains(t, out, "<script")
+	assert.NotContains(t, out, "javascript:")
+	assert.Contains(t, out, `<a href="/blog"`)
+	assert.Contains(t, out, `src="data:image/png;base64,AAAA"`)
+
+	unsafe := New(Options{}).Render(md).HTML
+	assert.Contains(t, unsafe, "<script>", "sanitizing is opt-in")
+
+	testCases := []struct{ in, want string }{
+		{`<details open><summary>More</summary><p>Body</p></details>`, `<details open=""><summary>More</summary><p>Body</p></details>`},
+		{`<custom-box data-x="1">kept</custom-box>`, `kept`},
+		{`<form action="/x"><input name="q">text</form>`, `text`},
+		{`<img src=x onerror=alert(1)//>`, `<img src="x">`},
+		{`<a href="java&#x09;script:alert(1)" title='a "b"'>x</a>`, `<a title="a &#34;b&#34;">x</a>`},
+		{`<svg><script>alert(1)</script></svg>after`, `after`},
+		{`<style>p{}</style><p x="1" aria-label="y">it's 1 < 2</p>`, `<p aria-label="y">it's 1 &lt; 2</p>`},
+		{`<!-- note --><br/>`, `<br />`},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, SanitizeHTML(tc.in), tc.in)
+	}
+
+	assert.True(t, SafeURL("#section", false))
+	assert.True(t, SafeURL("mailto:me@example.com", false))
+	assert.False(t, SafeURL(" jav&#x61;script:alert(1)", false))
+	assert.False(t, SafeURL("data:text/html,hi", true))
+}
+
+func TestSplitFrontmatter(t *testing.T) {
+	front, body, err := SplitFrontmatter([]byte("---\r\ntitle: Hi\r\n---\r\n# Body\r\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "title: Hi", string(front))
+	assert.Equal(t, "# Body\n", string(body))
+
+	_, body, err = SplitFrontmatter([]byte("# No frontmatter\n"))
+	assert.ErrorIs(t, err, ErrNoFrontmatter)
+	assert.Equal(t, "# No frontmatter\n", string(body))
+
+	_, _, err = SplitFrontmatter([]byte("---\ntitle: Hi\n"))
+	assert.ErrorIs(t, err, ErrUnclosedFrontmatter)
+
+	front, body, err = SplitFrontmatter([]byte("---\ntitle: Hi\n---"))
+	require.NoError(t, err)
+	assert.Equal(t, "title: Hi", string(front))
+	assert.Empty(t, strings.TrimSpace(string(body)))
+}
+
+func TestRenderFenceOptions(t *testing.T) {
+	md := []byte("```go {2,4-5} title=\"main.go\" start=10\npackage main\n\nfunc main() {\n\tfmt.Println(1)\n}\n```\n")
+
+	out := Site().Render(md).HTML
+	assert.Contains(t, out, `<figure class="code-block"><figcaption class="code-title">main.go</figcaption><pre class="chroma">`)
+	assert.Contains(t, out, `<span class="ln">10</span><span class="cl"><span class="kn">package</span>`, "highlighting survives the options, numbered from start")
+	assert.Contains(t, out, `<span class="line hl"><span class="ln">11</span>`, "ranges count from the first line of the block")
+	assert.Contains(t, out, `<span class="line"><span class="ln">12</span>`)
+	assert.Contains(t, out, `<span class="line hl"><span class="ln">13</span>`)
+	assert.Contains(t, out, `<span class="line hl"><span class="ln">14</span>`)
+
+	opts := parseFenceInfo([]byte(`python [Python 3] linenos hl_lines="1 3-4"`))
+	assert.Equal(t, fenceOptions{language: "python", label: "Python 3", lineNumbers: true, highlight: [][2]int{{1, 1}, {3, 4}}}, opts)
+	assert.Equal(t, fenceOptions{language: "go", diff: true}, parseFenceInfo([]byte("diff-go")))
+	assert.Equal(t, fenceOptions{language: "go", diff: true}, parseFenceInfo([]byte("go diff")))
+	assert.Equal(t, fenceOptions{language: "diff"}, parseFenceInfo([]byte("diff")))
+}
+
+func TestRenderDiff(t *testing.T) {
+	md := []byte("```diff-go\n func main() {\n-\tprintln(\"old\")\n+\tfmt.Println(\"new\")\n }\n```\n")
+
+	out := Site().Render(md).HTML
+	assert.Contains(t, out, `<span class="line diff-ctx"><span class="cl"><span class="kd">func</span>`)
+	assert.Contains(t, out, `<span class="line diff-del"><span class="cl">	<span class="nb">println</span>`)
+	assert.Contains(t, out, `<span class="line diff-add"><span class="cl">	<span class="nx">fmt</span>`)
+	assert.NotContains(t, out, "+", "markers become classes")
+
+	plain := Site().Render([]byte("```diff\n-a\n+b\n```\n")).HTML
+	assert.Contains(t, plain, `<span class="gd">-a`, "plain diffs use the diff lexer")
+}
+
+func TestChromaCSS(t *testing.T) {
+	css, err := ChromaCSS("", "dracula")
+	require.NoError(t, err)
+	out := string(css)
+	light := strings.Index(out, "@media (prefers-color-scheme: light) {")
+	dark := strings.Index(out, "@media (prefers-color-scheme: dark) {")
+	require.True(t, light >= 0 && dark > light)
+	assert.Contains(t, out[light:dark], "background-color: #272822", "the default light style is dark like the site")
+	assert.Contains(t, out[dark:], "background-color: #282a36", "dracula's background")
+
+	_, err = ChromaCSS("no-such-style", "")
+	assert.EqualError(t, err, `unknown chroma style "no-such-style" for the light color scheme`)
+}
+
+func TestInspect(t *testing.T) {
+	md := []byte("## Setup\n\n## Setup\n\nSee [usage](#usage)[^1] and ![A chart](chart.png \"Chart\").\n\n[^1]: A note.\n")
+
+	inspection := Inspect(md)
+	assert.Equal(t, map[string]bool{"setup": true, "setup-1": true, "fn:1": true, "fnref:1": true}, inspection.Anchors, "heading IDs match the rendered ones")
+	assert.Equal(t, []Reference{
+		{Destination: "#usage", Text: "usage"},
+		{Destination: "chart.png", Text: "A chart", Image: true},
+	}, inspection.References)
+}
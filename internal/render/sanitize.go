@@ -0,0 +1,194 @@
+package render
+
+import (
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"golang.org/x/net/html"
+)
+
+// allowedElements may appear in raw HTML, with the attributes allowed on
+// every element and their own. Other elements are dropped, keeping their
+// contents.
+var allowedElements = map[string][]string{
+	"a": {"href", "name", "target", "rel", "hreflang"}, "abbr": nil, "b": nil,
+	"blockquote": {"cite"}, "br": nil, "caption": nil, "cite": nil, "code": nil,
+	"col": {"span"}, "colgroup": {"span"}, "dd": nil, "del": {"cite", "datetime"},
+	"details": {"open"}, "dfn": nil, "div": nil, "dl": nil, "dt": nil, "em": nil,
+	"figcaption": nil, "figure": nil, "h1": nil, "h2": nil, "h3": nil, "h4": nil,
+	"h5": nil, "h6": nil, "hr": nil, "i": nil,
+	"img": {"src", "alt", "width", "height", "loading"},
+	"ins": {"cite", "datetime"}, "kbd": nil, "li": {"value"}, "mark": nil,
+	"ol": {"start", "reversed", "type"}, "p": nil, "picture": nil, "pre": nil,
+	"q": {"cite"}, "s": nil, "samp": nil, "small": nil,
+	"source": {"src", "type", "media"}, "span": nil, "strong": nil, "sub": nil,
+	"summary": nil, "sup": nil, "table": nil, "tbody": nil,
+	"td": {"colspan", "rowspan", "headers", "align"}, "tfoot": nil,
+	"th": {"colspan", "rowspan", "headers", "scope", "align"}, "thead": nil,
+	"time": {"datetime"}, "tr": nil, "u": nil, "ul": nil, "var": nil,
+	"video": {"src", "poster", "controls", "width", "height", "loop", "muted", "playsinline", "preload"},
+	"audio": {"src", "controls", "loop", "muted", "preload"},
+}
+
+// globalAttrs are allowed on every element, as are aria-* and data-*
+var globalAttrs = map[string]bool{
+	"class": true, "id": true, "style": true, "title": true, "lang": true,
+	"dir": true, "role": true,
+}
+
+// droppedElements are removed from raw HTML together with their contents
+var droppedElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "applet": true,
+	"noscript": true, "noembed": true, "noframes": true, "template": true,
+	"textarea": true, "title": true, "select": true, "xmp": true, "svg": true,
+	"math": true,
+}
+
+// urlAttrs hold URLs that are checked for unsafe schemes
+var urlAttrs = map[string]bool{
+	"href": true, "src": true, "cite": true, "poster": true,
+}
+
+// safeSchemes may be used in links and sources
+var safeSchemes = map[string]bool{
+	"http": true, "https": true, "mailto": true, "tel": true, "ftp": true,
+}
+
+// textEscaper escapes text between tags, leaving quotes as they are
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// sanitizeRenderHook writes raw HTML blocks and spans with only allowed
+// elements, attributes and URLs
+func sanitizeRenderHook(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+	switch node := node.(type) {
+	case *ast.HTMLBlock:
+		io.WriteString(w, "\n")
+		io.WriteString(w, SanitizeHTML(string(node.Literal)))
+		io.WriteString(w, "\n")
+		return ast.GoToNext, true
+	case *ast.HTMLSpan:
+		io.WriteString(w, SanitizeHTML(string(node.Literal)))
+		return ast.GoToNext, true
+	}
+	return ast.GoToNext, false
+}
+
+// SanitizeHTML keeps the allowed elements and attributes of a fragment of
+// HTML. Scripts, styles, frames and forms go, with event handlers and
+// javascript: style URLs; text and the markup of content, including classes
+// and inline styles, stay.
+func SanitizeHTML(fragment string) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(fragment))
+	dropped, depth := "", 0 // element being dropped with its contents
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return b.String()
+		}
+		token := z.Token()
+
+		if dropped != "" {
+			switch {
+			case tt == html.StartTagToken && token.Data == dropped:
+				depth++
+			case tt == html.EndTagToken && token.Data == dropped:
+				if depth--; depth == 0 {
+					dropped = ""
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			b.WriteString(textEscaper.Replace(token.Data))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedElements[token.Data] {
+				if tt == html.StartTagToken {
+					dropped, depth = token.Data, 1
+				}
+				continue
+			}
+			attrs, ok := allowedElements[token.Data]
+			if !ok {
+				continue
+			}
+			b.WriteString("<" + token.Data)
+			for _, attr := range token.Attr {
+				if !allowedAttr(attr.Key, attrs) {
+					continue
+				}
+				if urlAttrs[attr.Key] && !SafeURL(attr.Val, token.Data == "img" && attr.Key == "src") {
+					continue
+				}
+				b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+			}
+			if tt == html.SelfClosingTagToken {
+				b.WriteString(" /")
+			}
+			b.WriteString(">")
+		case html.EndTagToken:
+			if _, ok := allowedElements[token.Data]; ok {
+				b.WriteString("</" + token.Data + ">")
+			}
+		}
+	}
+}
+
+// allowedAttr reports whether an attribute may be kept on an element that
+// allows attrs besides the global ones
+func allowedAttr(key string, attrs []string) bool {
+	if globalAttrs[key] || strings.HasPrefix(key, "aria-") || strings.HasPrefix(key, "data-") {
+		return true
+	}
+	for _, attr := range attrs {
+		if key == attr {
+			return true
+		}
+	}
+	return false
+}
+
+// SafeURL reports whether a URL is relative or uses a safe scheme. Inline
+// data: URLs are only allowed for images.
+func SafeURL(rawURL string, image bool) bool {
+	u := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, html.UnescapeString(rawURL))
+
+	colon := strings.IndexByte(u, ':')
+	if colon < 0 || strings.ContainsAny(u[:colon], "/?#") {
+		return true // relative
+	}
+
+	scheme := strings.ToLower(u[:colon])
+	if image && scheme == "data" {
+		return strings.HasPrefix(strings.ToLower(u[colon+1:]), "image/")
+	}
+	return safeSchemes[scheme]
+}
+
+// sanitizeLinks points markdown links and images with unsafe URLs nowhere
+func sanitizeLinks(doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch node := node.(type) {
+		case *ast.Link:
+			if !SafeURL(string(node.Destination), false) {
+				node.Destination = []byte("#")
+			}
+		case *ast.Image:
+			if !SafeURL(string(node.Destination), true) {
+				node.Destination = nil
+			}
+		}
+		return ast.GoToNext
+	})
+}
@@ -0,0 +1,182 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	mdhtml "github.com/gomarkdown/markdown/html"
+)
+
+// Heading levels included in the table of contents
+const (
+	tocMinLevel = 2
+	tocMaxLevel = 3
+)
+
+// MinTOCHeadings is the number of headings a document needs before a table
+// of contents is shown automatically. A [[toc]] marker always shows one.
+const MinTOCHeadings = 3
+
+// tocMarker is a paragraph that is replaced by the table of contents
+const tocMarker = "[[toc]]"
+
+// TOCEntry is a heading in a document's table of contents
+type TOCEntry struct {
+	Level    int        `json:"level"`
+	ID       string     `json:"id"`
+	Title    string     `json:"title"`
+	Children []TOCEntry `json:"children,omitempty"`
+}
+
+// TOCHTML renders a table of contents as a nested list inside a nav
+func TOCHTML(entries []TOCEntry) template.HTML {
+	return template.HTML(renderTOC(entries))
+}
+
+// CountTOCEntries counts the entries of a table of contents at every level
+func CountTOCEntries(entries []TOCEntry) int {
+	count := len(entries)
+	for _, entry := range entries {
+		count += CountTOCEntries(entry.Children)
+	}
+	return count
+}
+
+// uniqueHeadingIDs makes heading IDs unique the same way the HTML renderer
+// does, so that the table of contents links match the rendered anchors
+func uniqueHeadingIDs(doc ast.Node) {
+	seen := make(map[string]int)
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		heading, ok := node.(*ast.Heading)
+		if !ok || !entering || heading.HeadingID == "" {
+			return ast.GoToNext
+		}
+		for {
+			id := heading.HeadingID
+			count, exists := seen[id]
+			if !exists {
+				seen[id] = 0
+				break
+			}
+			seen[id] = count + 1
+			heading.HeadingID = fmt.Sprintf("%s-%d", id, count+1)
+		}
+		return ast.GoToNext
+	})
+}
+
+// buildTOC collects the document's headings into a nested table of contents
+func buildTOC(doc ast.Node) []TOCEntry {
+	var entries []TOCEntry
+	// stack holds the open entries, from the top level down to the innermost
+	var stack []*TOCEntry
+
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		heading, ok := node.(*ast.Heading)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		if heading.Level < tocMinLevel || heading.Level > tocMaxLevel || heading.HeadingID == "" {
+			return ast.SkipChildren
+		}
+
+		entry := TOCEntry{
+			Level: heading.Level,
+			ID:    heading.HeadingID,
+			Title: strings.TrimSpace(nodeText(heading)),
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].Level >= entry.Level {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) == 0 {
+			entries = append(entries, entry)
+			stack = append(stack, &entries[len(entries)-1])
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, entry)
+			stack = append(stack, &parent.Children[len(parent.Children)-1])
+		}
+
+		return ast.SkipChildren
+	})
+
+	return entries
+}
+
+// nodeText returns the plain text inside a node
+func nodeText(node ast.Node) string {
+	var b strings.Builder
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		if leaf := n.AsLeaf(); leaf != nil {
+			switch n.(type) {
+			case *ast.Text, *ast.Code:
+				b.Write(leaf.Literal)
+			}
+		}
+		return ast.GoToNext
+	})
+	return html.UnescapeString(b.String())
+}
+
+// isTOCMarker reports whether a node is a paragraph holding only [[toc]]
+func isTOCMarker(node ast.Node) bool {
+	if _, ok := node.(*ast.Paragraph); !ok {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(nodeText(node)), tocMarker)
+}
+
+// renderTOC renders a table of contents as a nested list
+func renderTOC(entries []TOCEntry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+
+	var b bytes.Buffer
+	b.WriteString(`<nav class="toc" aria-label="Table of contents"><p class="toc-title">Contents</p>`)
+	writeTOCList(&b, entries)
+	b.WriteString("</nav>\n")
+	return b.String()
+}
+
+func writeTOCList(w io.Writer, entries []TOCEntry) {
+	io.WriteString(w, "<ol>")
+	for _, entry := range entries {
+		fmt.Fprintf(w, `<li><a href="#%s">%s</a>`, html.EscapeString(entry.ID), html.EscapeString(entry.Title))
+		if len(entry.Children) > 0 {
+			writeTOCList(w, entry.Children)
+		}
+		io.WriteString(w, "</li>")
+	}
+	io.WriteString(w, "</ol>")
+}
+
+// headingRenderHook renders section headings with a permalink anchor after
+// their text. Top-level headings are usually the page title and are left alone.
+func headingRenderHook(renderer *mdhtml.Renderer) mdhtml.RenderNodeFunc {
+	return func(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+		heading, ok := node.(*ast.Heading)
+		if !ok || heading.HeadingID == "" || heading.Level < 2 {
+			return ast.GoToNext, false
+		}
+
+		if entering {
+			renderer.HeadingEnter(w, heading)
+			return ast.GoToNext, true
+		}
+
+		fmt.Fprintf(w, ` <a class="heading-anchor" href="#%s" aria-label="Permalink to this section">#</a>`, html.EscapeString(heading.HeadingID))
+		renderer.HeadingExit(w, heading)
+		return ast.GoToNext, true
+	}
+}
@@ -0,0 +1,242 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BM25 tuning parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Field weights applied to term frequencies, so a word in the title counts
+// for more than the same word deep in the body
+const (
+	titleWeight   = 3.0
+	tagWeight     = 2.0
+	summaryWeight = 1.5
+	bodyWeight    = 1.0
+)
+
+// prefixWeight discounts terms matched only by prefix against exact matches
+const prefixWeight = 0.5
+
+// minPrefixLength is the shortest word that is also matched as a prefix
+const minPrefixLength = 3
+
+// Document is the searchable text of one item
+type Document struct {
+	ID      string
+	Title   string
+	Summary string
+	Tags    []string
+	TagKeys []string // Values matched by tag: filters, defaults to the lowercased Tags
+	Body    string   // Plain text, see PlainText
+	Date    time.Time
+}
+
+// Result is a document matching a query
+type Result struct {
+	ID    string
+	Score float64
+}
+
+// Index is an in-memory inverted index over a fixed set of documents. It is
+// safe for concurrent reads once built.
+type Index struct {
+	docs     []indexedDoc
+	postings map[string][]posting // term -> documents containing it
+	vocab    []string             // sorted terms, for prefix lookups
+	byID     map[string]int
+	avgLen   float64
+}
+
+type indexedDoc struct {
+	Document
+	tags   map[string]bool
+	length float64 // weighted number of terms
+}
+
+type posting struct {
+	doc  int
+	freq float64 // weighted term frequency
+}
+
+// NewIndex builds an index over the documents
+func NewIndex(docs []Document) *Index {
+	idx := &Index{
+		docs:     make([]indexedDoc, len(docs)),
+		postings: make(map[string][]posting),
+		byID:     make(map[string]int, len(docs)),
+	}
+
+	var totalLen float64
+	for i, doc := range docs {
+		freqs := make(map[string]float64)
+		add := func(text string, weight float64) {
+			for _, term := range Terms(text) {
+				freqs[term] += weight
+			}
+		}
+		add(doc.Title, titleWeight)
+		add(doc.Summary, summaryWeight)
+		add(strings.Join(doc.Tags, " "), tagWeight)
+		add(doc.Body, bodyWeight)
+
+		keys := doc.TagKeys
+		if keys == nil {
+			keys = doc.Tags
+		}
+		tags := make(map[string]bool, len(keys))
+		for _, tag := range keys {
+			tags[strings.ToLower(tag)] = true
+		}
+
+		var length float64
+		for term, freq := range freqs {
+			idx.postings[term] = append(idx.postings[term], posting{doc: i, freq: freq})
+			length += freq
+		}
+
+		idx.docs[i] = indexedDoc{Document: doc, tags: tags, length: length}
+		idx.byID[doc.ID] = i
+		totalLen += length
+	}
+
+	if len(docs) > 0 {
+		idx.avgLen = totalLen / float64(len(docs))
+	}
+
+	idx.vocab = make([]string, 0, len(idx.postings))
+	for term := range idx.postings {
+		idx.vocab = append(idx.vocab, term)
+	}
+	sort.Strings(idx.vocab)
+
+	return idx
+}
+
+// Len returns the number of indexed documents
+func (idx *Index) Len() int {
+	return len(idx.docs)
+}
+
+// Search returns the documents matching every word of the query and all of
+// its filters, best match first. A query with no searchable words returns
+// every document passing the filters, newest first.
+func (idx *Index) Search(q Query) []Result {
+	groups := idx.expand(q.Words)
+
+	if len(groups) == 0 {
+		var docs []int
+		for i := range idx.docs {
+			if q.matches(&idx.docs[i]) {
+				docs = append(docs, i)
+			}
+		}
+		sort.SliceStable(docs, func(i, j int) bool {
+			return idx.docs[docs[i]].Date.After(idx.docs[docs[j]].Date)
+		})
+
+		results := make([]Result, len(docs))
+		for i, doc := range docs {
+			results[i] = Result{ID: idx.docs[doc].ID}
+		}
+		return results
+	}
+
+	// Every word must match, so start from the documents of the first word
+	scores := idx.scoreGroup(groups[0])
+	for _, group := range groups[1:] {
+		groupScores := idx.scoreGroup(group)
+		for doc, score := range scores {
+			if extra, ok := groupScores[doc]; ok {
+				scores[doc] = score + extra
+			} else {
+				delete(scores, doc)
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for doc, score := range scores {
+		if q.matches(&idx.docs[doc]) {
+			results = append(results, Result{ID: idx.docs[doc].ID, Score: score})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	return results
+}
+
+// termMatch is an index term matched by a query word
+type termMatch struct {
+	term   string
+	weight float64
+}
+
+// expand maps each searchable query word to the index terms it matches: its
+// stem exactly, plus any longer term it is a prefix of
+func (idx *Index) expand(words []string) [][]termMatch {
+	var groups [][]termMatch
+
+	for _, word := range words {
+		for _, tok := range tokenize(word) {
+			term, ok := normalize(tok.text)
+			if !ok {
+				continue
+			}
+
+			group := []termMatch{{term: term, weight: 1}}
+			if len(tok.text) >= minPrefixLength {
+				start := sort.SearchStrings(idx.vocab, tok.text)
+				for _, candidate := range idx.vocab[start:] {
+					if !strings.HasPrefix(candidate, tok.text) {
+						break
+					}
+					if candidate != term {
+						group = append(group, termMatch{term: candidate, weight: prefixWeight})
+					}
+				}
+			}
+
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
+
+// scoreGroup scores documents against one query word using BM25
+func (idx *Index) scoreGroup(group []termMatch) map[int]float64 {
+	scores := make(map[int]float64)
+	n := float64(len(idx.docs))
+
+	for _, match := range group {
+		postings := idx.postings[match.term]
+		if len(postings) == 0 {
+			continue
+		}
+
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+		for _, p := range postings {
+			norm := 1 - bm25B + bm25B*idx.docs[p.doc].length/idx.avgLen
+			score := idf * p.freq * (bm25K1 + 1) / (p.freq + bm25K1*norm)
+			scores[p.doc] += match.weight * score
+		}
+	}
+
+	return scores
+}
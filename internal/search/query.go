@@ -0,0 +1,84 @@
+package search
+
+import (
+	"strings"
+	"time"
+)
+
+// Query is a parsed search query.
+//
+// Plain words must all match. Operators narrow the results further:
+//
+//	tag:go            only posts tagged "go" (repeat to require several tags)
+//	before:2025-06    published before 1 June 2025
+//	after:2024        published on or after 1 January 2024
+//
+// Dates accept YYYY, YYYY-MM or YYYY-MM-DD. Operators with values that can't
+// be parsed are searched for as plain words.
+type Query struct {
+	Words  []string // Lowercased words as typed
+	Tags   []string
+	Before time.Time
+	After  time.Time
+}
+
+// ParseQuery parses the search query syntax
+func ParseQuery(raw string) Query {
+	var q Query
+
+	for _, field := range strings.Fields(raw) {
+		key, value, found := strings.Cut(field, ":")
+		if found && value != "" {
+			switch strings.ToLower(key) {
+			case "tag":
+				q.Tags = append(q.Tags, strings.ToLower(value))
+				continue
+			case "before":
+				if t, ok := parseDate(value); ok {
+					q.Before = t
+					continue
+				}
+			case "after":
+				if t, ok := parseDate(value); ok {
+					q.After = t
+					continue
+				}
+			}
+		}
+
+		q.Words = append(q.Words, strings.ToLower(field))
+	}
+
+	return q
+}
+
+// IsEmpty reports whether the query has neither words nor filters
+func (q Query) IsEmpty() bool {
+	return len(q.Words) == 0 && len(q.Tags) == 0 && q.Before.IsZero() && q.After.IsZero()
+}
+
+// parseDate parses a date at year, month or day precision
+func parseDate(value string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02", "2006-01", "2006"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// matches reports whether a document passes the query's filters
+func (q Query) matches(doc *indexedDoc) bool {
+	if !q.Before.IsZero() && !doc.Date.Before(q.Before) {
+		return false
+	}
+	if !q.After.IsZero() && doc.Date.Before(q.After) {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !doc.tags[tag] {
+			return false
+		}
+	}
+	return true
+}
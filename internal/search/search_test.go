@@ -0,0 +1,149 @@
+package search
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testIndex() *Index {
+	return NewIndex([]Document{
+		{
+			ID:      "go-concurrency",
+			Title:   "Concurrency Patterns in Go",
+			Summary: "Channels, goroutines and worker pools",
+			Tags:    []string{"go", "concurrency"},
+			Body:    "Worker pools bound the number of running goroutines. Channels connect the workers.",
+			Date:    time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			ID:      "agents",
+			Title:   "Building Trading Agents",
+			Summary: "Autonomous agents for crypto markets",
+			Tags:    []string{"ai", "crypto"},
+			Body:    "Each agent runs a worker loop. Agents trade on several exchanges and report to a supervisor written in Go.",
+			Date:    time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			ID:      "wallets",
+			Title:   "Custodial Wallet Design",
+			Summary: "Key management for exchanges",
+			Tags:    []string{"crypto"},
+			Body:    strings.Repeat("Filler text about infrastructure. ", 20) + "Hardware security modules protect signing keys.",
+			Date:    time.Date(2024, 11, 5, 0, 0, 0, 0, time.UTC),
+		},
+	})
+}
+
+func ids(results []Result) []string {
+	var out []string
+	for _, r := range results {
+		out = append(out, r.ID)
+	}
+	return out
+}
+
+func TestStem(t *testing.T) {
+	cases := map[string]string{
+		"systems":   "system",
+		"running":   "run",
+		"agents":    "agent",
+		"policies":  "policy",
+		"called":    "call",
+		"quickly":   "quick",
+		"go":        "go",
+		"status":    "status",
+		"analysis":  "analysis",
+		"darkness":  "dark",
+		"addresses": "address",
+	}
+	for word, want := range cases {
+		assert.Equal(t, want, stem(word), word)
+	}
+}
+
+func TestSearchRanksTitleMatchesFirst(t *testing.T) {
+	idx := testIndex()
+
+	// Both posts mention workers, but only one is about worker pools
+	results := idx.Search(ParseQuery("worker"))
+	assert.Equal(t, []string{"go-concurrency", "agents"}, ids(results))
+	assert.Greater(t, results[0].Score, results[1].Score)
+
+	// Title beats a passing mention in the body
+	results = idx.Search(ParseQuery("agents"))
+	require.NotEmpty(t, results)
+	assert.Equal(t, "agents", results[0].ID)
+}
+
+func TestSearchRequiresEveryWord(t *testing.T) {
+	idx := testIndex()
+
+	assert.Equal(t, []string{"agents"}, ids(idx.Search(ParseQuery("agents exchanges supervisor"))))
+	assert.Empty(t, idx.Search(ParseQuery("goroutines wallet")))
+	assert.Empty(t, idx.Search(ParseQuery("nonexistent")))
+}
+
+func TestSearchStemsAndPrefixes(t *testing.T) {
+	idx := testIndex()
+
+	// "runs" and "running" both stem to "run"
+	assert.ElementsMatch(t, []string{"go-concurrency", "agents"}, ids(idx.Search(ParseQuery("run"))))
+
+	// Partial words match as prefixes
+	assert.Equal(t, []string{"go-concurrency"}, ids(idx.Search(ParseQuery("concurr"))))
+	assert.Equal(t, []string{"wallets"}, ids(idx.Search(ParseQuery("hardw"))))
+
+	// Stop words are ignored rather than required
+	assert.Equal(t, []string{"wallets"}, ids(idx.Search(ParseQuery("the wallet"))))
+}
+
+func TestSearchFilters(t *testing.T) {
+	idx := testIndex()
+
+	assert.Equal(t, []string{"agents", "wallets"}, ids(idx.Search(ParseQuery("tag:crypto"))))
+	assert.Equal(t, []string{"wallets"}, ids(idx.Search(ParseQuery("tag:crypto before:2025-06"))))
+	assert.Equal(t, []string{"agents"}, ids(idx.Search(ParseQuery("go after:2025-06"))))
+	assert.Equal(t, []string{"go-concurrency"}, ids(idx.Search(ParseQuery("tag:Go tag:concurrency"))))
+	assert.Empty(t, idx.Search(ParseQuery("tag:go before:2025")))
+}
+
+func TestParseQuery(t *testing.T) {
+	q := ParseQuery("Trading tag:AI before:2025-06 after:2024 foo:bar before:soon")
+	assert.Equal(t, []string{"trading", "foo:bar", "before:soon"}, q.Words)
+	assert.Equal(t, []string{"ai"}, q.Tags)
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), q.Before)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), q.After)
+	assert.False(t, q.IsEmpty())
+	assert.True(t, ParseQuery("   ").IsEmpty())
+}
+
+func TestSnippet(t *testing.T) {
+	idx := testIndex()
+
+	snippet := string(idx.Snippet("wallets", ParseQuery("signing keys")))
+	assert.Contains(t, snippet, "<mark>signing</mark> <mark>keys</mark>")
+	assert.True(t, strings.HasPrefix(snippet, "…"), "snippet should be cut before the match")
+
+	// Without a body match the summary is used
+	assert.Equal(t, "Key management for exchanges", string(idx.Snippet("wallets", ParseQuery("tag:crypto"))))
+
+	assert.Empty(t, idx.Snippet("missing", ParseQuery("keys")))
+}
+
+func TestSnippetEscapesHTML(t *testing.T) {
+	idx := NewIndex([]Document{{ID: "x", Body: "Use <script> tags & generics carefully"}})
+
+	snippet := string(idx.Snippet("x", ParseQuery("generics")))
+	assert.Equal(t, "Use &lt;script&gt; tags &amp; <mark>generics</mark> carefully", snippet)
+}
+
+func TestPlainText(t *testing.T) {
+	html := `<h1 id="x">Title</h1><p>Some <strong>bold</strong>ly &amp; <a href="/">linked</a> text</p>` +
+		`<script>var hidden = 1;</script><pre><code><span>fmt</span>.<span>Println</span></code></pre>`
+
+	assert.Equal(t, "Title Some boldly & linked text fmt.Println", PlainText(html))
+}
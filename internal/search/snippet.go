@@ -0,0 +1,120 @@
+package search
+
+import (
+	"html"
+	"html/template"
+	"strings"
+	"unicode/utf8"
+)
+
+// Snippet lengths in bytes of plain text
+const (
+	snippetLength  = 220
+	snippetLeadIn  = 60
+	snippetElision = "…"
+)
+
+// Snippet returns an excerpt of a document's body around the first word
+// matching the query, with matching words wrapped in <mark>. When nothing in
+// the body matches, the start of the summary (or body) is returned instead.
+func (idx *Index) Snippet(id string, q Query) template.HTML {
+	i, ok := idx.byID[id]
+	if !ok {
+		return ""
+	}
+	doc := &idx.docs[i]
+
+	groups := idx.expand(q.Words)
+	matched := make(map[string]bool)
+	for _, group := range groups {
+		for _, m := range group {
+			matched[m.term] = true
+		}
+	}
+
+	isMatch := func(word string) bool {
+		term, ok := normalize(word)
+		return ok && matched[term]
+	}
+
+	text := doc.Body
+	tokens := tokenize(text)
+	first := -1
+	for j, tok := range tokens {
+		if isMatch(tok.text) {
+			first = j
+			break
+		}
+	}
+
+	if first < 0 {
+		fallback := doc.Summary
+		if fallback == "" {
+			fallback = doc.Body
+		}
+		return template.HTML(html.EscapeString(truncate(fallback, snippetLength)))
+	}
+
+	// Start a little before the first hit, on a word boundary
+	start := 0
+	if tokens[first].start > snippetLeadIn {
+		for j := first; j >= 0; j-- {
+			if tokens[first].start-tokens[j].start > snippetLeadIn {
+				break
+			}
+			start = tokens[j].start
+		}
+	}
+	end := wordBoundary(text, start+snippetLength)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(snippetElision)
+	}
+
+	pos := start
+	for _, tok := range tokens {
+		if tok.start < start || tok.end > end {
+			continue
+		}
+		if !isMatch(tok.text) {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:tok.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[tok.start:tok.end]))
+		b.WriteString("</mark>")
+		pos = tok.end
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+
+	if end < len(text) {
+		b.WriteString(snippetElision)
+	}
+
+	return template.HTML(b.String())
+}
+
+// truncate cuts text to roughly length bytes on a word boundary
+func truncate(text string, length int) string {
+	end := wordBoundary(text, length)
+	if end < len(text) {
+		return strings.TrimSpace(text[:end]) + snippetElision
+	}
+	return text
+}
+
+// wordBoundary returns the offset of the last space at or before pos, so that
+// cutting there doesn't split a word
+func wordBoundary(text string, pos int) int {
+	if pos >= len(text) {
+		return len(text)
+	}
+	if cut := strings.LastIndexByte(text[:pos], ' '); cut > 0 {
+		return cut
+	}
+	for pos > 0 && !utf8.RuneStart(text[pos]) {
+		pos--
+	}
+	return pos
+}
@@ -0,0 +1,178 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// stopWords are common English words left out of the index
+var stopWords = map[string]bool{
+	"a": true, "about": true, "all": true, "an": true, "and": true, "are": true,
+	"as": true, "at": true, "be": true, "but": true, "by": true, "can": true,
+	"do": true, "for": true, "from": true, "has": true, "have": true, "how": true,
+	"if": true, "in": true, "into": true, "is": true, "it": true, "its": true,
+	"not": true, "of": true, "on": true, "or": true, "our": true, "so": true,
+	"than": true, "that": true, "the": true, "their": true, "then": true,
+	"there": true, "these": true, "this": true, "to": true, "was": true,
+	"we": true, "were": true, "what": true, "when": true, "which": true,
+	"who": true, "why": true, "will": true, "with": true, "you": true, "your": true,
+}
+
+// token is a word found in a text along with its byte offsets
+type token struct {
+	text       string // lowercased word
+	start, end int
+}
+
+// tokenize splits text into lowercased words of letters and digits
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+
+	flush := func(end int) {
+		if start >= 0 {
+			tokens = append(tokens, token{text: strings.ToLower(text[start:end]), start: start, end: end})
+			start = -1
+		}
+	}
+
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+
+	return tokens
+}
+
+// Terms returns the stemmed index terms of a text, skipping stop words
+func Terms(text string) []string {
+	var result []string
+	for _, tok := range tokenize(text) {
+		if term, ok := normalize(tok.text); ok {
+			result = append(result, term)
+		}
+	}
+	return result
+}
+
+// normalize turns a lowercased word into its index term. Single characters
+// and stop words are not indexed.
+func normalize(word string) (string, bool) {
+	if len(word) < 2 || stopWords[word] {
+		return "", false
+	}
+	return stem(word), true
+}
+
+// stem strips common English suffixes so that "systems", "system" and
+// "systematic" don't all need to be typed exactly. It is intentionally much
+// lighter than a full Porter stemmer: it only needs to be consistent between
+// indexing and querying.
+func stem(word string) string {
+	if len(word) <= 3 {
+		return word
+	}
+
+	switch {
+	case strings.HasSuffix(word, "sses"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		word = word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") &&
+		!strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		word = word[:len(word)-1]
+	}
+
+	for _, suffix := range []string{"ing", "ed"} {
+		base := strings.TrimSuffix(word, suffix)
+		if base == word || len(base) < 3 || !hasVowel(base) {
+			continue
+		}
+		// "running" -> "run", but keep "call" and "miss"
+		if n := len(base); base[n-1] == base[n-2] && !strings.ContainsRune("lsz", rune(base[n-1])) {
+			base = base[:n-1]
+		}
+		return base
+	}
+
+	for _, rule := range [][2]string{
+		{"ational", "ate"},
+		{"ization", "ize"},
+		{"fulness", "ful"},
+		{"ness", ""},
+		{"ly", ""},
+	} {
+		if strings.HasSuffix(word, rule[0]) && len(word)-len(rule[0]) >= 3 {
+			return word[:len(word)-len(rule[0])] + rule[1]
+		}
+	}
+
+	return word
+}
+
+func hasVowel(s string) bool {
+	return strings.ContainsAny(s, "aeiouy")
+}
+
+// inlineTags don't break words when they are stripped
+var inlineTags = map[string]bool{
+	"a": true, "abbr": true, "b": true, "code": true, "em": true, "i": true,
+	"mark": true, "s": true, "small": true, "span": true, "strong": true,
+	"sub": true, "sup": true,
+}
+
+// PlainText strips tags from rendered HTML and returns its visible text with
+// whitespace collapsed. Script and style elements are dropped entirely.
+func PlainText(htmlContent string) string {
+	var b strings.Builder
+	skip := ""
+
+	for i := 0; i < len(htmlContent); {
+		if htmlContent[i] != '<' {
+			next := strings.IndexByte(htmlContent[i:], '<')
+			if next < 0 {
+				next = len(htmlContent) - i
+			}
+			if skip == "" {
+				b.WriteString(htmlContent[i : i+next])
+			}
+			i += next
+			continue
+		}
+
+		end := strings.IndexByte(htmlContent[i:], '>')
+		if end < 0 {
+			break
+		}
+		tag := strings.ToLower(htmlContent[i+1 : i+end])
+		i += end + 1
+
+		name := strings.TrimPrefix(tag, "/")
+		if sp := strings.IndexAny(name, " \t\n/"); sp >= 0 {
+			name = name[:sp]
+		}
+
+		switch {
+		case skip != "":
+			if tag == "/"+skip {
+				skip = ""
+			}
+		case name == "script" || name == "style":
+			if !strings.HasPrefix(tag, "/") {
+				skip = name
+			}
+		case !inlineTags[name]:
+			// Block-level tags separate words
+			b.WriteByte(' ')
+		}
+	}
+
+	return strings.Join(strings.Fields(html.UnescapeString(b.String())), " ")
+}
@@ -32,6 +32,7 @@ type RateLimiter struct {
 	cleanup  time.Duration
 	maxReqs  int
 	window   time.Duration
+	block    time.Duration
 }
 
 // ClientInfo tracks request information for a specific client
@@ -19,6 +19,10 @@ func NewRateLimiter(config *RateLimiterConfig) *RateLimiter {
 		cleanup: config.CleanupPeriod,
 		maxReqs: config.MaxRequests,
 		window:  config.Window,
+		block:   config.BlockDuration,
+	}
+	if rl.block <= 0 {
+		rl.block = 10 * time.Minute
 	}
 
 	// Start cleanup goroutine
@@ -71,13 +75,29 @@ func (rl *RateLimiter) IsAllowed(clientIP string) bool {
 	// Check if we've exceeded the limit
 	if len(recentRequests) > rl.maxReqs {
 		client.blocked = true
-		client.blockUntil = now.Add(10 * time.Minute) // Block for 10 minutes
+		client.blockUntil = now.Add(rl.block)
 		return false
 	}
 
 	return true
 }
 
+// RetryAfter returns how long a blocked client has to wait before its
+// requests are allowed again, zero when it isn't blocked
+func (rl *RateLimiter) RetryAfter(clientIP string) time.Duration {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	client, exists := rl.clients[clientIP]
+	if !exists || !client.blocked {
+		return 0
+	}
+	if wait := time.Until(client.blockUntil); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
 // GetStats returns current rate limiter statistics
 func (rl *RateLimiter) GetStats() map[string]interface{} {
 	rl.mu.RLock()
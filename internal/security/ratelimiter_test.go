@@ -70,6 +70,32 @@ func TestRateLimiterIsAllowed(t *testing.T) {
 	}
 }
 
+func TestRateLimiterRetryAfter(t *testing.T) {
+	config := &RateLimiterConfig{
+		MaxRequests:   1,
+		Window:        time.Minute,
+		CleanupPeriod: time.Minute,
+		BlockDuration: 5 * time.Minute,
+	}
+
+	rl := NewRateLimiter(config)
+	clientIP := "192.168.1.100"
+
+	rl.IsAllowed(clientIP)
+	if wait := rl.RetryAfter(clientIP); wait != 0 {
+		t.Errorf("Allowed client should not wait, got %v", wait)
+	}
+
+	rl.IsAllowed(clientIP)
+	if wait := rl.RetryAfter(clientIP); wait <= 4*time.Minute || wait > 5*time.Minute {
+		t.Errorf("Blocked client should wait out the block duration, got %v", wait)
+	}
+
+	if wait := rl.RetryAfter("192.168.1.101"); wait != 0 {
+		t.Errorf("Unknown client should not wait, got %v", wait)
+	}
+}
+
 func TestRateLimiterDifferentIPs(t *testing.T) {
 	config := &RateLimiterConfig{
 		MaxRequests:   2,
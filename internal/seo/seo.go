@@ -0,0 +1,296 @@
+// Package seo builds the metadata in the head of every page: the meta
+// description, canonical link, OpenGraph and Twitter cards, and JSON-LD
+// structured data.
+package seo
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"blockhead.consulting/internal/blog"
+	"blockhead.consulting/internal/i18n"
+)
+
+// Page types, used as the OpenGraph type
+const (
+	TypeWebsite = "website"
+	TypeArticle = "article"
+)
+
+// maxDescription is roughly where search engines cut descriptions off
+const maxDescription = 160
+
+// Site holds the site-wide defaults of page metadata
+type Site struct {
+	Name        string
+	Description string
+	BaseURL     string // absolute origin, without a trailing slash
+	Image       string // default social card image
+	Author      string
+	Twitter     string        // the site's handle, with or without @
+	Locale      string        // OpenGraph locale, derived from Lang when empty
+	Lang        string        // locale the page is served in, the default when empty
+	Languages   *i18n.Catalog // locales the site is published in, nil for one
+}
+
+// Meta is the metadata of one page. Build it with Site.Page or Site.Post
+// so empty fields fall back to the site's defaults.
+type Meta struct {
+	Title       string
+	Description string
+	Canonical   string // absolute URL
+	Image       string // absolute URL, empty for none
+	Type        string
+	SiteName    string
+	Locale      string
+	Author      string
+	Twitter     string
+	NoIndex     bool
+	Alternates  []Alternate // the page in every language it's published in
+
+	// Articles only
+	Published time.Time
+	Modified  time.Time
+	Tags      []string
+	Authors   []Person // Credited authors, the site author when empty
+}
+
+// Alternate is the page in one of the languages it's published in
+type Alternate struct {
+	Lang string // hreflang value, x-default for the page shown to other languages
+	Name string // name of the language in itself
+	Path string // site-relative
+	URL  string // absolute
+}
+
+// Person is an author credited with an article
+type Person struct {
+	Name string
+	URL  string // absolute URL of the author's page, empty for guests without one
+}
+
+// ArticleAuthor is the article:author value of a person, their page when
+// they have one
+func (p Person) ArticleAuthor() string {
+	if p.URL != "" {
+		return p.URL
+	}
+	return p.Name
+}
+
+// Page returns the metadata of a page at a site-relative path in the
+// default locale. The canonical URL is the page in the site's locale, and
+// every locale is listed as an alternate.
+func (s Site) Page(title, description, path string) Meta {
+	if description == "" {
+		description = s.Description
+	}
+	codes := make([]string, 0, len(s.Languages.Locales()))
+	for _, locale := range s.Languages.Locales() {
+		codes = append(codes, locale.Code)
+	}
+	return Meta{
+		Title:       title,
+		Description: Description(description),
+		Canonical:   s.URL(s.Languages.Path(s.Lang, path)),
+		Image:       s.URL(s.Image),
+		Type:        TypeWebsite,
+		SiteName:    s.Name,
+		Locale:      s.locale(),
+		Author:      s.Author,
+		Twitter:     twitterHandle(s.Twitter),
+		Alternates:  s.alternates(path, codes),
+	}
+}
+
+// Post returns the metadata of a blog post. The description defaults to
+// the summary; image and author fall back to the site's, and the canonical
+// URL is the post in its own language unless it was first published
+// elsewhere. Only the languages the post is written in are alternates.
+func (s Site) Post(post *blog.Post) Meta {
+	description := post.Description
+	if description == "" {
+		description = post.Summary
+	}
+	path := "/blog/" + post.Slug
+	m := s.Page(post.Title, description, path)
+	m.Type = TypeArticle
+	m.Published = post.Date
+	m.Modified = post.LastModified()
+	m.Tags = post.Tags
+	if post.Image != "" {
+		m.Image = s.URL(post.Image)
+	}
+	// Untranslated posts are shown in every locale but belong to their own
+	lang := s.Languages.Resolve(post.Lang)
+	m.Canonical = s.URL(s.Languages.Path(lang, path))
+	m.Alternates = s.alternates(path, append([]string{lang}, post.Translations...))
+	if post.Canonical != "" {
+		m.Canonical = s.URL(post.Canonical)
+	}
+	if len(post.Authors) > 0 {
+		names := make([]string, 0, len(post.Authors))
+		for _, author := range post.Authors {
+			person := Person{Name: author.Name}
+			if author.Registered {
+				person.URL = s.URL("/blog/author/" + author.ID)
+			}
+			m.Authors = append(m.Authors, person)
+			names = append(names, author.Name)
+		}
+		m.Author = strings.Join(names, ", ")
+	}
+	return m
+}
+
+// alternates returns the page at path in each of the locales, with the
+// default locale as x-default. A page in one language has no alternates.
+func (s Site) alternates(path string, codes []string) []Alternate {
+	if len(codes) < 2 {
+		return nil
+	}
+	var alternates []Alternate
+	for _, code := range codes {
+		localized := s.Languages.Path(code, path)
+		alternates = append(alternates, Alternate{
+			Lang: code,
+			Name: s.Languages.Name(code),
+			Path: localized,
+			URL:  s.URL(localized),
+		})
+	}
+	for _, alternate := range alternates {
+		if alternate.Lang == s.Languages.Default() {
+			alternate.Lang = "x-default"
+			alternates = append(alternates, alternate)
+			break
+		}
+	}
+	return alternates
+}
+
+// URL makes a site-relative reference absolute. Absolute URLs and "" are
+// returned unchanged.
+func (s Site) URL(ref string) string {
+	if ref == "" || strings.Contains(ref, "://") {
+		return ref
+	}
+	return strings.TrimRight(s.BaseURL, "/") + "/" + strings.TrimLeft(ref, "/")
+}
+
+// locale returns the OpenGraph locale of the page, "pt-br" -> "pt_BR"
+func (s Site) locale() string {
+	if s.Locale != "" {
+		return s.Locale
+	}
+	lang := s.Languages.Resolve(s.Lang)
+	if lang == i18n.DefaultLocale {
+		return "en_US"
+	}
+	if language, region, ok := strings.Cut(lang, "-"); ok {
+		return language + "_" + strings.ToUpper(region)
+	}
+	return lang
+}
+
+// TwitterCard is the Twitter card type, large when there's an image to show
+func (m Meta) TwitterCard() string {
+	if m.Image != "" {
+		return "summary_large_image"
+	}
+	return "summary"
+}
+
+// IsArticle reports whether the page is a blog post
+func (m Meta) IsArticle() bool {
+	return m.Type == TypeArticle
+}
+
+// JSONLD returns the page's schema.org structured data, a BlogPosting for
+// articles and a WebPage otherwise, for a <script type="application/ld+json">
+func (m Meta) JSONLD() map[string]interface{} {
+	site := map[string]interface{}{
+		"@type": "Organization",
+		"name":  m.SiteName,
+	}
+	if !m.IsArticle() {
+		data := map[string]interface{}{
+			"@context":    "https://schema.org",
+			"@type":       "WebPage",
+			"name":        m.Title,
+			"description": m.Description,
+			"url":         m.Canonical,
+			"publisher":   site,
+		}
+		if m.Image != "" {
+			data["image"] = m.Image
+		}
+		return data
+	}
+
+	data := map[string]interface{}{
+		"@context":         "https://schema.org",
+		"@type":            "BlogPosting",
+		"headline":         m.Title,
+		"description":      m.Description,
+		"url":              m.Canonical,
+		"mainEntityOfPage": map[string]interface{}{"@type": "WebPage", "@id": m.Canonical},
+		"publisher":        site,
+	}
+	if authors := m.people(); len(authors) > 0 {
+		people := make([]map[string]interface{}, 0, len(authors))
+		for _, author := range authors {
+			person := map[string]interface{}{"@type": "Person", "name": author.Name}
+			if author.URL != "" {
+				person["url"] = author.URL
+			}
+			people = append(people, person)
+		}
+		data["author"] = people
+	}
+	if m.Image != "" {
+		data["image"] = m.Image
+	}
+	if !m.Published.IsZero() {
+		data["datePublished"] = m.Published.Format(time.RFC3339)
+		data["dateModified"] = m.Modified.Format(time.RFC3339)
+	}
+	if len(m.Tags) > 0 {
+		data["keywords"] = strings.Join(m.Tags, ", ")
+	}
+	return data
+}
+
+// people returns the article's authors, the site author when none are
+// credited
+func (m Meta) people() []Person {
+	if len(m.Authors) == 0 && m.Author != "" {
+		return []Person{{Name: m.Author}}
+	}
+	return m.Authors
+}
+
+// Description collapses whitespace and shortens text at a word boundary to
+// the length search engines show
+func Description(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) <= maxDescription {
+		return text
+	}
+	cut := strings.LastIndex(text[:maxDescription-1], " ")
+	if cut <= 0 {
+		for cut = maxDescription - 1; !utf8.RuneStart(text[cut]); cut-- {
+		}
+	}
+	return strings.TrimRight(text[:cut], " ,.;:-") + "…"
+}
+
+// twitterHandle returns a handle with its @
+func twitterHandle(handle string) string {
+	handle = strings.TrimSpace(handle)
+	if handle == "" || strings.HasPrefix(handle, "@") {
+		return handle
+	}
+	return "@" + handle
+}
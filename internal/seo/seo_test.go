@@ -0,0 +1,117 @@
+package seo
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"blockhead.consulting/internal/blog"
+	"blockhead.consulting/internal/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSite = Site{
+	Name:        "Example",
+	Description: "Consulting for careful people",
+	BaseURL:     "https://example.com/",
+	Image:       "/static/images/card.png",
+	Author:      "Site Author",
+	Twitter:     "example",
+}
+
+func TestPage(t *testing.T) {
+	m := testSite.Page("About", "", "/about")
+
+	assert.Equal(t, "Consulting for careful people", m.Description)
+	assert.Equal(t, "https://example.com/about", m.Canonical)
+	assert.Equal(t, "https://example.com/static/images/card.png", m.Image)
+	assert.Equal(t, "@example", m.Twitter)
+	assert.Equal(t, "en_US", m.Locale)
+	assert.Equal(t, "summary_large_image", m.TwitterCard())
+	assert.False(t, m.IsArticle())
+	assert.Equal(t, "WebPage", m.JSONLD()["@type"])
+}
+
+func TestAlternates(t *testing.T) {
+	site := testSite
+	site.Languages = i18n.New("en", map[string]string{"en": "English", "es": "Español"}, nil)
+	site.Lang = "es"
+
+	m := site.Page("Blog", "", "/blog")
+	assert.Equal(t, "https://example.com/es/blog", m.Canonical)
+	assert.Equal(t, "es", m.Locale)
+	assert.Equal(t, []Alternate{
+		{Lang: "en", Name: "English", Path: "/blog", URL: "https://example.com/blog"},
+		{Lang: "es", Name: "Español", Path: "/es/blog", URL: "https://example.com/es/blog"},
+		{Lang: "x-default", Name: "English", Path: "/blog", URL: "https://example.com/blog"},
+	}, m.Alternates)
+
+	// Untranslated posts point at the original from every locale
+	post := &blog.Post{Slug: "hello", Title: "Hello", Lang: "en"}
+	m = site.Post(post)
+	assert.Equal(t, "https://example.com/blog/hello", m.Canonical)
+	assert.Empty(t, m.Alternates)
+
+	post = &blog.Post{Slug: "hello", Title: "Hola", Lang: "es", Translations: []string{"en"}}
+	m = site.Post(post)
+	assert.Equal(t, "https://example.com/es/blog/hello", m.Canonical)
+	assert.Len(t, m.Alternates, 3)
+
+	assert.Empty(t, testSite.Page("About", "", "/about").Alternates, "single-language sites have no alternates")
+}
+
+func TestPost(t *testing.T) {
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	post := &blog.Post{
+		Slug:    "hello",
+		Title:   "Hello",
+		Summary: "A  first\npost",
+		Date:    date,
+		Tags:    []string{"go", "web"},
+	}
+
+	m := testSite.Post(post)
+	assert.Equal(t, "A first post", m.Description, "description defaults to the summary")
+	assert.Equal(t, "https://example.com/blog/hello", m.Canonical)
+	assert.Equal(t, "https://example.com/static/images/card.png", m.Image)
+	assert.Equal(t, "Site Author", m.Author)
+	assert.True(t, m.IsArticle())
+
+	data := m.JSONLD()
+	assert.Equal(t, "BlogPosting", data["@type"])
+	assert.Equal(t, "Hello", data["headline"])
+	assert.Equal(t, "2024-03-01T00:00:00Z", data["datePublished"])
+	assert.Equal(t, "go, web", data["keywords"])
+	assert.Equal(t, []map[string]interface{}{{"@type": "Person", "name": "Site Author"}}, data["author"])
+
+	// Frontmatter overrides the defaults
+	post.Description = "Written elsewhere"
+	post.Image = "https://cdn.example.org/hello.png"
+	post.Canonical = "https://example.org/hello"
+	post.Authors = []blog.Author{{ID: "ada", Name: "Ada", Registered: true}, {ID: "guest", Name: "Guest"}}
+	m = testSite.Post(post)
+	assert.Equal(t, "Written elsewhere", m.Description)
+	assert.Equal(t, "https://cdn.example.org/hello.png", m.Image)
+	assert.Equal(t, "https://example.org/hello", m.Canonical)
+	assert.Equal(t, "Ada, Guest", m.Author)
+	assert.Equal(t, []Person{{Name: "Ada", URL: "https://example.com/blog/author/ada"}, {Name: "Guest"}}, m.Authors)
+	assert.Equal(t, []map[string]interface{}{
+		{"@type": "Person", "name": "Ada", "url": "https://example.com/blog/author/ada"},
+		{"@type": "Person", "name": "Guest"},
+	}, m.JSONLD()["author"])
+}
+
+func TestDescription(t *testing.T) {
+	assert.Equal(t, "short", Description("  short "))
+
+	long := strings.Repeat("word ", 50)
+	got := Description(long)
+	assert.True(t, strings.HasSuffix(got, "word…"), got)
+	assert.LessOrEqual(t, utf8.RuneCountInString(got), maxDescription)
+
+	// No spaces to cut at, the cut mustn't split a rune
+	got = Description(strings.Repeat("é", 200))
+	assert.True(t, utf8.ValidString(got))
+	assert.True(t, strings.HasSuffix(got, "…"))
+}
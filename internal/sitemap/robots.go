@@ -0,0 +1,66 @@
+package sitemap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RobotsRules describes the rules written to robots.txt
+type RobotsRules struct {
+	UserAgent  string
+	Allow      []string
+	Disallow   []string
+	CrawlDelay int
+	Sitemaps   []string // Absolute sitemap URLs
+}
+
+// Robots renders a robots.txt document
+func Robots(rules RobotsRules) []byte {
+	var b strings.Builder
+
+	userAgent := rules.UserAgent
+	if userAgent == "" {
+		userAgent = "*"
+	}
+	fmt.Fprintf(&b, "User-agent: %s\n", userAgent)
+
+	for _, path := range rules.Allow {
+		fmt.Fprintf(&b, "Allow: %s\n", path)
+	}
+
+	for _, path := range uniquePaths(rules.Disallow) {
+		fmt.Fprintf(&b, "Disallow: %s\n", path)
+	}
+
+	// An empty group would otherwise be ambiguous to some crawlers
+	if len(rules.Allow) == 0 && len(rules.Disallow) == 0 {
+		b.WriteString("Disallow:\n")
+	}
+
+	if rules.CrawlDelay > 0 {
+		fmt.Fprintf(&b, "Crawl-delay: %d\n", rules.CrawlDelay)
+	}
+
+	if len(rules.Sitemaps) > 0 {
+		b.WriteString("\n")
+		for _, url := range rules.Sitemaps {
+			fmt.Fprintf(&b, "Sitemap: %s\n", url)
+		}
+	}
+
+	return []byte(b.String())
+}
+
+// uniquePaths removes duplicate paths while preserving order
+func uniquePaths(paths []string) []string {
+	seen := make(map[string]bool, len(paths))
+	var result []string
+	for _, path := range paths {
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+		result = append(result, path)
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package sitemap
+
+import (
+	"path"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// StaticRoutes returns the paths of GET routes registered on the router that
+// have no path variables and don't fall under any excluded prefix. Routes that
+// serve files (anything with an extension) are skipped as well.
+func StaticRoutes(router *mux.Router, excludePrefixes []string) []string {
+	var paths []string
+
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil || strings.Contains(template, "{") {
+			return nil
+		}
+
+		// Only pages a crawler can GET; prefix handlers such as /static/ have no methods
+		methods, err := route.GetMethods()
+		if err != nil || !containsMethod(methods, "GET") || path.Ext(template) != "" {
+			return nil
+		}
+
+		for _, prefix := range excludePrefixes {
+			if strings.HasPrefix(template, prefix) {
+				return nil
+			}
+		}
+
+		paths = append(paths, template)
+		return nil
+	})
+
+	return paths
+}
+
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package sitemap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxURLsPerSitemap is the protocol limit on entries in a single sitemap file
+const MaxURLsPerSitemap = 50000
+
+// URL is a single sitemap entry with a site-relative path
+type URL struct {
+	Path       string
+	LastMod    time.Time
+	ChangeFreq string
+	Priority   float64
+}
+
+// Sitemap is an ordered set of URLs for one site
+type Sitemap struct {
+	BaseURL string
+	URLs    []URL
+}
+
+// New creates a sitemap with duplicate paths removed and entries sorted by path
+func New(baseURL string, urls []URL) *Sitemap {
+	seen := make(map[string]int, len(urls))
+	deduped := make([]URL, 0, len(urls))
+
+	for _, u := range urls {
+		if idx, exists := seen[u.Path]; exists {
+			// Keep the most recent modification time for repeated paths
+			if u.LastMod.After(deduped[idx].LastMod) {
+				deduped[idx].LastMod = u.LastMod
+			}
+			continue
+		}
+		seen[u.Path] = len(deduped)
+		deduped = append(deduped, u)
+	}
+
+	sort.SliceStable(deduped, func(i, j int) bool {
+		return deduped[i].Path < deduped[j].Path
+	})
+
+	return &Sitemap{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		URLs:    deduped,
+	}
+}
+
+// NeedsIndex reports whether the URLs must be split across several sitemap files
+func (s *Sitemap) NeedsIndex() bool {
+	return len(s.URLs) > MaxURLsPerSitemap
+}
+
+// Parts returns the number of sitemap files needed for all URLs
+func (s *Sitemap) Parts() int {
+	if len(s.URLs) == 0 {
+		return 1
+	}
+	return (len(s.URLs) + MaxURLsPerSitemap - 1) / MaxURLsPerSitemap
+}
+
+// URLSet renders the n-th (1-based) sitemap file
+func (s *Sitemap) URLSet(part int) ([]byte, error) {
+	if part < 1 || part > s.Parts() {
+		return nil, fmt.Errorf("sitemap part %d out of range", part)
+	}
+
+	start := (part - 1) * MaxURLsPerSitemap
+	end := start + MaxURLsPerSitemap
+	if end > len(s.URLs) {
+		end = len(s.URLs)
+	}
+
+	doc := urlSet{Xmlns: xmlns, URLs: make([]urlEntry, 0, end-start)}
+	for _, u := range s.URLs[start:end] {
+		entry := urlEntry{
+			Loc:        s.BaseURL + u.Path,
+			ChangeFreq: u.ChangeFreq,
+		}
+		if !u.LastMod.IsZero() {
+			entry.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+		}
+		if u.Priority > 0 {
+			entry.Priority = fmt.Sprintf("%.1f", u.Priority)
+		}
+		doc.URLs = append(doc.URLs, entry)
+	}
+
+	return marshal(doc)
+}
+
+// Index renders a sitemap index pointing at each part, named by partPath
+func (s *Sitemap) Index(partPath func(part int) string) ([]byte, error) {
+	doc := sitemapIndex{Xmlns: xmlns}
+
+	for part := 1; part <= s.Parts(); part++ {
+		entry := indexEntry{Loc: s.BaseURL + partPath(part)}
+
+		// The part's last modification is its newest URL
+		start := (part - 1) * MaxURLsPerSitemap
+		end := start + MaxURLsPerSitemap
+		if end > len(s.URLs) {
+			end = len(s.URLs)
+		}
+		var newest time.Time
+		for _, u := range s.URLs[start:end] {
+			if u.LastMod.After(newest) {
+				newest = u.LastMod
+			}
+		}
+		if !newest.IsZero() {
+			entry.LastMod = newest.UTC().Format(time.RFC3339)
+		}
+
+		doc.Sitemaps = append(doc.Sitemaps, entry)
+	}
+
+	return marshal(doc)
+}
+
+const xmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	URLs    []urlEntry `xml:"url"`
+}
+
+type urlEntry struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []indexEntry `xml:"sitemap"`
+}
+
+type indexEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// marshal encodes an XML document with the standard header
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode sitemap: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,107 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDeduplicatesAndSorts(t *testing.T) {
+	older := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	sm := New("https://example.test/", []URL{
+		{Path: "/work", LastMod: older},
+		{Path: "/", LastMod: older},
+		{Path: "/work", LastMod: newer},
+	})
+
+	assert.Equal(t, "https://example.test", sm.BaseURL)
+	require.Len(t, sm.URLs, 2)
+	assert.Equal(t, "/", sm.URLs[0].Path)
+	assert.Equal(t, newer, sm.URLs[1].LastMod)
+}
+
+func TestURLSet(t *testing.T) {
+	sm := New("https://example.test", []URL{
+		{Path: "/blog/post", LastMod: time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC), ChangeFreq: "monthly", Priority: 0.6},
+		{Path: "/about"},
+	})
+
+	body, err := sm.URLSet(1)
+	require.NoError(t, err)
+
+	var doc urlSet
+	require.NoError(t, xml.Unmarshal(body, &doc))
+	require.Len(t, doc.URLs, 2)
+	assert.Equal(t, "https://example.test/about", doc.URLs[0].Loc)
+	assert.Empty(t, doc.URLs[0].LastMod)
+	assert.Equal(t, "2025-05-05T00:00:00Z", doc.URLs[1].LastMod)
+	assert.Equal(t, "0.6", doc.URLs[1].Priority)
+
+	_, err = sm.URLSet(2)
+	assert.Error(t, err)
+}
+
+func TestIndexSplitsLargeSitemaps(t *testing.T) {
+	urls := make([]URL, MaxURLsPerSitemap+1)
+	for i := range urls {
+		urls[i] = URL{Path: fmt.Sprintf("/p/%06d", i)}
+	}
+
+	sm := New("https://example.test", urls)
+	assert.True(t, sm.NeedsIndex())
+	assert.Equal(t, 2, sm.Parts())
+
+	body, err := sm.Index(func(part int) string { return fmt.Sprintf("/sitemap-%d.xml", part) })
+	require.NoError(t, err)
+
+	var doc sitemapIndex
+	require.NoError(t, xml.Unmarshal(body, &doc))
+	require.Len(t, doc.Sitemaps, 2)
+	assert.Equal(t, "https://example.test/sitemap-2.xml", doc.Sitemaps[1].Loc)
+
+	last, err := sm.URLSet(2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(last), "<url>"))
+}
+
+func TestStaticRoutes(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/", handler).Methods("GET")
+	r.HandleFunc("/about", handler).Methods("GET")
+	r.HandleFunc("/blog/{slug}", handler).Methods("GET")
+	r.HandleFunc("/blog/feed.xml", handler).Methods("GET")
+	r.HandleFunc("/content/about", handler).Methods("GET")
+	r.HandleFunc("/contact", handler).Methods("POST")
+	r.PathPrefix("/static/").Handler(http.HandlerFunc(handler))
+
+	routes := StaticRoutes(r, []string{"/content/"})
+	assert.Equal(t, []string{"/", "/about"}, routes)
+}
+
+func TestRobots(t *testing.T) {
+	body := string(Robots(RobotsRules{
+		Disallow:   []string{"/admin/", "/calendar", "/admin/"},
+		CrawlDelay: 5,
+		Sitemaps:   []string{"https://example.test/sitemap.xml"},
+	}))
+
+	assert.Contains(t, body, "User-agent: *\n")
+	assert.Equal(t, 1, strings.Count(body, "Disallow: /admin/"))
+	assert.Contains(t, body, "Disallow: /calendar\n")
+	assert.Contains(t, body, "Crawl-delay: 5\n")
+	assert.Contains(t, body, "Sitemap: https://example.test/sitemap.xml\n")
+
+	empty := string(Robots(RobotsRules{}))
+	assert.Contains(t, empty, "Disallow:\n")
+}
@@ -1,22 +1,24 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
 	"embed"
 	"encoding/hex"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"html/template"
-	"io"
 	"io/fs"
 	"log"
 	"math"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
-	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"sort"
@@ -26,21 +28,24 @@ import (
 	"syscall"
 	"time"
 
+	"blockhead.consulting/internal/api"
 	"blockhead.consulting/internal/bio"
 	"blockhead.consulting/internal/blog"
 	"blockhead.consulting/internal/config"
 	"blockhead.consulting/internal/contact"
 	"blockhead.consulting/internal/email"
 	"blockhead.consulting/internal/events"
+	"blockhead.consulting/internal/export"
+	"blockhead.consulting/internal/feed"
+	"blockhead.consulting/internal/history"
+	"blockhead.consulting/internal/i18n"
+	"blockhead.consulting/internal/llms"
+	"blockhead.consulting/internal/ogimage"
+	"blockhead.consulting/internal/render"
 	"blockhead.consulting/internal/security"
+	"blockhead.consulting/internal/seo"
+	"blockhead.consulting/internal/sitemap"
 	"blockhead.consulting/internal/storage/git"
-	"github.com/alecthomas/chroma/v2/formatters/html"
-	"github.com/alecthomas/chroma/v2/lexers"
-	"github.com/alecthomas/chroma/v2/styles"
-	"github.com/gomarkdown/markdown"
-	"github.com/gomarkdown/markdown/ast"
-	mdhtml "github.com/gomarkdown/markdown/html"
-	"github.com/gomarkdown/markdown/parser"
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 	"gopkg.in/yaml.v3"
@@ -52,6 +57,10 @@ type BlogPost struct {
 	Date        time.Time
 	Summary     string
 	Content     template.HTML
+	TOC         template.HTML // Table of contents shown above the content, if any
+	Updated     time.Time     // Last revision, zero unless the post changed after it was published
+	Revisions   []history.Revision
+	Authors     []blog.Author
 	ReadingTime int
 	Tags        []string
 	FileName    string
@@ -94,6 +103,8 @@ type SiteConfig struct {
 	HeroStyle       string // "professional" or "cyberpunk"
 	ConsoleLogging  bool   // Enable/disable JavaScript console logging
 	CSPNonce        string // Content Security Policy nonce for inline scripts
+	Locale          string // Locale the page is served in
+	LocalePrefix    string // Path prefix of the locale, empty for the default
 }
 
 // Input validation patterns
@@ -105,13 +116,17 @@ var templateFS embed.FS
 //go:embed static/*
 var staticFS embed.FS
 
-//go:embed content/blog/*.md content/blog.yml
+//go:embed content/blog content/blog.yml content/authors.yml
 var blogFS embed.FS
 
+//go:embed content/i18n
+var i18nFS embed.FS
+
 var (
 	templates       *template.Template
 	blogPosts       []BlogPost
 	blogService     blog.Service
+	previewSigner   *blog.PreviewSigner
 	bioService      bio.Service
 	contactService  contact.Service
 	emailService    email.Service
@@ -119,10 +134,20 @@ var (
 	timeSlots       = make(map[string]*TimeSlot)
 	bookingsFile    = "data/bookings.json"
 	securityConfig  *security.Config
+	machineLimiter  *security.RateLimiter // tighter limit of the markdown and llms.txt endpoints, nil for none
 	siteConfig      *SiteConfig
 	configService   config.Service
 	appConfig       *config.SiteConfig
 	workConfig      *config.WorkConfig
+	locales         *i18n.Catalog                 // languages the site is published in and their UI strings
+	localeConfigs   map[string]*config.SiteConfig // site.yml with the overlay of each other locale
+	
+	siteRenderer    *render.Renderer // renders markdown for blog posts, the bio and pages alike
+	siteImages      *render.Images   // resized, fingerprinted images referenced from markdown
+	postCards       *ogimage.Generator // social preview images of posts without an image of their own
+	contentHistory  *history.Git       // created and updated dates of content files
+	chromaCSS       chromaStylesheet // code highlighting stylesheet served as /static/chroma.css
+	llmsFull        llmsFullText     // llms-full.txt, built again only when the posts change
 )
 
 func init() {
@@ -142,6 +167,18 @@ func init() {
 		"upper": strings.ToUpper,
 		"lower": strings.ToLower,
 		"replaceAll": strings.ReplaceAll,
+		"tagSlug": blog.TagSlug,
+		// T translates a UI string from content/i18n into a locale
+		"T": func(lang, key string, args ...interface{}) string {
+			return locales.T(lang, key, args...)
+		},
+		// tagURL links a tag, or any of its aliases, to its tag page
+		"tagURL": func(tag string) string {
+			if blogService == nil {
+				return "/blog/tag/" + blog.TagSlug(tag)
+			}
+			return "/blog/tag/" + blogService.CanonicalTag(tag)
+		},
 		"slug": func(s string) string {
 			s = strings.ToLower(s)
 			s = strings.ReplaceAll(s, " ", "-")
@@ -176,11 +213,15 @@ func init() {
 
 	// Initialize configuration (uses security config)
 	initializeConfig()
+	initializeLocales()
 
 	// Load blog posts (after config is initialized)
 	if err := initializeBlogService(); err != nil {
 		log.Fatalf("Failed to initialize blog service: %v", err)
 	}
+	
+	// Code highlighting colors come from blog.yml
+	initializeChromaCSS()
 
 	// Load existing bookings
 	loadBookings()
@@ -190,55 +231,13 @@ func init() {
 }
 
 func main() {
-	r := mux.NewRouter()
-
-	// Routes
-	r.HandleFunc("/", homeHandler).Methods("GET")
-	
-	// HTMX content-only routes
-	r.HandleFunc("/content/home", homeContentHandler).Methods("GET")
-	
-	// Blog routes (conditional based on config)
-	if siteConfig.BlogEnabled {
-		r.HandleFunc("/blog", blogHandler).Methods("GET")
-		r.HandleFunc("/blog/{slug}", blogPostHandler).Methods("GET")
-		r.HandleFunc("/content/blog", blogContentHandler).Methods("GET")
-	}
-	
-	// About routes
-	r.HandleFunc("/about", aboutHandler).Methods("GET")
-	r.HandleFunc("/content/about", aboutContentHandler).Methods("GET")
-	
-	// Work experience routes
-	r.HandleFunc("/work", workHandler).Methods("GET")
-	r.HandleFunc("/content/work", workContentHandler).Methods("GET")
-	
-	r.HandleFunc("/contact", contactHandler).Methods("POST")
-	
-	// Health check endpoint for Docker/monitoring
-	r.HandleFunc("/health", healthHandler).Methods("GET")
-
-	// Calendar routes (conditional based on config)
-	if siteConfig.CalendarEnabled {
-		r.HandleFunc("/calendar", calendarHandler).Methods("GET")
-		r.HandleFunc("/content/calendar", calendarContentHandler).Methods("GET")
-		r.HandleFunc("/api/slots", slotsHandler).Methods("GET")
-		r.HandleFunc("/api/book", bookingHandler).Methods("POST")
-	}
-
-	// Static files - serve from embedded filesystem
-	staticFiles, err := fs.Sub(staticFS, "static")
-	if err != nil {
-		log.Fatalf("Failed to create static file sub-filesystem: %v", err)
+	// "export" writes a static copy of the site instead of serving it
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		runExport(os.Args[2:])
+		return
 	}
-	r.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.FS(staticFiles))))
-
-	// Admin endpoints (protect these in production!)
-	r.HandleFunc("/admin/slots", adminSlotsHandler).Methods("GET", "POST")
 	
-	// Security middleware stack (order matters!)
-	r.Use(security.SecurityMiddleware(securityConfig))
-	r.Use(loggingMiddleware)
+	r := newRouter()
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -278,6 +277,228 @@ func main() {
 	} else {
 		log.Println("Server exited gracefully")
 	}
+	
+	// Stop background work such as the blog content watcher
+	if stopper, ok := blogService.(interface{ Stop(context.Context) error }); ok {
+		stopper.Stop(ctx)
+	}
+}
+
+// exportExcludedPrefixes are routes a static copy of the site can't serve
+var exportExcludedPrefixes = []string{"/api/", "/admin/", "/health", "/contact", "/blog/search", "/blog/preview/"}
+
+// runExport writes every route of the site to a directory, starting from the
+// sitemap, the feeds, the HTMX fragments and the static files and following
+// the links between them. It exits non-zero when any route fails.
+func runExport(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	outDir := flags.String("out", "dist", "Directory to write the static site to")
+	flags.Parse(args)
+	
+	// init has set up the limiter, but the export fetches every post's
+	// markdown from one address at once, well over its limit
+	machineLimiter = nil
+	
+	// The copy is what gets deployed, so pages are rendered as in production,
+	// without development-only scripts such as mode-tests.js
+	siteConfig.Environment = "production"
+	r := newRouter()
+	req := httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil)
+	
+	// Features that are switched off have no routes, but content may still
+	// link to them
+	prefixes := append([]string(nil), exportExcludedPrefixes...)
+	if !siteConfig.BlogEnabled {
+		prefixes = append(prefixes, "/blog", "/content/blog")
+	}
+	if !siteConfig.CalendarEnabled {
+		prefixes = append(prefixes, "/calendar", "/content/calendar")
+	}
+	
+	// Fragments aren't in the sitemap but are listed as static routes. Images
+	// are found through the pages that use them.
+	excluded := localizedPrefixes(prefixes)
+	routes := sitemap.StaticRoutes(r, append([]string{siteImages.Prefix()}, excluded...))
+	for _, u := range buildSitemap(req, r).URLs {
+		routes = append(routes, u.Path)
+	}
+	routes = append(routes, "/sitemap.xml", "/robots.txt", "/static/chroma.css")
+	if siteConfig.BlogEnabled {
+		for _, locale := range locales.Locales() {
+			for _, feedPath := range []string{"/blog/feed.xml", "/blog/atom.xml", "/blog/feed.json"} {
+				routes = append(routes, locales.Path(locale.Code, feedPath))
+			}
+		}
+		
+		// Preview images are only referenced from meta tags and markdown
+		// sources from llms.txt, neither of which is followed
+		for _, locale := range locales.Locales() {
+			for _, post := range blogService.GetAll(i18n.WithLocale(req.Context(), locale.Code)) {
+				if post.Lang != locale.Code {
+					continue
+				}
+				if post.Image == "" {
+					routes = append(routes, locales.Path(post.Lang, postCardPath(post.Slug)))
+				}
+				routes = append(routes, locales.Path(post.Lang, postMarkdownPath(post.Slug)))
+			}
+		}
+	}
+	staticFiles, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		log.Fatalf("Failed to open static files: %v", err)
+	}
+	fs.WalkDir(staticFiles, ".", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			routes = append(routes, "/static/"+path)
+		}
+		return nil
+	})
+	
+	result, err := export.Site(r, export.Options{
+		OutDir:    *outDir,
+		BaseURL:   siteBaseURL(req),
+		Routes:    routes,
+		Exclude:   excluded,
+		Fragments: localizedPrefixes([]string{"/content/"}),
+	})
+	if err != nil {
+		log.Fatalf("Export failed: %v", err)
+	}
+	result.Summary(os.Stdout)
+	if len(result.Failures) > 0 {
+		os.Exit(1)
+	}
+	fmt.Printf("Static site written to %s\n", *outDir)
+}
+
+// newRouter registers all site routes and middleware
+func newRouter() *mux.Router {
+	r := mux.NewRouter()
+
+	// Pages are served at the root in the default locale and under a
+	// prefix in every other one
+	registerPageRoutes(r)
+	for _, locale := range locales.Others() {
+		prefix := "/" + locale.Code
+		r.Handle(prefix, http.RedirectHandler(prefix+"/", http.StatusMovedPermanently))
+		registerPageRoutes(r.PathPrefix(prefix).Subrouter())
+	}
+	
+	r.HandleFunc("/contact", contactHandler).Methods("POST")
+	
+	// Search engine discovery
+	r.HandleFunc("/sitemap.xml", sitemapHandler(r)).Methods("GET")
+	r.HandleFunc("/sitemap-{part:[0-9]+}.xml", sitemapPartHandler(r)).Methods("GET")
+	r.HandleFunc("/robots.txt", robotsHandler).Methods("GET")
+	
+	// Site content for language models and other machine readers
+	r.Handle("/llms.txt", machineReadable(llmsHandler(false))).Methods("GET")
+	r.Handle("/llms-full.txt", machineReadable(llmsHandler(true))).Methods("GET")
+	
+	// Health check endpoint for Docker/monitoring
+	r.HandleFunc("/health", healthHandler).Methods("GET")
+
+	// Calendar booking API (conditional based on config)
+	if siteConfig.CalendarEnabled {
+		r.HandleFunc("/api/slots", slotsHandler).Methods("GET")
+		r.HandleFunc("/api/book", bookingHandler).Methods("POST")
+	}
+	
+	// Public JSON API of the blog, documented at /api/v1/openapi.json
+	if siteConfig.BlogEnabled && blogService != nil {
+		api.New(blogService, api.Options{
+			Title:   siteConfig.SiteName + " API",
+			BaseURL: siteBaseURL,
+			Logger:  log.New(os.Stdout, "[api] ", log.LstdFlags),
+		}).Register(r)
+	}
+
+	// Generated code highlighting styles, registered before the static file server
+	r.HandleFunc("/static/chroma.css", chromaCSSHandler).Methods("GET", "HEAD")
+	
+	// Static files - serve from embedded filesystem
+	staticFiles, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		log.Fatalf("Failed to create static file sub-filesystem: %v", err)
+	}
+	r.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.FS(staticFiles))))
+	
+	// Images referenced from markdown, resized and fingerprinted while content loads
+	if siteImages != nil {
+		r.PathPrefix(siteImages.Prefix()).Handler(siteImages).Methods("GET", "HEAD")
+	}
+
+	// Admin endpoints (protect these in production!)
+	r.HandleFunc("/admin/slots", adminSlotsHandler).Methods("GET", "POST")
+	if siteConfig.BlogEnabled {
+		r.HandleFunc("/admin/preview", adminPreviewHandler).Methods("GET")
+	}
+	
+	// Security middleware stack (order matters!)
+	r.Use(security.SecurityMiddleware(securityConfig))
+	r.Use(loggingMiddleware)
+	r.Use(localeMiddleware)
+
+	return r
+}
+
+// registerPageRoutes registers the pages and HTMX fragments of one locale
+func registerPageRoutes(r *mux.Router) {
+	r.HandleFunc("/", homeHandler).Methods("GET")
+	
+	// HTMX content-only routes
+	r.HandleFunc("/content/home", homeContentHandler).Methods("GET")
+	
+	// Blog routes (conditional based on config)
+	if siteConfig.BlogEnabled {
+		r.HandleFunc("/blog", blogHandler).Methods("GET")
+		
+		// Syndication feeds (registered before the slug route so they aren't treated as posts)
+		r.HandleFunc("/blog/feed.xml", blogFeedHandler(feed.FormatRSS)).Methods("GET")
+		r.HandleFunc("/blog/atom.xml", blogFeedHandler(feed.FormatAtom)).Methods("GET")
+		r.HandleFunc("/blog/feed.json", blogFeedHandler(feed.FormatJSON)).Methods("GET")
+		r.HandleFunc("/blog/tag/{tag}/feed.xml", blogTagFeedHandler).Methods("GET")
+		r.HandleFunc("/blog/author/{id}/feed.xml", blogAuthorFeedHandler).Methods("GET")
+		
+		// Signed preview links for drafts and scheduled posts
+		r.HandleFunc("/blog/preview/{slug}", blogPreviewHandler).Methods("GET")
+		r.HandleFunc("/blog/search", blogSearchHandler(r)).Methods("GET")
+		
+		// Paginated listings, tag pages and archives
+		r.HandleFunc("/blog/page/{page:[0-9]+}", blogHandler).Methods("GET")
+		r.HandleFunc("/blog/tag/{tag}", blogTagHandler).Methods("GET")
+		r.HandleFunc("/blog/tag/{tag}/page/{page:[0-9]+}", blogTagHandler).Methods("GET")
+		r.HandleFunc("/blog/series/{name}", blogSeriesHandler).Methods("GET")
+		r.HandleFunc("/blog/series/{name}/page/{page:[0-9]+}", blogSeriesHandler).Methods("GET")
+		r.HandleFunc("/blog/author/{id}", blogAuthorHandler).Methods("GET")
+		r.HandleFunc("/blog/author/{id}/page/{page:[0-9]+}", blogAuthorHandler).Methods("GET")
+		r.HandleFunc("/blog/{year:[0-9]{4}}", trailingSlashRedirect).Methods("GET")
+		r.HandleFunc("/blog/{year:[0-9]{4}}/", blogArchiveHandler).Methods("GET")
+		r.HandleFunc("/blog/{year:[0-9]{4}}/page/{page:[0-9]+}/", blogArchiveHandler).Methods("GET")
+		r.HandleFunc("/blog/{year:[0-9]{4}}/{month:[0-9]{2}}", trailingSlashRedirect).Methods("GET")
+		r.HandleFunc("/blog/{year:[0-9]{4}}/{month:[0-9]{2}}/", blogArchiveHandler).Methods("GET")
+		r.HandleFunc("/blog/{year:[0-9]{4}}/{month:[0-9]{2}}/page/{page:[0-9]+}/", blogArchiveHandler).Methods("GET")
+		
+		r.Handle("/blog/{slug}.md", machineReadable(blogMarkdownHandler)).Methods("GET")
+		r.HandleFunc("/blog/{slug}", blogPostHandler).Methods("GET")
+		r.HandleFunc("/blog/{slug}/og.png", blogCardHandler).Methods("GET")
+		r.HandleFunc("/content/blog", blogContentHandler).Methods("GET")
+	}
+	
+	// About routes
+	r.HandleFunc("/about", aboutHandler).Methods("GET")
+	r.HandleFunc("/content/about", aboutContentHandler).Methods("GET")
+	
+	// Work experience routes
+	r.HandleFunc("/work", workHandler).Methods("GET")
+	r.HandleFunc("/content/work", workContentHandler).Methods("GET")
+
+	// Calendar routes (conditional based on config)
+	if siteConfig.CalendarEnabled {
+		r.HandleFunc("/calendar", calendarHandler).Methods("GET")
+		r.HandleFunc("/content/calendar", calendarContentHandler).Methods("GET")
+	}
 }
 
 func loggingMiddleware(next http.Handler) http.Handler {
@@ -296,9 +517,84 @@ func loggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// localeCookie remembers the language a visitor picked with the switcher
+const localeCookie = "lang"
+
+// localeMiddleware serves each request in the locale of its path prefix and
+// remembers a language picked through a ?lang= link
+func localeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if lang := r.URL.Query().Get("lang"); lang != "" && locales.Supports(lang) {
+			http.SetCookie(w, &http.Cookie{
+				Name:     localeCookie,
+				Value:    locales.Resolve(lang),
+				Path:     "/",
+				MaxAge:   365 * 24 * 60 * 60,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		
+		lang, _ := locales.Split(r.URL.Path)
+		next.ServeHTTP(w, r.WithContext(i18n.WithLocale(r.Context(), lang)))
+	})
+}
+
+// visitorLocale returns the language a visitor picked, or else the one their
+// browser prefers most of those the site is published in. The response then
+// depends on the visitor's headers, so caches are told which ones.
+func visitorLocale(w http.ResponseWriter, r *http.Request) string {
+	w.Header().Add("Vary", "Accept-Language, Cookie")
+	if lang := r.URL.Query().Get("lang"); lang != "" && locales.Supports(lang) {
+		return locales.Resolve(lang)
+	}
+	if cookie, err := r.Cookie(localeCookie); err == nil && locales.Supports(cookie.Value) {
+		return locales.Resolve(cookie.Value)
+	}
+	return locales.Resolve(locales.Negotiate(r.Header.Get("Accept-Language")))
+}
+
+// requestLocale returns the locale a request is served in
+func requestLocale(r *http.Request) string {
+	return locales.Resolve(i18n.FromContext(r.Context()))
+}
+
+// localePath returns a site-relative path in the locale of a request
+func localePath(r *http.Request, path string) string {
+	return locales.Path(requestLocale(r), path)
+}
+
+// requestConfig returns the legacy site configuration in the locale of a request
+func requestConfig(r *http.Request) *SiteConfig {
+	lang := requestLocale(r)
+	localized := *siteConfig
+	localized.Locale = lang
+	localized.LocalePrefix = locales.Prefix(lang)
+	if appConfig, ok := localeConfigs[lang]; ok && appConfig.Site.Name != "" {
+		localized.SiteName = appConfig.Site.Name
+	}
+	return &localized
+}
+
+// requestAppConfig returns site.yml with the overlay of the request's locale
+func requestAppConfig(r *http.Request) *config.SiteConfig {
+	if localized, ok := localeConfigs[requestLocale(r)]; ok {
+		return localized
+	}
+	return appConfig
+}
+
 func homeHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	
+	// Visitors landing on the root are sent to the home page of their language
+	if r.URL.Path == "/" && len(locales.Locales()) > 1 {
+		if lang := visitorLocale(w, r); lang != locales.Default() {
+			http.Redirect(w, r, locales.Path(lang, "/"), http.StatusFound)
+			return
+		}
+	}
+	appConfig := requestAppConfig(r)
+	
 	// Get brief bio
 	var bioBrief *bio.Bio
 	if bioService != nil {
@@ -318,13 +614,15 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
 	data := struct {
 		Title    string
 		Page     string
+		Meta     seo.Meta
 		Config   *SiteConfig
 		AppConfig *config.SiteConfig
 		BioBrief *bio.Bio
 	}{
 		Title:    title,
 		Page:     "home",
-		Config:   siteConfig,
+		Meta:     siteSEO(r).Page(title, "", "/"),
+		Config:   requestConfig(r),
 		AppConfig: appConfig,
 		BioBrief: bioBrief,
 	}
@@ -336,31 +634,323 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// blogPostsPerPage is the number of posts on each page of a blog listing
+const blogPostsPerPage = 9
+
+// blogListing is the template data for pages listing blog posts
+type blogListing struct {
+	Title      string
+	Page       string
+	Meta       seo.Meta
+	Heading    string // Replaces the blog title from blog.yml when set
+	Subheading string
+	Path       string       // Of the page shown, which an emptied search box returns to
+	ActiveTag  string       // Canonical tag of a tag page, "all" elsewhere
+	Author     *blog.Author // Shown above the posts of an author page
+	Posts      []blog.Post
+	Pagination blog.Pagination
+	PrevURL    string
+	NextURL    string
+	Config     *SiteConfig
+	AppConfig  *config.SiteConfig
+	WorkConfig *config.WorkConfig
+	BlogConfig *blog.BlogConfig
+}
+
 func blogHandler(w http.ResponseWriter, r *http.Request) {
-	data := struct {
-		Title      string
-		Page       string
-		Posts      []BlogPost
-		Config     *SiteConfig
-		AppConfig  *config.SiteConfig
-		WorkConfig *config.WorkConfig
-		BlogConfig *blog.BlogConfig
-	}{
-		Title:      "Blog - Blockhead Consulting",
-		Page:       "blog",
-		Posts:      blogPosts,
-		Config:     siteConfig,
-		AppConfig:  appConfig,
-		WorkConfig: workConfig,
-		BlogConfig: blogService.GetBlogConfig(),
+	// Tag filters used to be a query parameter
+	if tag := r.URL.Query().Get("tag"); tag != "" && tag != "all" {
+		http.Redirect(w, r, localePath(r, "/blog/tag/"+blogService.CanonicalTag(tag)), http.StatusMovedPermanently)
+		return
+	}
+	
+	listing := blogListing{
+		Title:     "Blog - Blockhead Consulting",
+		ActiveTag: "all",
+	}
+	renderBlogListing(w, r, "page-blog.html", listing, blogService.GetAll(r.Context()), "/blog")
+}
+
+// blogTagHandler lists the posts filed under a tag and all of its aliases
+func blogTagHandler(w http.ResponseWriter, r *http.Request) {
+	tag := mux.Vars(r)["tag"]
+	
+	// Aliases and differently written tags share the canonical tag's page
+	canonical := blogService.CanonicalTag(tag)
+	if canonical != tag {
+		if canonical == "" {
+			http.NotFound(w, r)
+			return
+		}
+		http.Redirect(w, r, localePath(r, blogPagePath("/blog/tag/"+canonical, listingPageNumber(r))), http.StatusMovedPermanently)
+		return
+	}
+	
+	posts := blogService.GetByTag(r.Context(), canonical)
+	if len(posts) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	
+	name := blogService.TagName(canonical)
+	listing := blogListing{
+		Title:      name + " - Blog - Blockhead Consulting",
+		Heading:    name,
+		Subheading: fmt.Sprintf("%d posts tagged %s", len(posts), name),
+		ActiveTag:  canonical,
+	}
+	if len(posts) == 1 {
+		listing.Subheading = "1 post tagged " + name
+	}
+	renderBlogListing(w, r, "page-blog.html", listing, posts, "/blog/tag/"+canonical)
+}
+
+// blogSeriesHandler lists the parts of a series in reading order
+func blogSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	
+	slug := blog.SeriesSlug(name)
+	if slug != name {
+		if slug == "" {
+			http.NotFound(w, r)
+			return
+		}
+		http.Redirect(w, r, localePath(r, blogPagePath("/blog/series/"+slug, listingPageNumber(r))), http.StatusMovedPermanently)
+		return
+	}
+	
+	series, err := blogService.GetSeries(r.Context(), slug)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	
+	listing := blogListing{
+		Title:      series.Name + " - Blog - Blockhead Consulting",
+		Heading:    series.Name,
+		Subheading: fmt.Sprintf("A %d-part series.", len(series.Posts)),
+		ActiveTag:  "all",
+	}
+	renderBlogListing(w, r, "page-blog.html", listing, series.Posts, "/blog/series/"+slug)
+}
+
+// blogAuthorHandler lists the posts credited to a registered author
+func blogAuthorHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	
+	slug := blog.AuthorSlug(id)
+	if slug != id {
+		if slug == "" {
+			http.NotFound(w, r)
+			return
+		}
+		http.Redirect(w, r, localePath(r, blogPagePath("/blog/author/"+slug, listingPageNumber(r))), http.StatusMovedPermanently)
+		return
+	}
+	
+	author, err := blogService.GetAuthor(r.Context(), slug)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	
+	// Authors can be linked by name, their page lives at their ID
+	if author.ID != slug {
+		http.Redirect(w, r, localePath(r, blogPagePath("/blog/author/"+author.ID, listingPageNumber(r))), http.StatusMovedPermanently)
+		return
+	}
+	
+	posts := blogService.GetByAuthor(r.Context(), slug)
+	listing := blogListing{
+		Title:      author.Name + " - Blog - Blockhead Consulting",
+		Heading:    author.Name,
+		Subheading: author.Bio,
+		ActiveTag:  "all",
+		Author:     author,
+	}
+	if listing.Subheading == "" {
+		listing.Subheading = fmt.Sprintf("%d posts by %s", len(posts), author.Name)
+	}
+	renderBlogListing(w, r, "page-blog.html", listing, posts, "/blog/author/"+slug)
+}
+
+// blogArchiveHandler lists the posts published in a year or month
+func blogArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	
+	year, err := strconv.Atoi(vars["year"])
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	
+	var month time.Month
+	basePath := fmt.Sprintf("/blog/%04d/", year)
+	period := strconv.Itoa(year)
+	if vars["month"] != "" {
+		m, err := strconv.Atoi(vars["month"])
+		if err != nil || m < 1 || m > 12 {
+			http.NotFound(w, r)
+			return
+		}
+		month = time.Month(m)
+		basePath = fmt.Sprintf("/blog/%04d/%02d/", year, m)
+		period = month.String() + " " + period
+	}
+	
+	posts := blogService.GetByDate(r.Context(), year, month)
+	if len(posts) == 0 {
+		http.NotFound(w, r)
+		return
 	}
+	
+	listing := blogListing{
+		Title:      "Posts from " + period + " - Blog - Blockhead Consulting",
+		Heading:    "Posts from " + period,
+		Subheading: "Archive of everything published in " + period + ".",
+		ActiveTag:  "all",
+	}
+	renderBlogListing(w, r, "page-blog.html", listing, posts, basePath)
+}
+
+// trailingSlashRedirect sends archive URLs typed without a trailing slash to their canonical form
+func trailingSlashRedirect(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+}
 
-	if err := templates.ExecuteTemplate(w, "page-blog.html", data); err != nil {
+// renderBlogListing renders the requested page of a post listing rooted at basePath
+func renderBlogListing(w http.ResponseWriter, r *http.Request, templateName string, listing blogListing, posts []blog.Post, basePath string) {
+	pageNumber := listingPageNumber(r)
+	
+	// The first page lives at the listing's own URL
+	if pageNumber == 1 && mux.Vars(r)["page"] != "" {
+		http.Redirect(w, r, localePath(r, basePath), http.StatusMovedPermanently)
+		return
+	}
+	
+	pagePosts, pagination, ok := blog.Paginate(posts, pageNumber, blogPostsPerPage)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	
+	listing.Page = "blog"
+	listing.Path = localePath(r, blogPagePath(basePath, pageNumber))
+	listing.Posts = pagePosts
+	listing.Pagination = pagination
+	listing.Config = requestConfig(r)
+	listing.AppConfig = requestAppConfig(r)
+	listing.WorkConfig = workConfig
+	listing.BlogConfig = blogService.GetBlogConfig()
+	if pagination.HasPrev() {
+		listing.PrevURL = localePath(r, blogPagePath(basePath, pageNumber-1))
+	}
+	if pagination.HasNext() {
+		listing.NextURL = localePath(r, blogPagePath(basePath, pageNumber+1))
+	}
+	if pageNumber > 1 {
+		listing.Title = fmt.Sprintf("Page %d - %s", pageNumber, listing.Title)
+	}
+	
+	// Tag, series and archive pages describe themselves, the blog uses its subtitle
+	description := listing.Subheading
+	if description == "" {
+		description = listing.BlogConfig.Blog.Subtitle
+	}
+	listing.Meta = siteSEO(r).Page(listing.Title, description, blogPagePath(basePath, pageNumber))
+	
+	// An emptied search box asks for just the posts and pagination
+	if resultsOnly, _ := r.Context().Value(listingResultsKey{}).(bool); resultsOnly {
+		templateName = "blog-results"
+	}
+	
+	w.Header().Set("Content-Type", "text/html")
+	
+	if err := templates.ExecuteTemplate(w, templateName, listing); err != nil {
 		log.Printf("Template execution error: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
 
+// listingPageNumber returns the page requested in the URL, defaulting to the first
+func listingPageNumber(r *http.Request) int {
+	page, err := strconv.Atoi(mux.Vars(r)["page"])
+	if err != nil {
+		return 1
+	}
+	return page
+}
+
+// blogPagePath returns the URL of a page of the listing at basePath
+func blogPagePath(basePath string, page int) string {
+	if page <= 1 {
+		return basePath
+	}
+	
+	path := strings.TrimSuffix(basePath, "/") + "/page/" + strconv.Itoa(page)
+	if strings.HasSuffix(basePath, "/") {
+		path += "/"
+	}
+	return path
+}
+
+// maxSearchQueryLength bounds the work a single search request can cause
+const maxSearchQueryLength = 200
+
+// listingResultsKey marks a request for a blog listing that renders only its
+// posts and pagination, for the search box to swap back in
+type listingResultsKey struct{}
+
+// blogSearchHandler returns the matching post cards as an HTMX fragment. An
+// empty query returns the listing the search box is on instead.
+func blogSearchHandler(router *mux.Router) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if len(query) > maxSearchQueryLength {
+			http.Error(w, "Search query too long", http.StatusBadRequest)
+			return
+		}
+		
+		if query == "" {
+			serveListingResults(router, w, r)
+			return
+		}
+		
+		data := struct {
+			Query   string
+			Results []blog.SearchResult
+			Config  *SiteConfig
+		}{
+			Query:   query,
+			Results: blogService.SearchResults(r.Context(), query),
+			Config:  requestConfig(r),
+		}
+		
+		w.Header().Set("Content-Type", "text/html")
+		
+		if err := templates.ExecuteTemplate(w, "blog-search-results", data); err != nil {
+			log.Printf("Template execution error: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+	}
+}
+
+// serveListingResults serves the posts and pagination of the blog listing
+// named by the from parameter, at the page and tag it was on. Anything but
+// a path under the blog of the request's locale gets the blog's first page.
+func serveListingResults(router *mux.Router, w http.ResponseWriter, r *http.Request) {
+	blogPath := localePath(r, "/blog")
+	from := r.URL.Query().Get("from")
+	if from != blogPath && !strings.HasPrefix(from, blogPath+"/") {
+		from = blogPath
+	}
+	
+	listing := r.Clone(context.WithValue(r.Context(), listingResultsKey{}, true))
+	listing.URL = &url.URL{Path: from}
+	listing.RequestURI = from
+	router.ServeHTTP(w, listing)
+}
+
 func blogPostHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	slug := vars["slug"]
@@ -384,6 +974,37 @@ func blogPostHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	renderBlogPost(w, r, servicePost, false)
+}
+
+// blogPreviewHandler renders an unpublished post for holders of a signed preview link
+func blogPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+	query := r.URL.Query()
+	
+	if err := previewSigner.Verify(slug, query.Get("expires"), query.Get("sig")); err != nil {
+		log.Printf("SECURITY: Rejected preview link for '%s' from %s: %v", slug, security.ExtractClientIP(r), err)
+		http.Error(w, "Preview link is invalid or has expired", http.StatusForbidden)
+		return
+	}
+	
+	post, err := blogService.GetPreview(r.Context(), slug)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	
+	// Previews must never end up in search results or shared caches
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	w.Header().Set("Cache-Control", "private, no-store")
+	renderBlogPost(w, r, post, true)
+}
+
+// relatedPostCount is the number of related posts suggested below a post
+const relatedPostCount = 3
+
+// renderBlogPost executes the blog post template for a service post
+func renderBlogPost(w http.ResponseWriter, r *http.Request, servicePost *blog.Post, preview bool) {
 	// Convert service post to legacy BlogPost structure for template compatibility
 	post := &BlogPost{
 		Slug:        servicePost.Slug,
@@ -391,6 +1012,9 @@ func blogPostHandler(w http.ResponseWriter, r *http.Request) {
 		Date:        servicePost.Date,
 		Summary:     servicePost.Summary,
 		Content:     template.HTML(servicePost.Content),
+		TOC:         servicePost.TOCHTML(),
+		Revisions:   servicePost.Revisions,
+		Authors:     servicePost.Authors,
 		ReadingTime: servicePost.ReadingTime,
 		Tags:        servicePost.Tags,
 		FileName:    servicePost.FileName,
@@ -399,15 +1023,39 @@ func blogPostHandler(w http.ResponseWriter, r *http.Request) {
 	data := struct {
 		Title     string
 		Page      string
+		Meta      seo.Meta
 		Post      *BlogPost
+		Related   []blog.Post
+		Series    *blog.SeriesPosition
+		Preview   bool
 		Config    *SiteConfig
 		AppConfig *config.SiteConfig
 	}{
 		Title:     post.Title + " - Blockhead Consulting",
 		Page:      "blog",
+		Meta:      siteSEO(r).Post(servicePost),
 		Post:      post,
-		Config:    siteConfig,
-		AppConfig: appConfig,
+		Related:   blogService.RelatedTo(r.Context(), post.Slug, relatedPostCount),
+		Preview:   preview,
+		Config:    requestConfig(r),
+		AppConfig: requestAppConfig(r),
+	}
+	
+	data.Meta.NoIndex = preview
+	if servicePost.WasUpdated() {
+		post.Updated = servicePost.Updated
+	}
+	if servicePost.Image == "" && postCards != nil && !preview {
+		data.Meta.Image = siteSEO(r).URL(locales.Path(servicePost.Lang, postCardPath(servicePost.Slug)))
+	}
+	
+	// Previous/next navigation for multi-part series
+	if servicePost.Series != "" {
+		if series, err := blogService.GetSeries(r.Context(), servicePost.Series); err == nil {
+			if position, ok := series.Position(post.Slug); ok {
+				data.Series = &position
+			}
+		}
 	}
 
 	if err := templates.ExecuteTemplate(w, "blog-post.html", data); err != nil {
@@ -416,17 +1064,142 @@ func blogPostHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Number of posts included in each syndication feed
+const feedItemLimit = 20
+
+// blogFeedHandler serves the full blog feed in the given format
+func blogFeedHandler(format feed.Format) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		posts := blogService.GetAll(r.Context())
+		writeBlogFeed(w, r, format, posts, "", "/blog")
+	}
+}
+
+// blogTagFeedHandler serves an RSS feed of posts carrying a single tag
+func blogTagFeedHandler(w http.ResponseWriter, r *http.Request) {
+	// Aliases and differently written tags get the canonical tag's feed
+	canonical := blogService.CanonicalTag(mux.Vars(r)["tag"])
+	if canonical == "" {
+		http.NotFound(w, r)
+		return
+	}
+	
+	posts := blogService.GetByTag(r.Context(), canonical)
+	if len(posts) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	
+	writeBlogFeed(w, r, feed.FormatRSS, posts, " - "+blogService.TagName(canonical), "/blog/tag/"+canonical)
+}
+
+// blogAuthorFeedHandler serves an RSS feed of the posts credited to a registered author
+func blogAuthorFeedHandler(w http.ResponseWriter, r *http.Request) {
+	author, err := blogService.GetAuthor(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	
+	posts := blogService.GetByAuthor(r.Context(), author.ID)
+	writeBlogFeed(w, r, feed.FormatRSS, posts, " - "+author.Name, "/blog/author/"+author.ID)
+}
+
+// writeBlogFeed renders posts as a feed document and writes it to the response
+func writeBlogFeed(w http.ResponseWriter, r *http.Request, format feed.Format, posts []blog.Post, titleSuffix, pagePath string) {
+	blogConfig := blogService.GetBlogConfig()
+	
+	siteName := requestConfig(r).SiteName
+	f := feed.FromPosts(feed.Options{
+		Title:       siteName + " - " + blogConfig.Blog.Title + titleSuffix,
+		Description: blogConfig.Blog.Subtitle,
+		BaseURL:     siteBaseURL(r),
+		Path:        localePath(r, pagePath),
+		Prefix:      locales.Prefix(requestLocale(r)),
+		FeedPath:    r.URL.Path,
+		Author:      siteName,
+		Language:    requestLocale(r),
+		Limit:       feedItemLimit,
+	}, posts)
+	
+	body, err := f.Render(format)
+	if err != nil {
+		log.Printf("Feed rendering error: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Cache-Control", "public, max-age=900")
+	w.Write(body)
+}
+
+// siteSEO returns the site-wide defaults of page metadata in the locale of
+// the request
+func siteSEO(r *http.Request) seo.Site {
+	site := seo.Site{
+		Name:      requestConfig(r).SiteName,
+		BaseURL:   siteBaseURL(r),
+		Lang:      requestLocale(r),
+		Languages: locales,
+	}
+	if appConfig := requestAppConfig(r); appConfig != nil {
+		site.Description = appConfig.Site.Description
+		site.Author = appConfig.Site.Author
+		site.Twitter = appConfig.Site.Twitter
+		site.Image = appConfig.Site.Image
+		if site.Image == "" {
+			site.Image = appConfig.About.ProfileImage
+		}
+	}
+	return site
+}
+
+// siteBaseURL returns the absolute origin of the site, the configured base
+// URL. Without one the request's host is only used when it's local, so a
+// forged Host header can't point feeds, sitemaps and canonical links at
+// another site.
+func siteBaseURL(r *http.Request) string {
+	if appConfig != nil && appConfig.Site.BaseURL != "" {
+		return appConfig.Site.BaseURL
+	}
+	
+	if isLocalHost(r.Host) {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		return scheme + "://" + r.Host
+	}
+	return "http://localhost:" + getEnv("PORT", "8085")
+}
+
+// isLocalHost reports whether a Host header names this machine
+func isLocalHost(host string) bool {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	host = strings.Trim(host, "[]")
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 func calendarHandler(w http.ResponseWriter, r *http.Request) {
 	data := struct {
 		Title     string
 		Page      string
+		Meta      seo.Meta
 		Config    *SiteConfig
 		AppConfig *config.SiteConfig
 	}{
 		Title:     "Book a Consultation - Blockhead Consulting",
 		Page:      "calendar",
-		Config:    siteConfig,
-		AppConfig: appConfig,
+		Meta:      siteSEO(r).Page("Book a Consultation", "", "/calendar"),
+		Config:    requestConfig(r),
+		AppConfig: requestAppConfig(r),
 	}
 
 	if err := templates.ExecuteTemplate(w, "page-calendar.html", data); err != nil {
@@ -542,6 +1315,64 @@ func contactHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func adminSlotsHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	if r.Method == "POST" {
+		// Handle slot updates
+		// Implementation depends on your needs
+	}
+
+	// Return admin interface
+	w.Header().Set("Content-Type", "text/html")
+	fmt.Fprintf(w, "<h1>Admin Interface</h1><p>Slots: %d</p>", len(timeSlots))
+}
+
+// Default and maximum lifetime of a draft preview link
+const (
+	defaultPreviewTTL = 72 * time.Hour
+	maxPreviewTTL     = 30 * 24 * time.Hour
+)
+
+// adminPreviewHandler mints a signed, expiring preview URL for an unpublished post
+func adminPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	
+	slug := r.URL.Query().Get("slug")
+	if _, err := blogService.GetPreview(r.Context(), slug); err != nil {
+		http.Error(w, "Unknown blog post", http.StatusNotFound)
+		return
+	}
+	
+	ttl := defaultPreviewTTL
+	if raw := r.URL.Query().Get("ttl"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 || parsed > maxPreviewTTL {
+			http.Error(w, "Invalid ttl (use a duration such as 48h, up to 720h)", http.StatusBadRequest)
+			return
+		}
+		ttl = parsed
+	}
+	
+	expires := time.Now().Add(ttl).Truncate(time.Second)
+	previewURL := siteBaseURL(r) + previewSigner.PreviewPath(slug, expires)
+	log.Printf("ADMIN: Issued preview link for '%s' expiring %s", slug, expires.Format(time.RFC3339))
+	
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]string{
+		"slug":    slug,
+		"url":     previewURL,
+		"expires": expires.UTC().Format(time.RFC3339),
+	})
+}
+
+// requireAdmin enforces HTTP basic auth against the configured admin credentials.
+// It writes the error response and returns false when the request is not authorized.
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
 	// Get admin credentials from environment variables
 	expectedUser := os.Getenv("ADMIN_USERNAME")
 	expectedPass := os.Getenv("ADMIN_PASSWORD")
@@ -550,7 +1381,7 @@ func adminSlotsHandler(w http.ResponseWriter, r *http.Request) {
 	if expectedUser == "" || expectedPass == "" {
 		log.Printf("SECURITY: Admin credentials not configured in environment variables")
 		http.Error(w, "Admin interface is not configured", http.StatusServiceUnavailable)
-		return
+		return false
 	}
 	
 	// Basic auth check
@@ -559,17 +1390,10 @@ func adminSlotsHandler(w http.ResponseWriter, r *http.Request) {
 		subtle.ConstantTimeCompare([]byte(password), []byte(expectedPass)) != 1 {
 		w.Header().Set("WWW-Authenticate", `Basic realm="Admin"`)
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	if r.Method == "POST" {
-		// Handle slot updates
-		// Implementation depends on your needs
+		return false
 	}
-
-	// Return admin interface
-	w.Header().Set("Content-Type", "text/html")
-	fmt.Fprintf(w, "<h1>Admin Interface</h1><p>Slots: %d</p>", len(timeSlots))
+	
+	return true
 }
 
 // Health check handler for Docker and monitoring
@@ -588,7 +1412,17 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 	if blogService != nil {
 		ctx := r.Context()
 		posts := blogService.GetAll(ctx)
-		status["services"].(map[string]string)["blog"] = fmt.Sprintf("ok (%d posts)", len(posts))
+		blogStatus := blogService.Status()
+		status["services"].(map[string]string)["blog"] = fmt.Sprintf("ok (%d posts, generation %d)", len(posts), blogStatus.Generation)
+		if len(blogStatus.Errors) > 0 {
+			status["services"].(map[string]string)["blog"] = fmt.Sprintf("degraded: %d posts failed to load (generation %d)", len(blogStatus.Errors), blogStatus.Generation)
+		}
+		if checker, ok := blogService.(interface{ Health(context.Context) error }); ok {
+			if err := checker.Health(ctx); err != nil {
+				status["services"].(map[string]string)["blog"] = fmt.Sprintf("unhealthy: %v", err)
+			}
+		}
+		status["blog"] = blogStatus
 	}
 	
 	// Check if contact service is working
@@ -642,6 +1476,14 @@ func initializeSecurity() {
 		SessionTimeout: 30 * time.Minute,
 		MaxRequestSize: 1 << 20, // 1MB
 	}
+	
+	// Raw markdown and llms.txt are fetched by scrapers rather than people
+	machineLimiter = security.NewRateLimiter(&security.RateLimiterConfig{
+		MaxRequests:   getEnvInt("MACHINE_RATE_LIMIT", 60),
+		Window:        time.Minute,
+		CleanupPeriod: 5 * time.Minute,
+		BlockDuration: time.Minute, // a client over the limit waits out one window
+	})
 }
 
 // Legacy rate limiter methods - replaced by security package
@@ -901,6 +1743,10 @@ func initializeConfig() {
 		siteConfig.HeroStyle = "professional"
 	}
 	
+	if appConfig.Site.BaseURL == "" {
+		log.Printf("Warning: site.base_url is not set in site.yml, absolute links only work locally")
+	}
+	
 	log.Printf("CONFIG: Loaded from site.yml - %s", appConfig.Site.Name)
 	log.Printf("CONFIG: Calendar enabled: %v", siteConfig.CalendarEnabled)
 	log.Printf("CONFIG: Blog enabled: %v", siteConfig.BlogEnabled)
@@ -917,6 +1763,39 @@ func initializeConfig() {
 	}
 }
 
+// initializeLocales loads the UI strings of every locale in content/i18n and
+// the site.<lang>.yml overlay of each locale served under a prefix
+func initializeLocales() {
+	logger := log.New(os.Stdout, "[i18n] ", log.LstdFlags)
+	
+	defaultLocale := i18n.DefaultLocale
+	if appConfig != nil {
+		defaultLocale = appConfig.Site.Language
+	}
+	
+	var err error
+	locales, err = i18n.Load(i18nFS, "content/i18n", defaultLocale, logger)
+	if err != nil {
+		log.Printf("Warning: Failed to load UI strings, serving %s only: %v", defaultLocale, err)
+		locales = i18n.New(defaultLocale, nil, nil)
+	}
+	siteConfig.Locale = locales.Default()
+	
+	localeConfigs = make(map[string]*config.SiteConfig)
+	if appConfig == nil {
+		return
+	}
+	for _, locale := range locales.Others() {
+		localized, err := configService.LoadLocaleConfig("content/site.yml", locale.Code)
+		if err != nil {
+			log.Printf("Warning: Failed to load %s site configuration: %v", locale.Code, err)
+			continue
+		}
+		localeConfigs[locale.Code] = localized
+	}
+	log.Printf("CONFIG: Locales: %d, default %s", len(locales.Locales()), locales.Default())
+}
+
 // Legacy configuration fallback
 func initializeLegacyConfig() {
 	calendarEnabled := true // Default to enabled
@@ -960,16 +1839,110 @@ func initializeLegacyConfig() {
 		ConsoleLogging:  getEnvBool("CONSOLE_LOGGING", environment == "development"),
 		CSPNonce:        securityConfig.CSPNonce,
 	}
-	
-	log.Printf("CONFIG: Using legacy environment variables")
-	log.Printf("CONFIG: Calendar enabled: %v", siteConfig.CalendarEnabled)
-	log.Printf("CONFIG: Blog enabled: %v", siteConfig.BlogEnabled)
-	log.Printf("CONFIG: Environment: %s", siteConfig.Environment)
-	log.Printf("CONFIG: Hero style: %s", siteConfig.HeroStyle)
-	log.Printf("CONFIG: Console logging: %v", siteConfig.ConsoleLogging)
+	
+	log.Printf("CONFIG: Using legacy environment variables")
+	log.Printf("CONFIG: Calendar enabled: %v", siteConfig.CalendarEnabled)
+	log.Printf("CONFIG: Blog enabled: %v", siteConfig.BlogEnabled)
+	log.Printf("CONFIG: Environment: %s", siteConfig.Environment)
+	log.Printf("CONFIG: Hero style: %s", siteConfig.HeroStyle)
+	log.Printf("CONFIG: Console logging: %v", siteConfig.ConsoleLogging)
+}
+
+// rendererOnce guards the creation of the shared renderer, which request
+// handlers may ask for
+var rendererOnce sync.Once
+
+// initializeRenderer builds the shared markdown renderer the first time
+// it's called
+func initializeRenderer() {
+	rendererOnce.Do(createRenderer)
+}
+
+// createRenderer builds the markdown renderer. Diagrams are served from the
+// on-disk cache; on a miss they're rendered with the installed CLIs unless
+// DIAGRAM_RENDER is false, as in production where the cache is filled ahead
+// of time by cmd/prerender-diagrams.
+func createRenderer() {
+	logger := log.New(os.Stdout, "[render] ", log.LstdFlags)
+	diagrams := render.NewDiagrams(render.DiagramOptions{
+		Cache:     render.NewDiagramCache(getEnv("DIAGRAM_CACHE_DIR", "data/diagram-cache"), int64(getEnvInt("DIAGRAM_CACHE_MAX_MB", 64))<<20),
+		CacheOnly: !getEnvBool("DIAGRAM_RENDER", true),
+		Logger:    logger,
+	}, render.DefaultDiagramRenderers()...)
+	
+	staticFiles, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		log.Fatalf("Failed to create static file sub-filesystem: %v", err)
+	}
+	siteImages = render.NewImages(render.ImageOptions{
+		Static: staticFiles,
+		Logger: logger,
+	})
+	
+	siteRenderer = render.New(render.Options{
+		HeadingAnchors: true,
+		Sanitize:       true,
+		Diagrams:       diagrams,
+		Images:         siteImages,
+	})
+}
+
+// chromaStylesheet is the code highlighting stylesheet with the blog.yml it
+// was generated from, so it follows blog.yml when the blog reloads
+type chromaStylesheet struct {
+	mu     sync.Mutex
+	config *blog.BlogConfig
+	css    []byte
+}
+
+// initializeChromaCSS generates the code highlighting stylesheet at startup,
+// so unknown styles in blog.yml are reported right away
+func initializeChromaCSS() {
+	chromaCSS.current()
+}
+
+// current returns the stylesheet for the styles named in blog.yml, generating
+// it again when blog.yml has changed and falling back to the defaults if
+// they're unknown
+func (c *chromaStylesheet) current() []byte {
+	var config *blog.BlogConfig
+	if blogService != nil {
+		config = blogService.GetBlogConfig()
+	}
+	
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.css != nil && c.config == config {
+		return c.css
+	}
+	
+	var light, dark string
+	if config != nil {
+		light, dark = config.Blog.Code.LightStyle, config.Blog.Code.DarkStyle
+	}
+	css, err := render.ChromaCSS(light, dark)
+	if err != nil {
+		log.Printf("Warning: %v, using the default code styles", err)
+		css, err = render.ChromaCSS("", "")
+		if err != nil {
+			log.Fatalf("Failed to generate code highlighting styles: %v", err)
+		}
+	}
+	c.config, c.css = config, css
+	return css
+}
+
+// chromaCSSHandler serves the generated code highlighting stylesheet
+func chromaCSSHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(chromaCSS.current())
 }
 
 func initializeBlogService() error {
+	initializeRenderer()
+	contentHistory = history.New(history.Options{Logger: log.New(os.Stdout, "[history] ", log.LstdFlags)})
+	
 	// Skip if blog is disabled
 	if !siteConfig.BlogEnabled {
 		log.Printf("Blog disabled - skipping blog service initialization")
@@ -982,8 +1955,16 @@ func initializeBlogService() error {
 	// Create event bus
 	eventBus := events.NewInMemoryEventBus(5, logger)
 	
-	// Create blog service
-	blogService = blog.NewService(blogFS, logger, eventBus)
+	// Create blog service, reading posts from disk when BLOG_CONTENT_DIR is set
+	// so edits show up without a rebuild
+	blogService = blog.NewServiceWithOptions(blogFS, "content/blog", logger, eventBus,
+		blog.WithRenderer(siteRenderer),
+		blog.WithHistory(contentHistory),
+		blog.WithContentDir(getEnv("BLOG_CONTENT_DIR", "")),
+		blog.WithWatchInterval(getEnvDuration("BLOG_WATCH_INTERVAL", blog.DefaultWatchInterval)),
+		blog.WithLocales(locales.Default(), localeCodes(locales.Others())...),
+	)
+	previewSigner = blog.NewPreviewSigner(previewSecret())
 	
 	// Initialize email service
 	emailConfig := &email.EmailConfig{
@@ -1043,7 +2024,10 @@ func initializeBlogService() error {
 	
 	// Initialize bio service
 	bioLogger := log.New(os.Stdout, "[bio] ", log.LstdFlags)
-	bioService = bio.NewService(bioLogger)
+	bioService = bio.NewService(bioLogger, siteRenderer, bio.WithHistory(contentHistory))
+	
+	// Preview images are drawn whenever posts load
+	initializePostCards(eventBus)
 	
 	// Start services
 	ctx := context.Background()
@@ -1078,6 +2062,268 @@ func initializeBlogService() error {
 	return nil
 }
 
+// initializePostCards sets up the generated preview images of posts and
+// redraws them whenever posts load, so they're ready before crawlers ask
+func initializePostCards(eventBus events.EventBus) {
+	staticFiles, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		log.Fatalf("Failed to create static file sub-filesystem: %v", err)
+	}
+	opts := ogimage.Options{
+		SiteName: siteConfig.SiteName,
+		Static:   staticFiles,
+		Logger:   log.New(os.Stdout, "[ogimage] ", log.LstdFlags),
+	}
+	if appConfig != nil {
+		opts.SiteName = appConfig.Site.Name
+		opts.Logo = appConfig.Branding.LogoImage
+		opts.PrimaryColor = appConfig.Branding.PrimaryColor
+		opts.SecondaryColor = appConfig.Branding.SecondaryColor
+	}
+	postCards = ogimage.New(opts)
+	
+	eventBus.Subscribe(events.EventBlogPublished, func(ctx context.Context, event events.Event) error {
+		posts := blogService.GetAll(ctx)
+		cards := make([]ogimage.Card, len(posts))
+		for i := range posts {
+			cards[i] = postCard(ctx, &posts[i])
+		}
+		postCards.Generate(cards)
+		return nil
+	})
+}
+
+// postCard is what the preview image of a post shows. The card fonts have
+// no CJK glyphs, so translations titled in such scripts show the title of
+// the original post instead.
+func postCard(ctx context.Context, post *blog.Post) ogimage.Card {
+	card := ogimage.Card{Title: post.Title, Date: post.Date, Tags: post.Tags}
+	if locales != nil && post.Lang != locales.Default() && !ogimage.CanDraw(card.Title) {
+		original, err := blogService.GetBySlug(i18n.WithLocale(ctx, locales.Default()), post.Slug)
+		if err == nil && original != nil {
+			card.Title = original.Title
+		}
+	}
+	return card
+}
+
+// postCardPath is where the generated preview image of a post is served
+func postCardPath(slug string) string {
+	return "/blog/" + slug + "/og.png"
+}
+
+// blogCardHandler serves the generated preview image of a published post
+func blogCardHandler(w http.ResponseWriter, r *http.Request) {
+	if blogService == nil || postCards == nil {
+		http.NotFound(w, r)
+		return
+	}
+	post, err := blogService.GetBySlug(r.Context(), mux.Vars(r)["slug"])
+	if err != nil || post == nil {
+		http.NotFound(w, r)
+		return
+	}
+	
+	card := postCard(r.Context(), post)
+	etag := `"` + postCards.Key(card) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	
+	data, err := postCards.Render(card)
+	if err != nil {
+		log.Printf("ERROR: Preview image for %s: %v", post.Slug, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(data)
+}
+
+// postMarkdownPath is where the markdown source of a post is served
+func postMarkdownPath(slug string) string {
+	return "/blog/" + slug + ".md"
+}
+
+// blogMarkdownHandler serves the markdown source of a published post with
+// normalized frontmatter, in the locale of the path like the post's page
+func blogMarkdownHandler(w http.ResponseWriter, r *http.Request) {
+	if blogService == nil {
+		http.NotFound(w, r)
+		return
+	}
+	post, err := blogService.GetBySlug(r.Context(), mux.Vars(r)["slug"])
+	if err != nil || post == nil {
+		http.NotFound(w, r)
+		return
+	}
+	
+	site := siteSEO(r)
+	canonical := site.URL(locales.Path(post.Lang, "/blog/"+post.Slug))
+	body, err := llms.Post(*post, site.BaseURL, canonical)
+	if err != nil {
+		log.Printf("ERROR: Markdown source of %s: %v", post.Slug, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	
+	// The HTML page stays the version search engines index
+	w.Header().Set("Link", "<"+canonical+`>; rel="canonical"`)
+	writeMachineReadable(w, r, "text/markdown; charset=utf-8", body)
+}
+
+// llmsHandler serves llms.txt, the index of the site for language models, or
+// with full set llms-full.txt, which inlines every document it links to
+func llmsHandler(full bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body []byte
+		if full {
+			body = llmsFull.current(r)
+		} else {
+			body = llmsIndex(r).Text()
+		}
+		writeMachineReadable(w, r, "text/plain; charset=utf-8", body)
+	}
+}
+
+// llmsFullText is llms-full.txt with what it was built from: the blog
+// generation, the number of published posts, which grows as scheduled posts
+// go out, and the base URL of its links
+type llmsFullText struct {
+	mu         sync.Mutex
+	generation uint64
+	published  int
+	baseURL    string
+	body       []byte
+}
+
+// current returns llms-full.txt, inlining every post again only when the
+// blog has reloaded or published a scheduled post since it was last built
+func (l *llmsFullText) current(r *http.Request) []byte {
+	var generation uint64
+	var published int
+	if siteConfig.BlogEnabled && blogService != nil {
+		generation = blogService.Status().Generation
+		published = len(blogService.GetAll(r.Context()))
+	}
+	baseURL := siteBaseURL(r)
+	
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.body != nil && l.generation == generation && l.published == published && l.baseURL == baseURL {
+		return l.body
+	}
+	l.generation, l.published, l.baseURL = generation, published, baseURL
+	l.body = llmsIndex(r).Full()
+	return l.body
+}
+
+// llmsIndex describes the site in the default locale: the about and work
+// pages followed by the published posts, linked to their markdown sources
+func llmsIndex(r *http.Request) *llms.Index {
+	ctx := r.Context()
+	site := siteSEO(r)
+	index := &llms.Index{Title: site.Name, Summary: site.Description}
+	if appConfig != nil {
+		index.Details = appConfig.Site.Tagline
+	}
+	
+	var pages []llms.Link
+	if bioService != nil {
+		if bio, err := bioService.GetFull(ctx); err == nil {
+			pages = append(pages, llms.Link{
+				Title: "About",
+				URL:   site.URL("/about"),
+				Notes: bio.Subtitle,
+				Body:  llms.AbsoluteLinks(bio.Markdown, site.BaseURL),
+			})
+		} else {
+			log.Printf("Warning: Could not load bio for llms.txt: %v", err)
+		}
+	}
+	if workConfig != nil {
+		pages = append(pages, llms.Link{
+			Title: "Work",
+			URL:   site.URL("/work"),
+			Notes: workConfig.Intro,
+			Body:  llms.Work(workConfig),
+		})
+	}
+	index.Sections = append(index.Sections, llms.Section{Title: "Pages", Links: pages})
+	
+	if siteConfig.BlogEnabled && blogService != nil {
+		posts := blogService.GetAll(ctx)
+		index.Sections = append(index.Sections, llms.Section{
+			Title: "Blog",
+			Links: llms.PostLinks(posts, site.BaseURL, func(post blog.Post) string {
+				return locales.Path(post.Lang, "/blog/"+post.Slug)
+			}),
+		})
+		index.Sections = append(index.Sections, llms.Section{
+			Title: "Optional",
+			Links: []llms.Link{{Title: "RSS feed", URL: site.URL("/blog/feed.xml"), Notes: "Rendered HTML of recent posts"}},
+		})
+	}
+	return index
+}
+
+// machineReadable applies the policy of the endpoints serving content to
+// machines: their own rate limit on top of the site-wide one
+func machineReadable(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := security.ExtractClientIP(r)
+		if machineLimiter != nil && !machineLimiter.IsAllowed(ip) {
+			retry := machineLimiter.RetryAfter(ip)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+			http.Error(w, "Rate limit exceeded. Please try again later.", http.StatusTooManyRequests)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// writeMachineReadable writes content for machine readers with an ETag of
+// the body, so crawlers revalidating an hour later get a 304 unless it changed
+func writeMachineReadable(w http.ResponseWriter, r *http.Request, contentType string, body []byte) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(body)
+}
+
+// previewSecret returns the HMAC key for draft preview links. Without PREVIEW_SECRET
+// a random key is generated, so links stop working when the server restarts.
+func previewSecret() []byte {
+	if secret := os.Getenv("PREVIEW_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	
+	log.Printf("BLOG: PREVIEW_SECRET not set - preview links will expire on restart")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("Failed to generate preview secret: %v", err)
+	}
+	return secret
+}
+
+// localeCodes returns the codes of locales
+func localeCodes(list []i18n.Locale) []string {
+	codes := make([]string, 0, len(list))
+	for _, locale := range list {
+		codes = append(codes, locale.Code)
+	}
+	return codes
+}
+
 // Deprecated: loadBlogPosts is replaced by initializeBlogService
 func loadBlogPosts() {
 	blogPosts = []BlogPost{}
@@ -1155,22 +2401,11 @@ func loadMarkdownPost(filename string) (*BlogPost, error) {
 }
 
 func parseFrontmatter(content []byte) (*BlogFrontmatter, []byte, error) {
-	// Check if content starts with frontmatter delimiter
-	if !bytes.HasPrefix(content, []byte("---\n")) {
-		return nil, nil, fmt.Errorf("missing frontmatter delimiter")
-	}
-	
-	// Find the end of frontmatter
-	endDelimiter := []byte("\n---\n")
-	endIndex := bytes.Index(content[4:], endDelimiter)
-	if endIndex == -1 {
-		return nil, nil, fmt.Errorf("missing frontmatter end delimiter")
+	frontmatterBytes, markdownContent, err := render.SplitFrontmatter(content)
+	if err != nil {
+		return nil, nil, err
 	}
 	
-	// Extract frontmatter and content
-	frontmatterBytes := content[4 : endIndex+4]
-	markdownContent := content[endIndex+8:] // Skip past "\n---\n"
-	
 	// Parse YAML frontmatter
 	var frontmatter BlogFrontmatter
 	if err := yaml.Unmarshal(frontmatterBytes, &frontmatter); err != nil {
@@ -1180,173 +2415,10 @@ func parseFrontmatter(content []byte) (*BlogFrontmatter, []byte, error) {
 	return &frontmatter, markdownContent, nil
 }
 
+// markdownToHTML renders markdown with the site's shared renderer
 func markdownToHTML(mdContent []byte) string {
-	// Configure markdown parser
-	extensions := parser.CommonExtensions | parser.AutoHeadingIDs | parser.NoEmptyLineBeforeBlock
-	p := parser.NewWithExtensions(extensions)
-	
-	// Configure HTML renderer with syntax highlighting
-	htmlFlags := mdhtml.CommonFlags | mdhtml.HrefTargetBlank
-	opts := mdhtml.RendererOptions{
-		Flags: htmlFlags,
-		RenderNodeHook: chromaRenderHook,
-	}
-	renderer := mdhtml.NewRenderer(opts)
-	
-	// Convert markdown to HTML
-	return string(markdown.ToHTML(mdContent, p, renderer))
-}
-
-// chromaRenderHook provides syntax highlighting for code blocks and Mermaid diagram support
-func chromaRenderHook(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
-	if code, ok := node.(*ast.CodeBlock); ok && entering {
-		// Get the language from the code block info
-		language := ""
-		if code.Info != nil {
-			language = string(code.Info)
-		}
-		
-		// Handle Mermaid diagrams
-		if language == "mermaid" {
-			// Try server-side rendering first, fallback to client-side
-			if svgContent := renderMermaidServerSide(string(code.Literal)); svgContent != "" {
-				// Server-side rendering successful
-				w.Write([]byte(`<div class="mermaid-container mermaid-ssr">`))
-				w.Write([]byte(svgContent))
-				w.Write([]byte("</div>"))
-			} else {
-				// Fallback to client-side rendering
-				w.Write([]byte(`<div class="mermaid-container mermaid-csr"><div class="mermaid">`))
-				w.Write(code.Literal)
-				w.Write([]byte("</div></div>"))
-			}
-			return ast.GoToNext, true
-		}
-		
-		// Get lexer for the language
-		lexer := lexers.Get(language)
-		if lexer == nil {
-			lexer = lexers.Fallback
-		}
-		
-		// Configure formatter with cyberpunk theme
-		formatter := html.New(html.WithClasses(true), html.TabWidth(2))
-		style := styles.Get("monokai")
-		if style == nil {
-			style = styles.Fallback
-		}
-		
-		// Create iterator from code content
-		iterator, err := lexer.Tokenise(nil, string(code.Literal))
-		if err != nil {
-			// Fallback to plain text
-			w.Write([]byte("<pre><code>"))
-			w.Write(code.Literal)
-			w.Write([]byte("</code></pre>"))
-			return ast.GoToNext, true
-		}
-		
-		// Format the code
-		err = formatter.Format(w, style, iterator)
-		if err != nil {
-			// Fallback to plain text
-			w.Write([]byte("<pre><code>"))
-			w.Write(code.Literal)
-			w.Write([]byte("</code></pre>"))
-		}
-		
-		return ast.GoToNext, true
-	}
-	
-	return ast.GoToNext, false
-}
-
-// Cache for server-side rendered Mermaid diagrams
-var mermaidCache = make(map[string]string)
-var mermaidCacheMutex sync.RWMutex
-
-// renderMermaidServerSide attempts to render Mermaid diagram on server-side
-func fileExists(filename string) bool {
-	_, err := os.Stat(filename)
-	return !os.IsNotExist(err)
-}
-
-func renderMermaidServerSide(mermaidCode string) string {
-	// Generate cache key from content hash
-	hash := md5.Sum([]byte(mermaidCode))
-	cacheKey := hex.EncodeToString(hash[:])
-	
-	// Check cache first
-	mermaidCacheMutex.RLock()
-	if cached, exists := mermaidCache[cacheKey]; exists {
-		mermaidCacheMutex.RUnlock()
-		return cached
-	}
-	mermaidCacheMutex.RUnlock()
-	
-	// Create temporary directory for Mermaid CLI
-	tempDir := "data/mermaid-temp"
-	os.MkdirAll(tempDir, 0755)
-	
-	inputFile := filepath.Join(tempDir, cacheKey+".mmd")
-	outputFile := filepath.Join(tempDir, cacheKey+".svg")
-	
-	// Write Mermaid input file
-	if err := os.WriteFile(inputFile, []byte(mermaidCode), 0644); err != nil {
-		log.Printf("Failed to write Mermaid input file: %v", err)
-		return ""
-	}
-	
-	// Try to execute mmdc (Mermaid CLI) with timeout - using simple dark theme for now
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	
-	cmd := exec.CommandContext(ctx, "mmdc", 
-		"-i", inputFile, 
-		"-o", outputFile,
-		"-t", "dark",
-		"-b", "transparent")
-	
-	// Capture both stdout and stderr for debugging
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	
-	if err := cmd.Run(); err != nil {
-		log.Printf("Mermaid CLI failed (falling back to client-side):")
-		log.Printf("  Error: %v", err)
-		log.Printf("  Command: mmdc -i %s -o %s -t dark -b transparent", inputFile, outputFile)
-		log.Printf("  Stdout: %s", stdout.String())
-		log.Printf("  Stderr: %s", stderr.String())
-		log.Printf("  Input file exists: %v", fileExists(inputFile))
-		log.Printf("  Output dir exists: %v", fileExists(filepath.Dir(outputFile)))
-		// Clean up temp files
-		os.Remove(inputFile)
-		os.Remove(outputFile)
-		return ""
-	}
-	
-	// Read generated SVG
-	svgContent, err := os.ReadFile(outputFile)
-	if err != nil {
-		log.Printf("Failed to read generated SVG: %v", err)
-		os.Remove(inputFile)
-		os.Remove(outputFile)
-		return ""
-	}
-	
-	// Clean up temp files
-	os.Remove(inputFile)
-	os.Remove(outputFile)
-	
-	svgString := string(svgContent)
-	
-	// Cache the result
-	mermaidCacheMutex.Lock()
-	mermaidCache[cacheKey] = svgString
-	mermaidCacheMutex.Unlock()
-	
-	return svgString
+	initializeRenderer()
+	return siteRenderer.Render(mdContent).HTML
 }
 
 func calculateReadingTime(text string) int {
@@ -1443,8 +2515,8 @@ func homeContentHandler(w http.ResponseWriter, r *http.Request) {
 		AppConfig *config.SiteConfig
 		BioBrief *bio.Bio
 	}{
-		Config:   siteConfig,
-		AppConfig: appConfig,
+		Config:   requestConfig(r),
+		AppConfig: requestAppConfig(r),
 		BioBrief: bioBrief,
 	}
 
@@ -1457,26 +2529,8 @@ func homeContentHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func blogContentHandler(w http.ResponseWriter, r *http.Request) {
-	data := struct {
-		Posts      []BlogPost
-		Config     *SiteConfig
-		AppConfig  *config.SiteConfig
-		WorkConfig *config.WorkConfig
-		BlogConfig *blog.BlogConfig
-	}{
-		Posts:      blogPosts,
-		Config:     siteConfig,
-		AppConfig:  appConfig,
-		WorkConfig: workConfig,
-		BlogConfig: blogService.GetBlogConfig(),
-	}
-
-	w.Header().Set("Content-Type", "text/html")
-	
-	if err := templates.ExecuteTemplate(w, "blog-content", data); err != nil {
-		log.Printf("Template execution error: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-	}
+	listing := blogListing{ActiveTag: "all"}
+	renderBlogListing(w, r, "blog-content", listing, blogService.GetAll(r.Context()), "/blog")
 }
 
 func aboutHandler(w http.ResponseWriter, r *http.Request) {
@@ -1492,17 +2546,25 @@ func aboutHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	
+	description := ""
+	if appConfig := requestAppConfig(r); appConfig != nil {
+		description = appConfig.About.Subtitle
+	}
+	title := aboutTitle(r, fullBio)
+	
 	data := struct {
 		Title     string
 		Page      string
+		Meta      seo.Meta
 		Config    *SiteConfig
 		AppConfig *config.SiteConfig
 		Bio       *bio.Bio
 	}{
-		Title:     "About Lance Rogers - Blockhead Consulting",
+		Title:     title + " - " + requestConfig(r).SiteName,
 		Page:      "about",
-		Config:    siteConfig,
-		AppConfig: appConfig,
+		Meta:      siteSEO(r).Page(title, description, "/about"),
+		Config:    requestConfig(r),
+		AppConfig: requestAppConfig(r),
 		Bio:       fullBio,
 	}
 
@@ -1513,6 +2575,23 @@ func aboutHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// aboutTitle returns the heading of the About page: the bio's title, the
+// configured one, or the name of the site's default author
+func aboutTitle(r *http.Request, fullBio *bio.Bio) string {
+	if fullBio != nil && fullBio.Title != "" {
+		return fullBio.Title
+	}
+	if appConfig := requestAppConfig(r); appConfig != nil && appConfig.About.Title != "" {
+		return appConfig.About.Title
+	}
+	if blogService != nil {
+		if authors := blogService.GetAuthors(r.Context()); len(authors) > 0 {
+			return "About " + authors[0].Name
+		}
+	}
+	return "About"
+}
+
 func aboutContentHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	
@@ -1531,8 +2610,8 @@ func aboutContentHandler(w http.ResponseWriter, r *http.Request) {
 		AppConfig *config.SiteConfig
 		Bio       *bio.Bio
 	}{
-		Config:    siteConfig,
-		AppConfig: appConfig,
+		Config:    requestConfig(r),
+		AppConfig: requestAppConfig(r),
 		Bio:       fullBio,
 	}
 
@@ -1549,8 +2628,8 @@ func calendarContentHandler(w http.ResponseWriter, r *http.Request) {
 		Config    *SiteConfig
 		AppConfig *config.SiteConfig
 	}{
-		Config:    siteConfig,
-		AppConfig: appConfig,
+		Config:    requestConfig(r),
+		AppConfig: requestAppConfig(r),
 	}
 	
 	w.Header().Set("Content-Type", "text/html")
@@ -1562,17 +2641,24 @@ func calendarContentHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func workHandler(w http.ResponseWriter, r *http.Request) {
+	description := ""
+	if workConfig != nil {
+		description = workConfig.Intro
+	}
+	
 	data := struct {
 		Title      string
 		Page       string
+		Meta       seo.Meta
 		Config     *SiteConfig
 		AppConfig  *config.SiteConfig
 		WorkConfig *config.WorkConfig
 	}{
 		Title:      "Work Experience - Blockhead Consulting",
 		Page:       "work",
-		Config:     siteConfig,
-		AppConfig:  appConfig,
+		Meta:       siteSEO(r).Page("Work Experience", description, "/work"),
+		Config:     requestConfig(r),
+		AppConfig:  requestAppConfig(r),
 		WorkConfig: workConfig,
 	}
 
@@ -1589,8 +2675,8 @@ func workContentHandler(w http.ResponseWriter, r *http.Request) {
 		AppConfig  *config.SiteConfig
 		WorkConfig *config.WorkConfig
 	}{
-		Config:     siteConfig,
-		AppConfig:  appConfig,
+		Config:     requestConfig(r),
+		AppConfig:  requestAppConfig(r),
 		WorkConfig: workConfig,
 	}
 
@@ -1602,6 +2688,239 @@ func workContentHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// localizedPrefixes returns route prefixes along with their variants in
+// every locale served under a prefix
+func localizedPrefixes(prefixes []string) []string {
+	localized := append([]string(nil), prefixes...)
+	for _, locale := range locales.Others() {
+		for _, prefix := range prefixes {
+			localized = append(localized, locales.Path(locale.Code, prefix))
+		}
+	}
+	return localized
+}
+
+// Route prefixes that never belong in the sitemap
+var sitemapExcludedPrefixes = []string{"/content/", "/api/", "/admin/", "/static/", "/images/", "/health", "/blog/search", "/llms"}
+
+func sitemapHandler(router *mux.Router) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sm := buildSitemap(r, router)
+		
+		// Large sites get an index pointing at numbered sitemap parts
+		var body []byte
+		var err error
+		if sm.NeedsIndex() {
+			body, err = sm.Index(sitemapPartPath)
+		} else {
+			body, err = sm.URLSet(1)
+		}
+		
+		writeSitemap(w, body, err)
+	}
+}
+
+func sitemapPartHandler(router *mux.Router) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		part, err := strconv.Atoi(mux.Vars(r)["part"])
+		sm := buildSitemap(r, router)
+		if err != nil || !sm.NeedsIndex() || part < 1 || part > sm.Parts() {
+			http.NotFound(w, r)
+			return
+		}
+		
+		body, err := sm.URLSet(part)
+		writeSitemap(w, body, err)
+	}
+}
+
+func sitemapPartPath(part int) string {
+	return fmt.Sprintf("/sitemap-%d.xml", part)
+}
+
+func writeSitemap(w http.ResponseWriter, body []byte, err error) {
+	if err != nil {
+		log.Printf("Sitemap rendering error: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(body)
+}
+
+// buildSitemap collects every crawlable URL from the router and content services
+func buildSitemap(r *http.Request, router *mux.Router) *sitemap.Sitemap {
+	ctx := r.Context()
+	var urls []sitemap.URL
+	
+	for _, path := range sitemap.StaticRoutes(router, localizedPrefixes(sitemapExcludedPrefixes)) {
+		urls = append(urls, sitemap.URL{
+			Path:       path,
+			LastMod:    routeLastModified(ctx, path),
+			ChangeFreq: "weekly",
+			Priority:   0.8,
+		})
+	}
+	
+	if siteConfig.BlogEnabled && blogService != nil {
+		for _, post := range blogService.GetAll(ctx) {
+			urls = append(urls, sitemap.URL{
+				Path:       "/blog/" + post.Slug,
+				LastMod:    post.LastModified(),
+				ChangeFreq: "monthly",
+				Priority:   0.6,
+			})
+		}
+		
+		// Translations are listed in their locale, untranslated posts only at their original
+		for _, locale := range locales.Others() {
+			for _, post := range blogService.GetAll(i18n.WithLocale(ctx, locale.Code)) {
+				if post.Lang != locale.Code {
+					continue
+				}
+				urls = append(urls, sitemap.URL{
+					Path:       locales.Path(locale.Code, "/blog/"+post.Slug),
+					LastMod:    post.LastModified(),
+					ChangeFreq: "monthly",
+					Priority:   0.6,
+				})
+			}
+		}
+		
+		for _, series := range blogService.GetAllSeries(ctx) {
+			newest := series.Posts[0].Date
+			for _, post := range series.Posts {
+				if post.Date.After(newest) {
+					newest = post.Date
+				}
+			}
+			urls = append(urls, sitemap.URL{
+				Path:       "/blog/series/" + series.Slug,
+				LastMod:    newest,
+				ChangeFreq: "weekly",
+				Priority:   0.5,
+			})
+		}
+		
+		// Author pages change when one of their posts is published
+		for _, author := range blogService.GetAuthors(ctx) {
+			posts := blogService.GetByAuthor(ctx, author.ID)
+			if len(posts) == 0 {
+				continue
+			}
+			urls = append(urls, sitemap.URL{
+				Path:       "/blog/author/" + author.ID,
+				LastMod:    posts[0].Date,
+				ChangeFreq: "weekly",
+				Priority:   0.5,
+			})
+		}
+		
+		// Tag pages and yearly archives change whenever a post is added to them
+		for _, tag := range blogService.GetTags(ctx) {
+			if posts := blogService.GetByTag(ctx, tag); len(posts) > 0 {
+				urls = append(urls, sitemap.URL{
+					Path:       "/blog/tag/" + tag,
+					LastMod:    posts[0].Date,
+					ChangeFreq: "weekly",
+					Priority:   0.4,
+				})
+			}
+		}
+		for _, post := range blogService.GetAll(ctx) {
+			urls = append(urls, sitemap.URL{
+				Path:       fmt.Sprintf("/blog/%04d/", post.Date.Year()),
+				LastMod:    post.Date,
+				ChangeFreq: "monthly",
+				Priority:   0.3,
+			})
+		}
+	}
+	
+	return sitemap.New(siteBaseURL(r), urls)
+}
+
+// routeLastModified returns when the content behind a static route last
+// changed, as reported by the services that render it. Zero when unknown.
+func routeLastModified(ctx context.Context, path string) time.Time {
+	lang, path := locales.Split(path)
+	ctx = i18n.WithLocale(ctx, lang)
+	
+	var site time.Time
+	if localized, ok := localeConfigs[lang]; ok {
+		site = localized.LastMod
+	} else if appConfig != nil {
+		site = appConfig.LastMod
+	}
+	
+	switch path {
+	case "/":
+		if bioService != nil {
+			if brief, err := bioService.GetBrief(ctx); err == nil {
+				return latest(site, brief.LastMod)
+			}
+		}
+		return site
+	case "/about":
+		if bioService != nil {
+			if full, err := bioService.GetFull(ctx); err == nil {
+				return latest(site, full.LastMod)
+			}
+		}
+		return site
+	case "/work":
+		if workConfig != nil {
+			return workConfig.LastMod
+		}
+	case "/calendar":
+		return site
+	case "/blog":
+		if blogService != nil {
+			if posts := blogService.GetAll(ctx); len(posts) > 0 {
+				return posts[0].Date
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// latest returns the latest of some times
+func latest(times ...time.Time) time.Time {
+	var result time.Time
+	for _, t := range times {
+		if t.After(result) {
+			result = t
+		}
+	}
+	return result
+}
+
+func robotsHandler(w http.ResponseWriter, r *http.Request) {
+	rules := sitemap.RobotsRules{
+		Sitemaps: []string{siteBaseURL(r) + "/sitemap.xml"},
+	}
+	
+	if appConfig != nil {
+		rules.Allow = appConfig.Robots.Allow
+		rules.Disallow = append(rules.Disallow, appConfig.Robots.Disallow...)
+		rules.CrawlDelay = appConfig.Robots.CrawlDelay
+	}
+	
+	// Keep crawlers away from features that are switched off
+	if !siteConfig.BlogEnabled {
+		rules.Disallow = append(rules.Disallow, "/blog")
+	}
+	if !siteConfig.CalendarEnabled {
+		rules.Disallow = append(rules.Disallow, "/calendar")
+	}
+	
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(sitemap.Robots(rules))
+}
+
 // Environment variable helper functions
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -1627,3 +2946,12 @@ func getEnvBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
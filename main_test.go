@@ -1,12 +1,19 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"blockhead.consulting/internal/blog"
+	"blockhead.consulting/internal/feed"
+	"blockhead.consulting/internal/i18n"
 	"blockhead.consulting/internal/security"
 	"github.com/gorilla/mux"
 )
@@ -613,4 +620,588 @@ func TestServicesScrollingWorkflow(t *testing.T) {
 			t.Error("Services link from blog missing required navigation attributes")
 		}
 	})
-}
\ No newline at end of file
+}
+
+func TestBlogFeeds(t *testing.T) {
+	r := mux.NewRouter()
+	r.HandleFunc("/blog/feed.xml", blogFeedHandler(feed.FormatRSS)).Methods("GET")
+	r.HandleFunc("/blog/atom.xml", blogFeedHandler(feed.FormatAtom)).Methods("GET")
+	r.HandleFunc("/blog/feed.json", blogFeedHandler(feed.FormatJSON)).Methods("GET")
+	r.HandleFunc("/blog/tag/{tag}/feed.xml", blogTagFeedHandler).Methods("GET")
+	r.HandleFunc("/blog/author/{id}/feed.xml", blogAuthorFeedHandler).Methods("GET")
+
+	testCases := []struct {
+		path           string
+		expectedStatus int
+		contentType    string
+		contains       string
+	}{
+		{"/blog/feed.xml", http.StatusOK, "application/rss+xml; charset=utf-8", "<rss version=\"2.0\""},
+		{"/blog/atom.xml", http.StatusOK, "application/atom+xml; charset=utf-8", "http://www.w3.org/2005/Atom"},
+		{"/blog/feed.json", http.StatusOK, "application/feed+json; charset=utf-8", "https://jsonfeed.org/version/1.1"},
+		{"/blog/tag/golang/feed.xml", http.StatusOK, "application/rss+xml; charset=utf-8", "<category>golang</category>"},
+		{"/blog/tag/no-such-tag/feed.xml", http.StatusNotFound, "", ""},
+		{"/blog/tag/GO/feed.xml", http.StatusOK, "application/rss+xml; charset=utf-8", "Insights - Go</title>"},
+		{"/blog/tag/llm/feed.xml", http.StatusOK, "application/rss+xml; charset=utf-8", "/blog/tag/ai</link>"},
+		{"/blog/author/lance/feed.xml", http.StatusOK, "application/rss+xml; charset=utf-8", "<dc:creator>Lance Rogers</dc:creator>"},
+		{"/blog/author/no-such-author/feed.xml", http.StatusNotFound, "", ""},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != tc.expectedStatus {
+			t.Errorf("%s returned wrong status code: got %v want %v", tc.path, rr.Code, tc.expectedStatus)
+			continue
+		}
+		if tc.contentType != "" && rr.Header().Get("Content-Type") != tc.contentType {
+			t.Errorf("%s returned wrong content type: got %v want %v", tc.path, rr.Header().Get("Content-Type"), tc.contentType)
+		}
+		if tc.contains != "" && !strings.Contains(rr.Body.String(), tc.contains) {
+			t.Errorf("%s response missing %q", tc.path, tc.contains)
+		}
+	}
+}
+
+func TestSitemapAndRobots(t *testing.T) {
+	initializeConfig()
+	r := newRouter()
+
+	req := httptest.NewRequest("GET", "/sitemap.xml", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("sitemap returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	body := rr.Body.String()
+	for _, loc := range []string{"/about</loc>", "/work</loc>", "/blog</loc>", "/blog/claude-code-go-sdk-announcement</loc>"} {
+		if !strings.Contains(body, loc) {
+			t.Errorf("sitemap missing %s", loc)
+		}
+	}
+	if strings.Contains(body, "/content/") || strings.Contains(body, "/health") {
+		t.Error("sitemap should not list HTMX fragments or health endpoints")
+	}
+
+	req = httptest.NewRequest("GET", "/robots.txt", nil)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	robots := rr.Body.String()
+	if !strings.Contains(robots, "Sitemap: ") || !strings.Contains(robots, "/sitemap.xml") {
+		t.Errorf("robots.txt does not reference the sitemap: %s", robots)
+	}
+	if !strings.Contains(robots, "Disallow: /calendar") {
+		t.Error("robots.txt should disallow the disabled calendar feature")
+	}
+}
+
+func TestSiteBaseURL(t *testing.T) {
+	initializeConfig()
+	baseURL := appConfig.Site.BaseURL
+	appConfig.Site.BaseURL = ""
+	defer func() { appConfig.Site.BaseURL = baseURL }()
+
+	testCases := []struct {
+		host string
+		want string
+	}{
+		{"localhost:8085", "http://localhost:8085"},
+		{"127.0.0.1:3000", "http://127.0.0.1:3000"},
+		{"[::1]:8085", "http://[::1]:8085"},
+		{"attacker.example", "http://localhost:" + getEnv("PORT", "8085")},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest("GET", "/sitemap.xml", nil)
+		req.Host = tc.host
+		req.Header.Set("X-Forwarded-Proto", "https")
+		if got := siteBaseURL(req); got != tc.want {
+			t.Errorf("siteBaseURL with Host %s = %s, want %s", tc.host, got, tc.want)
+		}
+	}
+}
+
+func TestBlogSearchHandler(t *testing.T) {
+	testCases := []struct {
+		query          string
+		expectedStatus int
+		contains       string
+	}{
+		{"claude", http.StatusOK, "<mark>"},
+		{"tag:golang", http.StatusOK, "blog-post-card"},
+		{"zzzznotaword", http.StatusOK, "blog-no-results"},
+		{strings.Repeat("a", maxSearchQueryLength+1), http.StatusBadRequest, ""},
+	}
+
+	r := newRouter()
+	for _, tc := range testCases {
+		req := httptest.NewRequest("GET", "/blog/search?q="+url.QueryEscape(tc.query), nil)
+		req.Header.Set("HX-Request", "true")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != tc.expectedStatus {
+			t.Errorf("search %q returned wrong status code: got %v want %v", tc.query, rr.Code, tc.expectedStatus)
+			continue
+		}
+		if tc.contains != "" && !strings.Contains(rr.Body.String(), tc.contains) {
+			t.Errorf("search %q response should contain %q", tc.query, tc.contains)
+		}
+	}
+}
+
+// Clearing the search box swaps the listing it's on back in, not every post
+func TestBlogSearchEmptyQuery(t *testing.T) {
+	r := newRouter()
+	ctx := context.Background()
+
+	testCases := []struct {
+		from  string
+		posts int
+	}{
+		{"/blog", len(blogService.GetAll(ctx))},
+		{"/blog/tag/python", len(blogService.GetByTag(ctx, "python"))},
+		{"https://example.com/blog/tag/python", len(blogService.GetAll(ctx))},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest("GET", "/blog/search?q=&from="+url.QueryEscape(tc.from), nil)
+		req.Header.Set("HX-Request", "true")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("empty search from %s returned wrong status code: got %v want %v", tc.from, rr.Code, http.StatusOK)
+			continue
+		}
+		body := rr.Body.String()
+		if cards := strings.Count(body, `class="blog-post-card"`); cards != tc.posts {
+			t.Errorf("empty search from %s returned %d posts, want %d", tc.from, cards, tc.posts)
+		}
+		if strings.Contains(body, "blog-search") {
+			t.Errorf("empty search from %s should return only the listing's posts and pagination", tc.from)
+		}
+	}
+}
+
+func TestBlogListings(t *testing.T) {
+	r := newRouter()
+
+	posts := blogService.GetAll(context.Background())
+	if len(posts) == 0 {
+		t.Fatal("expected blog posts to be loaded")
+	}
+	newest := posts[0].Date
+
+	testCases := []struct {
+		path           string
+		expectedStatus int
+		location       string
+		contains       string
+	}{
+		{"/blog", http.StatusOK, "", "blog-grid"},
+		{"/blog/page/1", http.StatusMovedPermanently, "/blog", ""},
+		{"/blog/page/99", http.StatusNotFound, "", ""},
+		{"/blog?tag=AI", http.StatusMovedPermanently, "/blog/tag/ai", ""},
+		{"/blog/tag/ai", http.StatusOK, "", "AI/ML"},
+		{"/blog/tag/AI-ML", http.StatusMovedPermanently, "/blog/tag/ai", ""},
+		{"/blog/tag/llm", http.StatusMovedPermanently, "/blog/tag/ai", ""},
+		{"/blog/tag/no-such-tag", http.StatusNotFound, "", ""},
+		{"/blog/series/no-such-series", http.StatusNotFound, "", ""},
+		{"/blog/series/No_Such_Series", http.StatusMovedPermanently, "/blog/series/no-such-series", ""},
+		{"/blog/author/lance", http.StatusOK, "", "/blog/author/lance/feed.xml"},
+		{"/blog/author/Lance", http.StatusMovedPermanently, "/blog/author/lance", ""},
+		{"/blog/author/lance-rogers", http.StatusMovedPermanently, "/blog/author/lance", ""},
+		{"/blog/author/no-such-author", http.StatusNotFound, "", ""},
+		{newest.Format("/blog/2006"), http.StatusMovedPermanently, newest.Format("/blog/2006/"), ""},
+		{newest.Format("/blog/2006/"), http.StatusOK, "", "Posts from " + newest.Format("2006")},
+		{newest.Format("/blog/2006/01/"), http.StatusOK, "", "Posts from " + newest.Format("January 2006")},
+		{"/blog/1999/", http.StatusNotFound, "", ""},
+		{"/blog/2025/13/", http.StatusNotFound, "", ""},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != tc.expectedStatus {
+			t.Errorf("%s returned wrong status code: got %v want %v", tc.path, rr.Code, tc.expectedStatus)
+			continue
+		}
+		if tc.location != "" && rr.Header().Get("Location") != tc.location {
+			t.Errorf("%s redirected to %q, want %q", tc.path, rr.Header().Get("Location"), tc.location)
+		}
+		if tc.contains != "" && !strings.Contains(rr.Body.String(), tc.contains) {
+			t.Errorf("%s response should contain %q", tc.path, tc.contains)
+		}
+	}
+}
+
+// Posts can't take a /blog/ path another route serves, so the slugs the
+// content lint rejects must match the routes
+func TestReservedSlugs(t *testing.T) {
+	r := newRouter()
+
+	routed := map[string]bool{}
+	r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(template, "/blog/") {
+			return nil
+		}
+		segment, _, _ := strings.Cut(strings.TrimPrefix(template, "/blog/"), "/")
+		if !strings.Contains(segment, "{") {
+			routed[segment] = true
+		}
+		return nil
+	})
+
+	for segment := range routed {
+		if !blog.IsReservedSlug(segment) {
+			t.Errorf("/blog/%s is routed but %q isn't in blog.ReservedSlugs", segment, segment)
+		}
+	}
+	for _, slug := range blog.ReservedSlugs {
+		if !routed[slug] {
+			t.Errorf("%q is in blog.ReservedSlugs but /blog/%s isn't routed", slug, slug)
+		}
+	}
+}
+
+func TestHealthHandlerReportsBlogGeneration(t *testing.T) {
+	if blogService == nil {
+		t.Skip("blog service not initialized")
+	}
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	rr := httptest.NewRecorder()
+	healthHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("health returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	var body struct {
+		Services map[string]string `json:"services"`
+		Blog     struct {
+			Generation uint64 `json:"generation"`
+			Posts      int    `json:"posts"`
+		} `json:"blog"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("health returned invalid JSON: %v", err)
+	}
+	if body.Blog.Generation == 0 || body.Blog.Posts == 0 {
+		t.Errorf("health should report the loaded blog snapshot, got %+v", body.Blog)
+	}
+	if !strings.Contains(body.Services["blog"], "generation") {
+		t.Errorf("blog service status missing generation: %q", body.Services["blog"])
+	}
+}
+
+func TestChromaStylesheet(t *testing.T) {
+	r := newRouter()
+	req := httptest.NewRequest("GET", "/static/chroma.css", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("chroma.css returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/css") {
+		t.Errorf("chroma.css has content type %q", ct)
+	}
+	body := rr.Body.String()
+	for _, want := range []string{"@media (prefers-color-scheme: light)", "@media (prefers-color-scheme: dark)", ".chroma .kd"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("chroma.css should contain %q", want)
+		}
+	}
+}
+
+func TestPageMetadata(t *testing.T) {
+	r := newRouter()
+
+	posts := blogService.GetAll(context.Background())
+	if len(posts) == 0 {
+		t.Fatal("expected blog posts to be loaded")
+	}
+	post := posts[0]
+	base := siteBaseURL(httptest.NewRequest("GET", "/", nil))
+
+	testCases := []struct {
+		path     string
+		contains []string
+	}{
+		{"/", []string{`<link rel="canonical" href="` + base + `/"`, `property="og:type" content="website"`, `"@type":"WebPage"`}},
+		{"/blog/" + post.Slug, []string{
+			`<link rel="canonical" href="` + base + `/blog/` + post.Slug + `"`,
+			`property="og:type" content="article"`,
+			`name="twitter:card"`,
+			`"@type":"BlogPosting"`,
+			`property="article:published_time"`,
+			`property="og:image" content="` + base + postCardPath(post.Slug) + `"`,
+			`property="article:author" content="` + base + `/blog/author/lance"`,
+			`rel="author"`,
+		}},
+		{"/about", []string{`<link rel="canonical" href="` + base + `/about"`, `name="description"`}},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("%s returned wrong status code: got %v want %v", tc.path, rr.Code, http.StatusOK)
+			continue
+		}
+		for _, want := range tc.contains {
+			if !strings.Contains(rr.Body.String(), want) {
+				t.Errorf("%s head should contain %q", tc.path, want)
+			}
+		}
+	}
+}
+
+func TestBlogCard(t *testing.T) {
+	r := newRouter()
+
+	posts := blogService.GetAll(context.Background())
+	if len(posts) == 0 {
+		t.Fatal("expected blog posts to be loaded")
+	}
+
+	req := httptest.NewRequest("GET", postCardPath(posts[0].Slug), nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("og.png returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("og.png has content type %q", ct)
+	}
+	if !strings.HasPrefix(rr.Body.String(), "\x89PNG") {
+		t.Error("og.png should be a PNG")
+	}
+
+	// Unchanged cards are revalidated by their content hash
+	req = httptest.NewRequest("GET", postCardPath(posts[0].Slug), nil)
+	req.Header.Set("If-None-Match", rr.Header().Get("ETag"))
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotModified {
+		t.Errorf("og.png with a matching ETag returned %v, want %v", rr.Code, http.StatusNotModified)
+	}
+
+	req = httptest.NewRequest("GET", postCardPath("no-such-post"), nil)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("og.png of a missing post returned %v, want %v", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestLocales(t *testing.T) {
+	// Serve a second language for the duration of the test
+	previous := locales
+	locales = i18n.New("en", map[string]string{"en": "English", "es": "Español"}, map[string]map[string]string{
+		"es": {"nav.blog": "Artículos"},
+	})
+	defer func() { locales = previous }()
+	r := newRouter()
+
+	posts := blogService.GetAll(context.Background())
+	if len(posts) == 0 {
+		t.Fatal("expected blog posts to be loaded")
+	}
+	base := siteBaseURL(httptest.NewRequest("GET", "/", nil))
+
+	req := httptest.NewRequest("GET", "/es/blog", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("/es/blog returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	for _, want := range []string{
+		`<html lang="es">`,
+		`<link rel="canonical" href="` + base + `/es/blog"`,
+		`hreflang="en" href="` + base + `/blog"`,
+		`hreflang="x-default" href="` + base + `/blog"`,
+		`href="/es/blog/` + posts[0].Slug + `"`,
+		`>Artículos</a>`,
+	} {
+		if !strings.Contains(rr.Body.String(), want) {
+			t.Errorf("/es/blog should contain %q", want)
+		}
+	}
+
+	// Untranslated posts are shown in every locale but point at the original
+	req = httptest.NewRequest("GET", "/es/blog/"+posts[0].Slug, nil)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("/es/blog/%s returned wrong status code: got %v want %v", posts[0].Slug, rr.Code, http.StatusOK)
+	}
+	if want := `<link rel="canonical" href="` + base + `/blog/` + posts[0].Slug + `"`; !strings.Contains(rr.Body.String(), want) {
+		t.Errorf("untranslated post should contain %q", want)
+	}
+
+	// Visitors landing on the root get the home page of their language
+	testCases := []struct {
+		name     string
+		language string
+		cookie   string
+		query    string
+		location string
+	}{
+		{"spanish browser", "es-MX,es;q=0.9", "", "", "/es/"},
+		{"english browser", "en-US,en;q=0.9", "", "", ""},
+		{"unsupported language", "fr", "", "", ""},
+		{"picked english", "es", "en", "", ""},
+		{"switching to english", "es", "", "?lang=en", ""},
+		{"picked spanish", "en", "es", "", "/es/"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/"+tc.query, nil)
+			req.Header.Set("Accept-Language", tc.language)
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: localeCookie, Value: tc.cookie})
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+			if got := rr.Header().Get("Location"); got != tc.location {
+				t.Errorf("redirected to %q, want %q", got, tc.location)
+			}
+			if got := rr.Header().Get("Vary"); got != "Accept-Language, Cookie" {
+				t.Errorf("Vary = %q, want Accept-Language, Cookie", got)
+			}
+		})
+	}
+
+	req = httptest.NewRequest("GET", "/es", nil)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusMovedPermanently || rr.Header().Get("Location") != "/es/" {
+		t.Errorf("/es should redirect to /es/, got %v %q", rr.Code, rr.Header().Get("Location"))
+	}
+}
+
+func TestMachineReadable(t *testing.T) {
+	r := newRouter()
+
+	posts := blogService.GetAll(context.Background())
+	if len(posts) == 0 {
+		t.Fatal("expected blog posts to be loaded")
+	}
+	post := posts[0]
+
+	req := httptest.NewRequest("GET", postMarkdownPath(post.Slug), nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("%s returned wrong status code: got %v want %v", req.URL.Path, rr.Code, http.StatusOK)
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/markdown") {
+		t.Errorf("markdown source has content type %q", ct)
+	}
+	body := rr.Body.String()
+	if !strings.HasPrefix(body, "---\ntitle: ") || !strings.Contains(body, "\nurl: https://blockhead.consulting/blog/"+post.Slug+"\n") {
+		t.Errorf("markdown source should start with normalized frontmatter, got %q", body[:min(len(body), 300)])
+	}
+
+	// Unchanged sources are revalidated by their content hash
+	req = httptest.NewRequest("GET", postMarkdownPath(post.Slug), nil)
+	req.Header.Set("If-None-Match", rr.Header().Get("ETag"))
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotModified {
+		t.Errorf("markdown source with a matching ETag returned %v, want %v", rr.Code, http.StatusNotModified)
+	}
+
+	req = httptest.NewRequest("GET", postMarkdownPath("no-such-post"), nil)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("markdown source of a missing post returned %v, want %v", rr.Code, http.StatusNotFound)
+	}
+
+	for path, want := range map[string]string{
+		"/llms.txt":      "(https://blockhead.consulting" + postMarkdownPath(post.Slug) + ")",
+		"/llms-full.txt": "\n# " + post.Title + "\n",
+	} {
+		req = httptest.NewRequest("GET", path, nil)
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Errorf("%s returned wrong status code: got %v want %v", path, rr.Code, http.StatusOK)
+		}
+		if !strings.Contains(rr.Body.String(), want) {
+			t.Errorf("%s should contain %q", path, want)
+		}
+	}
+
+	// llms-full.txt is only built again when the posts change
+	if first, second := llmsFull.current(req), llmsFull.current(req); &first[0] != &second[0] {
+		t.Error("llms-full.txt should be reused while the blog generation is unchanged")
+	}
+
+	// Machine readers have their own, tighter rate limit
+	previous := machineLimiter
+	machineLimiter = security.NewRateLimiter(&security.RateLimiterConfig{MaxRequests: 1, Window: time.Minute, CleanupPeriod: time.Minute, BlockDuration: 2 * time.Minute})
+	defer func() { machineLimiter = previous }()
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req = httptest.NewRequest("GET", "/llms.txt", nil)
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != want {
+			t.Errorf("request %d to /llms.txt returned %v, want %v", i+1, rr.Code, want)
+		}
+	}
+	if got := rr.Header().Get("Retry-After"); got != "120" {
+		t.Errorf("Retry-After = %q, want the limiter's block of 120 seconds", got)
+	}
+	req = httptest.NewRequest("GET", "/blog", nil)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("pages shouldn't count against the machine rate limit, /blog returned %v", rr.Code)
+	}
+}
+
+func TestBlogAPI(t *testing.T) {
+	r := newRouter()
+
+	posts := blogService.GetAll(context.Background())
+	if len(posts) == 0 {
+		t.Fatal("expected blog posts to be loaded")
+	}
+
+	for _, path := range []string{"/api/v1/posts", "/api/v1/posts/" + posts[0].Slug, "/api/v1/tags", "/api/v1/openapi.json"} {
+		req := httptest.NewRequest("GET", path, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Errorf("%s returned wrong status code: got %v want %v", path, rr.Code, http.StatusOK)
+		}
+		if ct := rr.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+			t.Errorf("%s has content type %q", path, ct)
+		}
+		if !json.Valid(rr.Body.Bytes()) {
+			t.Errorf("%s should return JSON", path)
+		}
+	}
+
+	// The API isn't crawlable
+	req := httptest.NewRequest("GET", "/sitemap.xml", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if strings.Contains(rr.Body.String(), "/api/v1") {
+		t.Error("sitemap should not list API routes")
+	}
+}
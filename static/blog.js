@@ -1,80 +1,18 @@
 // Blog-specific functionality
 
-// Blog filters and search
+// Blog search
 function initializeBlogFilters() {
-  const tagButtons = document.querySelectorAll('.filter-btn');
-  const blogPosts = document.querySelectorAll('.blog-post-card');
   const searchInput = document.getElementById('blog-search');
   const searchClear = document.querySelector('.search-clear');
   
-  // Tag filtering
-  tagButtons.forEach(button => {
-    button.addEventListener('click', function() {
-      const tag = this.dataset.tag.toLowerCase();
-      
-      // Update active state
-      tagButtons.forEach(btn => btn.classList.remove('active'));
-      this.classList.add('active');
-      
-      // Use blog filter configuration from server (injected by template)
-      const tagAliases = window.blogFilterConfig || {};
-      
-      blogPosts.forEach(post => {
-        const postTags = post.dataset.tags.toLowerCase();
-        
-        if (tag === 'all') {
-          post.style.display = 'block';
-          return;
-        }
-        
-        // Check if any alias matches (using word boundaries to avoid partial matches)
-        const aliases = tagAliases[tag.toLowerCase()] || [tag.toLowerCase()];
-        const matches = aliases.some(alias => {
-          const regex = new RegExp(`\\b${alias.toLowerCase().replace(/[.*+?^${}()|[\]\\]/g, '\\$&')}\\b`);
-          return regex.test(postTags);
-        });
-        
-        
-        if (matches) {
-          post.style.display = 'block';
-        } else {
-          post.style.display = 'none';
-        }
-      });
-      
-      // Update URL without page reload
-      const url = new URL(window.location);
-      if (tag === 'all') {
-        url.searchParams.delete('tag');
-      } else {
-        url.searchParams.set('tag', tag);
-      }
-      window.history.pushState({}, '', url);
-    });
-  });
-  
-  // Search functionality
+  // Tag filters are links to server-rendered tag pages, and search runs
+  // server-side through HTMX (hx-get="/blog/search")
   if (searchInput) {
     searchInput.addEventListener('input', function() {
-      const searchTerm = this.value.toLowerCase();
-      
       // Show/hide clear button
       if (searchClear) {
-        searchClear.style.display = searchTerm ? 'block' : 'none';
+        searchClear.style.display = this.value ? 'block' : 'none';
       }
-      
-      // Filter posts
-      blogPosts.forEach(post => {
-        const title = post.querySelector('.blog-title').textContent.toLowerCase();
-        const summary = post.querySelector('.blog-summary').textContent.toLowerCase();
-        const tags = post.dataset.tags.toLowerCase();
-        
-        if (title.includes(searchTerm) || summary.includes(searchTerm) || tags.includes(searchTerm)) {
-          post.style.display = 'block';
-        } else {
-          post.style.display = 'none';
-        }
-      });
     });
     
     // Clear search
@@ -85,16 +23,6 @@ function initializeBlogFilters() {
       });
     }
   }
-  
-  // Check URL for initial tag filter
-  const urlParams = new URLSearchParams(window.location.search);
-  const tagParam = urlParams.get('tag');
-  if (tagParam) {
-    const tagButton = document.querySelector(`[data-tag="${tagParam}"]`);
-    if (tagButton) {
-      tagButton.click();
-    }
-  }
 }
 
 // Project Card System